@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
 	"github.com/nava1525/bilio-backend/pkg/api"
 )
 
@@ -25,7 +26,15 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if this is an ID operation (path contains an ID after /clients/)
-	id := extractIDFromPath(r.URL.Path)
+	id, action := extractIDFromPath(r.URL.Path)
+	if id != "" && action == "late-fee-policy" {
+		handleClientLateFeePolicy(w, r, userID, id)
+		return
+	}
+	if id != "" && action == "credits" {
+		handleClientCredits(w, r, userID, id)
+		return
+	}
 	if id != "" {
 		// Handle ID-based operations
 		switch r.Method {
@@ -88,17 +97,87 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func extractIDFromPath(path string) string {
+// handleClientLateFeePolicy serves /clients/{id}/late-fee-policy, the
+// client's override of the workspace late fee policy.
+func handleClientLateFeePolicy(w http.ResponseWriter, r *http.Request, userID, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		policy, err := api.GetLateFeeService().GetClientPolicy(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, policy)
+	case http.MethodPut:
+		var input api.UpdateLateFeePolicyInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		policy, err := api.GetLateFeeService().UpdateClientPolicy(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, policy)
+	case http.MethodDelete:
+		if err := api.GetLateFeeService().DeleteClientPolicy(r.Context(), id, userID); err != nil {
+			api.RespondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleClientCredits serves /clients/{id}/credits, the client's credit
+// ledger and deposits.
+func handleClientCredits(w http.ResponseWriter, r *http.Request, userID, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		credits, err := api.GetClientService().ListCredits(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.ClientCreditErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, credits)
+	case http.MethodPost:
+		var input api.CreateDepositInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		deposit, err := api.GetClientService().AddDeposit(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.ClientCreditErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, deposit)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// extractIDFromPath returns the client ID and, for sub-resources such as
+// /clients/{id}/late-fee-policy, the trailing action segment.
+func extractIDFromPath(path string) (string, string) {
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	for i, part := range parts {
 		if part == "clients" && i+1 < len(parts) {
 			nextPart := parts[i+1]
 			// Don't treat "index" as an ID
 			if nextPart != "index" && nextPart != "" {
-				return nextPart
+				action := ""
+				if i+2 < len(parts) {
+					action = parts[i+2]
+				}
+				return nextPart, action
 			}
 		}
 	}
-	return ""
+	return "", ""
 }
 
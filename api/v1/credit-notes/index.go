@@ -0,0 +1,80 @@
+package creditnotes
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	service := api.GetCreditNoteService()
+
+	id := extractIDFromPath(r.URL.Path)
+	if id != "" {
+		creditNote, err := service.GetByID(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.CreditNoteErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, creditNote)
+		return
+	}
+
+	filters := api.CreditNoteFilters{}
+	if invoiceID := r.URL.Query().Get("invoice_id"); invoiceID != "" {
+		filters.InvoiceID = &invoiceID
+	}
+	if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
+		if fromDate, err := time.Parse("2006-01-02", fromDateStr); err == nil {
+			filters.FromDate = &fromDate
+		}
+	}
+	if toDateStr := r.URL.Query().Get("to_date"); toDateStr != "" {
+		if toDate, err := time.Parse("2006-01-02", toDateStr); err == nil {
+			filters.ToDate = &toDate
+		}
+	}
+
+	creditNotes, err := service.List(r.Context(), userID, filters)
+	if err != nil {
+		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	api.RespondJSON(w, http.StatusOK, creditNotes)
+}
+
+func extractIDFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "credit-notes" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				return nextPart
+			}
+		}
+	}
+	return ""
+}
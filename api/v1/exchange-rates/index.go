@@ -0,0 +1,113 @@
+package exchangerates
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	service := api.GetExchangeRateService()
+
+	segment := extractSegmentFromPath(r.URL.Path)
+	switch {
+	case segment == "" && r.Method == http.MethodGet:
+		filters := api.ExchangeRateFilters{}
+		if currency := r.URL.Query().Get("currency"); currency != "" {
+			filters.Currency = &currency
+		}
+		if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
+			if fromDate, err := time.Parse("2006-01-02", fromDateStr); err == nil {
+				filters.FromDate = &fromDate
+			}
+		}
+		if toDateStr := r.URL.Query().Get("to_date"); toDateStr != "" {
+			if toDate, err := time.Parse("2006-01-02", toDateStr); err == nil {
+				filters.ToDate = &toDate
+			}
+		}
+
+		rates, err := service.List(r.Context(), userID, filters)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, rates)
+	case segment == "import" && r.Method == http.MethodPost:
+		part, err := api.RatesFilePart(w, r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				api.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			api.RespondError(w, http.StatusBadRequest, "invalid upload: send the rates as multipart/form-data in the \"file\" field")
+			return
+		}
+		defer part.Close()
+
+		result, err := service.Import(r.Context(), userID, api.ImportExchangeRatesInput{Content: part})
+		if err != nil {
+			api.RespondError(w, httperr.ExchangeRateErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, result)
+	case segment == "sync" && r.Method == http.MethodPost:
+		var input api.SyncExchangeRatesInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		result, err := service.Sync(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.ExchangeRateErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, result)
+	case segment != "" && segment != "import" && segment != "sync" && r.Method == http.MethodDelete:
+		if err := service.Delete(r.Context(), segment, userID); err != nil {
+			api.RespondError(w, httperr.ExchangeRateErrorStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// extractSegmentFromPath returns the segment after /exchange-rates: "import",
+// "sync" or a rate ID.
+func extractSegmentFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "exchange-rates" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				return nextPart
+			}
+		}
+	}
+	return ""
+}
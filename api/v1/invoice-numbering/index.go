@@ -0,0 +1,54 @@
+package invoicenumbering
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		settings, err := api.GetInvoiceNumberingService().Get(r.Context(), userID)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, settings)
+	case http.MethodPut:
+		var input api.UpdateInvoiceNumberingInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		settings, err := api.GetInvoiceNumberingService().Update(r.Context(), userID, input)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if _, ok := api.AsValidationError(err); ok {
+				status = http.StatusBadRequest
+			}
+			api.RespondError(w, status, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, settings)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
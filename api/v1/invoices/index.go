@@ -2,10 +2,13 @@ package invoices
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
 	"github.com/nava1525/bilio-backend/pkg/api"
 )
 
@@ -25,7 +28,11 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := extractIDFromPath(r.URL.Path)
+	id, action := extractIDFromPath(r.URL.Path)
+	if id != "" && action != "" {
+		handleInvoiceAction(w, r, userID, id, action)
+		return
+	}
 	if id != "" {
 		switch r.Method {
 		case http.MethodGet:
@@ -44,10 +51,16 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 			invoice, err := api.GetInvoiceService().Update(r.Context(), id, userID, input)
 			if err != nil {
-				api.RespondError(w, http.StatusBadRequest, err.Error())
+				api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
 				return
 			}
 			api.RespondJSON(w, http.StatusOK, invoice)
+		case http.MethodDelete:
+			if err := api.GetInvoiceService().Delete(r.Context(), id, userID); err != nil {
+				api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
 		default:
 			api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
 		}
@@ -100,16 +113,227 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func extractIDFromPath(path string) string {
+func handleInvoiceAction(w http.ResponseWriter, r *http.Request, userID, id, action string) {
+	switch {
+	case action == "pdf" && r.Method == http.MethodGet:
+		size, err := api.ParsePageSize(r.URL.Query().Get("size"))
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		document, err := api.GetInvoiceService().GeneratePDF(r.Context(), id, userID, size)
+		if err != nil {
+			api.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		api.RespondPDF(w, document.Filename, document.Content)
+	case action == "mark-paid" && r.Method == http.MethodPost:
+		var input api.CreatePaymentInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		invoice, err := api.GetInvoiceService().MarkPaid(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, invoice)
+	case action == "send" && r.Method == http.MethodPost:
+		var input api.SendInvoiceInput
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+		}
+
+		invoice, err := api.GetInvoiceService().Send(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, invoice)
+	case action == "duplicate" && r.Method == http.MethodPost:
+		var input api.DuplicateInvoiceInput
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+		}
+
+		invoice, err := api.GetInvoiceService().Duplicate(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, invoice)
+	case action == "void" && r.Method == http.MethodPost:
+		var input api.VoidInvoiceInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		invoice, err := api.GetInvoiceService().Void(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, invoice)
+	case action == "history" && r.Method == http.MethodGet:
+		events, err := api.GetInvoiceService().History(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, events)
+	case action == "share-links" && r.Method == http.MethodGet:
+		links, err := api.GetInvoiceShareService().List(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, links)
+	case action == "share-links" && r.Method == http.MethodPost:
+		var input api.CreateShareLinkInput
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+		}
+
+		link, err := api.GetInvoiceShareService().Create(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, link)
+	case action == "share-links" && r.Method == http.MethodDelete:
+		linkID := extractSubresourceID(r.URL.Path, action)
+		if err := api.GetInvoiceShareService().Revoke(r.Context(), id, linkID, userID); err != nil {
+			api.RespondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "credit-notes" && r.Method == http.MethodGet:
+		creditNotes, err := api.GetCreditNoteService().List(r.Context(), userID, api.CreditNoteFilters{InvoiceID: &id})
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, creditNotes)
+	case action == "credit-notes" && r.Method == http.MethodPost:
+		var input api.CreateCreditNoteInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		creditNote, err := api.GetCreditNoteService().Create(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.CreditNoteErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, creditNote)
+	case action == "reminders" && r.Method == http.MethodGet:
+		reminders, err := api.GetReminderService().ListForInvoice(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, reminders)
+	case action == "late-fees" && r.Method == http.MethodGet:
+		fees, err := api.GetLateFeeService().ListForInvoice(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, fees)
+	case action == "attachments" && r.Method == http.MethodGet:
+		if attachmentID := extractSubresourceID(r.URL.Path, action); attachmentID != "" {
+			attachment, content, err := api.GetInvoiceService().OpenAttachment(r.Context(), id, attachmentID, userID)
+			if err != nil {
+				api.RespondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+				return
+			}
+			defer content.Close()
+			api.RespondAttachment(w, attachment, content)
+			return
+		}
+
+		attachments, err := api.GetInvoiceService().ListAttachments(r.Context(), id, userID)
+		if err != nil {
+			api.RespondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, attachments)
+	case action == "attachments" && r.Method == http.MethodPost:
+		part, err := api.AttachmentPart(w, r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				api.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			api.RespondError(w, http.StatusBadRequest, "invalid upload: send the file as multipart/form-data in the \"file\" field")
+			return
+		}
+		defer part.Close()
+
+		attachment, err := api.GetInvoiceService().AddAttachment(r.Context(), id, userID, api.UploadAttachmentInput{
+			FileName:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     part,
+		})
+		if err != nil {
+			api.RespondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, attachment)
+	case action == "attachments" && r.Method == http.MethodDelete:
+		attachmentID := extractSubresourceID(r.URL.Path, action)
+		if err := api.GetInvoiceService().DeleteAttachment(r.Context(), id, attachmentID, userID); err != nil {
+			api.RespondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// extractIDFromPath returns the invoice ID and, for sub-resources such as
+// /invoices/{id}/pdf, the trailing action segment.
+func extractIDFromPath(path string) (string, string) {
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	for i, part := range parts {
 		if part == "invoices" && i+1 < len(parts) {
 			nextPart := parts[i+1]
 			if nextPart != "index" && nextPart != "" && nextPart != "handler" {
-				return nextPart
+				action := ""
+				if i+2 < len(parts) {
+					action = parts[i+2]
+				}
+				return nextPart, action
 			}
 		}
 	}
-	return ""
+	return "", ""
 }
 
+// extractSubresourceID returns the segment after action, e.g. the link ID in
+// /invoices/{id}/share-links/{linkID}.
+func extractSubresourceID(path, action string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == action && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package items
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	service := api.GetCatalogItemService()
+
+	id := extractIDFromPath(r.URL.Path)
+	if id != "" {
+		switch r.Method {
+		case http.MethodGet:
+			item, err := service.GetByID(r.Context(), id, userID)
+			if err != nil {
+				api.RespondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+				return
+			}
+			api.RespondJSON(w, http.StatusOK, item)
+		case http.MethodPut:
+			var input api.CatalogItemInput
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+
+			item, err := service.Update(r.Context(), id, userID, input)
+			if err != nil {
+				api.RespondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+				return
+			}
+			api.RespondJSON(w, http.StatusOK, item)
+		case http.MethodDelete:
+			if err := service.Delete(r.Context(), id, userID); err != nil {
+				api.RespondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		var filters api.CatalogItemFilters
+		if search := r.URL.Query().Get("search"); search != "" {
+			filters.Search = &search
+		}
+
+		items, err := service.List(r.Context(), userID, filters)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, items)
+	case http.MethodPost:
+		var input api.CatalogItemInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		item, err := service.Create(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, item)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func extractIDFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "items" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				return nextPart
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package latefeepolicy
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		policy, err := api.GetLateFeeService().GetPolicy(r.Context(), userID)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, policy)
+	case http.MethodPut:
+		var input api.UpdateLateFeePolicyInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		policy, err := api.GetLateFeeService().UpdatePolicy(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, policy)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
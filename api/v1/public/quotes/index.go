@@ -0,0 +1,91 @@
+package publicquotes
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+// Handler lets clients view, accept and decline a quote. It is public: the
+// signed token in the path is the only credential.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	token, action := extractTokenFromPath(r.URL.Path)
+	if token == "" {
+		api.RespondError(w, http.StatusNotFound, "quote link not found")
+		return
+	}
+
+	service := api.GetQuoteService()
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		quote, err := service.View(r.Context(), token)
+		if err != nil {
+			api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, quote)
+	case action == "accept" && r.Method == http.MethodPost:
+		var input api.AcceptQuoteInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		quote, err := service.Accept(r.Context(), token, input)
+		if err != nil {
+			api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, quote)
+	case action == "decline" && r.Method == http.MethodPost:
+		var input api.DeclineQuoteInput
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+		}
+
+		quote, err := service.Decline(r.Context(), token, input)
+		if err != nil {
+			api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, quote)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func extractTokenFromPath(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "quotes" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				action := ""
+				if i+2 < len(parts) {
+					action = parts[i+2]
+				}
+				return nextPart, action
+			}
+		}
+	}
+	return "", ""
+}
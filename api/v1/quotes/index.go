@@ -0,0 +1,142 @@
+package quotes
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	service := api.GetQuoteService()
+
+	id, action := extractIDFromPath(r.URL.Path)
+	if id != "" && action != "" {
+		if action != "convert" || r.Method != http.MethodPost {
+			api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var input api.ConvertQuoteInput
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+		}
+
+		invoice, err := service.Convert(r.Context(), id, userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, invoice)
+		return
+	}
+
+	if id != "" {
+		switch r.Method {
+		case http.MethodGet:
+			quote, err := service.GetByID(r.Context(), id, userID)
+			if err != nil {
+				api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+				return
+			}
+			api.RespondJSON(w, http.StatusOK, quote)
+		case http.MethodPut:
+			var input api.UpdateQuoteInput
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+
+			quote, err := service.Update(r.Context(), id, userID, input)
+			if err != nil {
+				api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+				return
+			}
+			api.RespondJSON(w, http.StatusOK, quote)
+		case http.MethodDelete:
+			if err := service.Delete(r.Context(), id, userID); err != nil {
+				api.RespondError(w, httperr.QuoteErrorStatus(err), err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		filters := api.QuoteFilters{}
+		if status := r.URL.Query().Get("status"); status != "" {
+			s := api.QuoteStatus(status)
+			filters.Status = &s
+		}
+		if clientID := r.URL.Query().Get("client_id"); clientID != "" {
+			filters.ClientID = &clientID
+		}
+
+		quotes, err := service.List(r.Context(), userID, filters)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, quotes)
+	case http.MethodPost:
+		var input api.CreateQuoteInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		quote, err := service.Create(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, quote)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// extractIDFromPath returns the quote ID and, for /quotes/{id}/convert, the
+// trailing action segment.
+func extractIDFromPath(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "quotes" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				action := ""
+				if i+2 < len(parts) {
+					action = parts[i+2]
+				}
+				return nextPart, action
+			}
+		}
+	}
+	return "", ""
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
 	"github.com/nava1525/bilio-backend/pkg/api"
 )
 
@@ -45,7 +46,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	profitability, err := api.GetReportService().GetClientProfitability(r.Context(), userID, clientID, fromDate, toDate)
 	if err != nil {
-		api.RespondError(w, http.StatusNotFound, err.Error())
+		api.RespondError(w, httperr.ReportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
 	"github.com/nava1525/bilio-backend/pkg/api"
 )
 
@@ -42,7 +43,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	summary, err := api.GetReportService().GetSummary(r.Context(), userID, fromDate, toDate)
 	if err != nil {
-		api.RespondError(w, http.StatusInternalServerError, err.Error())
+		api.RespondError(w, httperr.ReportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -0,0 +1,94 @@
+package share
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+// Handler serves shared invoices to clients. It is public: the signed token
+// in the path is the only credential.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	token := extractTokenFromPath(r.URL.Path)
+	if token == "" {
+		api.RespondError(w, http.StatusNotFound, "share link not found or expired")
+		return
+	}
+
+	service := api.GetInvoiceShareService()
+	viewer := api.ShareViewer{
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "html":
+		page, err := service.ViewHTML(r.Context(), token, viewer)
+		if err != nil {
+			api.RespondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondHTML(w, page)
+	case "json":
+		invoice, err := service.View(r.Context(), token, viewer)
+		if err != nil {
+			api.RespondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, invoice)
+	case "pdf":
+		size, err := api.ParsePageSize(r.URL.Query().Get("size"))
+		if err != nil {
+			api.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		document, err := service.ViewPDF(r.Context(), token, viewer, size)
+		if err != nil {
+			api.RespondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondPDF(w, document.Filename, document.Content)
+	default:
+		api.RespondError(w, http.StatusBadRequest, "unsupported format (use html, json or pdf)")
+	}
+}
+
+// clientIP prefers the address reported by Vercel's proxy.
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+	return r.RemoteAddr
+}
+
+func extractTokenFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "share" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				return nextPart
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package taxcodes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	service := api.GetTaxCodeService()
+
+	id := extractIDFromPath(r.URL.Path)
+	if id != "" {
+		switch r.Method {
+		case http.MethodGet:
+			taxCode, err := service.GetByID(r.Context(), id, userID)
+			if err != nil {
+				api.RespondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+				return
+			}
+			api.RespondJSON(w, http.StatusOK, taxCode)
+		case http.MethodPut:
+			var input api.TaxCodeInput
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				api.RespondError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+
+			taxCode, err := service.Update(r.Context(), id, userID, input)
+			if err != nil {
+				api.RespondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+				return
+			}
+			api.RespondJSON(w, http.StatusOK, taxCode)
+		case http.MethodDelete:
+			if err := service.Delete(r.Context(), id, userID); err != nil {
+				api.RespondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		taxCodes, err := service.List(r.Context(), userID)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, taxCodes)
+	case http.MethodPost:
+		var input api.TaxCodeInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		taxCode, err := service.Create(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusCreated, taxCode)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func extractIDFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "tax-codes" && i+1 < len(parts) {
+			nextPart := parts[i+1]
+			if nextPart != "index" && nextPart != "" {
+				return nextPart
+			}
+		}
+	}
+	return ""
+}
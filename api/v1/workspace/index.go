@@ -0,0 +1,177 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/pkg/api"
+)
+
+func Handler(w http.ResponseWriter, r *http.Request) {
+	api.HandleCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if err := api.EnsureInitialized(); err != nil {
+		api.RespondError(w, http.StatusInternalServerError, "service initialization failed")
+		return
+	}
+
+	userID, ok := api.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	section, name := extractSectionFromPath(r.URL.Path)
+	switch section {
+	case "profile":
+		handleProfile(w, r, userID)
+	case "logo":
+		handleLogo(w, r, userID)
+	case "templates":
+		handleTemplates(w, r, userID, name)
+	default:
+		api.RespondError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func handleProfile(w http.ResponseWriter, r *http.Request, userID string) {
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := api.GetWorkspaceService().GetProfile(r.Context(), userID)
+		if err != nil {
+			api.RespondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, profile)
+	case http.MethodPut:
+		var input api.UpdateWorkspaceProfileInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		profile, err := api.GetWorkspaceService().UpdateProfile(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, profile)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleLogo(w http.ResponseWriter, r *http.Request, userID string) {
+	switch r.Method {
+	case http.MethodGet:
+		logo, content, err := api.GetWorkspaceService().OpenLogo(r.Context(), userID)
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		defer content.Close()
+		api.RespondLogo(w, logo, content)
+	case http.MethodPut:
+		part, err := api.AttachmentPart(w, r)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				api.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			api.RespondError(w, http.StatusBadRequest, "invalid upload: send the image as multipart/form-data in the \"file\" field")
+			return
+		}
+		defer part.Close()
+
+		profile, err := api.GetWorkspaceService().UploadLogo(r.Context(), userID, api.UploadLogoInput{Content: part})
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, profile)
+	case http.MethodDelete:
+		if err := api.GetWorkspaceService().DeleteLogo(r.Context(), userID); err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func handleTemplates(w http.ResponseWriter, r *http.Request, userID, name string) {
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		templates, err := api.GetWorkspaceService().ListTemplates(r.Context(), userID)
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, templates)
+	case name == "preview" && r.Method == http.MethodPost:
+		var input api.PreviewInvoiceInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		preview, err := api.GetWorkspaceService().Preview(r.Context(), userID, input)
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondPreview(w, preview)
+	case name != "" && r.Method == http.MethodGet:
+		layout := api.InvoiceLayout(r.URL.Query().Get("layout"))
+		template, err := api.GetWorkspaceService().GetTemplate(r.Context(), userID, api.TemplateKind(name), layout)
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, template)
+	case name != "" && r.Method == http.MethodPut:
+		var input api.SaveTemplateInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			api.RespondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+
+		template, err := api.GetWorkspaceService().SaveTemplate(r.Context(), userID, api.TemplateKind(name), input)
+		if err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		api.RespondJSON(w, http.StatusOK, template)
+	case name != "" && r.Method == http.MethodDelete:
+		if err := api.GetWorkspaceService().DeleteTemplate(r.Context(), userID, api.TemplateKind(name)); err != nil {
+			api.RespondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		api.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// extractSectionFromPath returns the segment after /workspace, such as
+// "templates", and the one after it, such as the template kind.
+func extractSectionFromPath(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "workspace" && i+1 < len(parts) {
+			name := ""
+			if i+2 < len(parts) {
+				name = parts[i+2]
+			}
+			return parts[i+1], name
+		}
+	}
+	return "", ""
+}
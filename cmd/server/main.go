@@ -13,6 +13,7 @@ import (
 	"github.com/nava1525/bilio-backend/internal/database"
 	"github.com/nava1525/bilio-backend/internal/logger"
 	"github.com/nava1525/bilio-backend/internal/server"
+	"github.com/nava1525/bilio-backend/internal/worker"
 )
 
 func main() {
@@ -47,6 +48,20 @@ func main() {
 		}
 	}()
 
+	workerDone := make(chan struct{})
+	if cfg.Worker.Enabled {
+		runner, err := worker.New(cfg, logProvider, dbClient.DB())
+		if err != nil {
+			logProvider.Fatal().Err(err).Msg("failed to initialize background worker")
+		}
+		go func() {
+			defer close(workerDone)
+			runner.Run(shutdownCtx)
+		}()
+	} else {
+		close(workerDone)
+	}
+
 	<-shutdownCtx.Done()
 	logProvider.Info().Msg("shutting down server...")
 
@@ -54,6 +69,7 @@ func main() {
 		logProvider.Error().Err(err).Msg("graceful shutdown failed")
 		os.Exit(1)
 	}
+	<-workerDone
 
 	logProvider.Info().Msg("server exited cleanly")
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/nava1525/bilio-backend/internal/config"
+	"github.com/nava1525/bilio-backend/internal/database"
+	"github.com/nava1525/bilio-backend/internal/logger"
+	"github.com/nava1525/bilio-backend/internal/worker"
+)
+
+func main() {
+	once := flag.Bool("once", false, "run every job a single time and exit (for cron-style scheduling)")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+	_ = godotenv.Load("../.env")
+	_ = godotenv.Load("../../.env")
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+
+	logProvider := logger.New(cfg.Logging.Level)
+
+	dbClient, err := database.NewClient(cfg.Database.URL)
+	if err != nil {
+		logProvider.Fatal().Err(err).Msg("failed to initialize database client")
+	}
+	defer dbClient.Disconnect()
+
+	runner, err := worker.New(cfg, logProvider, dbClient.DB())
+	if err != nil {
+		logProvider.Fatal().Err(err).Msg("failed to initialize worker")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		if err := runner.RunOnce(ctx); err != nil {
+			logProvider.Error().Err(err).Msg("worker run failed")
+			os.Exit(1)
+		}
+		return
+	}
+
+	runner.Run(ctx)
+	logProvider.Info().Msg("worker exited cleanly")
+}
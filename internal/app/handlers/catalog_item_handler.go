@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type CatalogItemHandler struct {
+	service *services.CatalogItemService
+}
+
+func NewCatalogItemHandler(service *services.CatalogItemService) *CatalogItemHandler {
+	return &CatalogItemHandler{service: service}
+}
+
+func (h *CatalogItemHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var filters services.CatalogItemFilters
+	if search := r.URL.Query().Get("search"); search != "" {
+		filters.Search = &search
+	}
+
+	items, err := h.service.List(r.Context(), userID, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, items)
+}
+
+func (h *CatalogItemHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	item, err := h.service.GetByID(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
+
+func (h *CatalogItemHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.CatalogItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	item, err := h.service.Create(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, item)
+}
+
+func (h *CatalogItemHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.CatalogItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	item, err := h.service.Update(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
+
+func (h *CatalogItemHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		respondError(w, httperr.CatalogItemErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+func (h *ClientHandler) ListCredits(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	credits, err := h.service.ListCredits(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.ClientCreditErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, credits)
+}
+
+func (h *ClientHandler) AddDeposit(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.CreateDepositInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	deposit, err := h.service.AddDeposit(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.ClientCreditErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, deposit)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type CreditNoteHandler struct {
+	service *services.CreditNoteService
+}
+
+func NewCreditNoteHandler(service *services.CreditNoteService) *CreditNoteHandler {
+	return &CreditNoteHandler{service: service}
+}
+
+func (h *CreditNoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	filters := services.CreditNoteFilters{}
+
+	if invoiceID := r.URL.Query().Get("invoice_id"); invoiceID != "" {
+		filters.InvoiceID = &invoiceID
+	}
+	if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
+		if fromDate, err := time.Parse("2006-01-02", fromDateStr); err == nil {
+			filters.FromDate = &fromDate
+		}
+	}
+	if toDateStr := r.URL.Query().Get("to_date"); toDateStr != "" {
+		if toDate, err := time.Parse("2006-01-02", toDateStr); err == nil {
+			filters.ToDate = &toDate
+		}
+	}
+
+	creditNotes, err := h.service.List(r.Context(), userID, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, creditNotes)
+}
+
+func (h *CreditNoteHandler) ListForInvoice(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	creditNotes, err := h.service.List(r.Context(), userID, services.CreditNoteFilters{InvoiceID: &invoiceID})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, creditNotes)
+}
+
+func (h *CreditNoteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	creditNote, err := h.service.GetByID(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.CreditNoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, creditNote)
+}
+
+func (h *CreditNoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	var input services.CreateCreditNoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	creditNote, err := h.service.Create(r.Context(), invoiceID, userID, input)
+	if err != nil {
+		respondError(w, httperr.CreditNoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, creditNote)
+}
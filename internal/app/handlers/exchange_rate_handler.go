@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type ExchangeRateHandler struct {
+	service *services.ExchangeRateService
+}
+
+func NewExchangeRateHandler(service *services.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+func (h *ExchangeRateHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	filters := services.ExchangeRateFilters{}
+	if currency := r.URL.Query().Get("currency"); currency != "" {
+		filters.Currency = &currency
+	}
+	if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
+		if fromDate, err := time.Parse("2006-01-02", fromDateStr); err == nil {
+			filters.FromDate = &fromDate
+		}
+	}
+	if toDateStr := r.URL.Query().Get("to_date"); toDateStr != "" {
+		if toDate, err := time.Parse("2006-01-02", toDateStr); err == nil {
+			filters.ToDate = &toDate
+		}
+	}
+
+	rates, err := h.service.List(r.Context(), userID, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rates)
+}
+
+// Import takes a multipart/form-data request with the CSV in the "file"
+// field.
+func (h *ExchangeRateHandler) Import(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxExchangeRateFileSize+multipartOverhead)
+	part, err := attachmentPart(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(w, http.StatusRequestEntityTooLarge, services.ErrExchangeRateFileTooLarge.Error())
+			return
+		}
+		respondError(w, http.StatusBadRequest, "invalid upload: send the rates as multipart/form-data in the \"file\" field")
+		return
+	}
+	defer part.Close()
+
+	result, err := h.service.Import(r.Context(), userID, services.ImportExchangeRatesInput{Content: part})
+	if err != nil {
+		respondError(w, httperr.ExchangeRateErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+func (h *ExchangeRateHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	// The body is optional; an empty one syncs the last 30 days.
+	var input services.SyncExchangeRatesInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	result, err := h.service.Sync(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.ExchangeRateErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+func (h *ExchangeRateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		respondError(w, httperr.ExchangeRateErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
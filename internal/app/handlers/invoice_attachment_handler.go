@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+// multipartOverhead allows for the form boundaries and headers around an
+// uploaded file.
+const multipartOverhead = 1 << 20
+
+func (h *InvoiceHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	attachments, err := h.service.ListAttachments(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, attachments)
+}
+
+// UploadAttachment takes a multipart/form-data request with the file in the
+// "file" field.
+func (h *InvoiceHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAttachmentSize+multipartOverhead)
+	part, err := attachmentPart(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(w, http.StatusRequestEntityTooLarge, services.ErrAttachmentTooLarge.Error())
+			return
+		}
+		respondError(w, http.StatusBadRequest, "invalid upload: send the file as multipart/form-data in the \"file\" field")
+		return
+	}
+	defer part.Close()
+
+	id := chi.URLParam(r, "id")
+	attachment, err := h.service.AddAttachment(r.Context(), id, userID, services.UploadAttachmentInput{
+		FileName:    part.FileName(),
+		ContentType: part.Header.Get("Content-Type"),
+		Content:     part,
+	})
+	if err != nil {
+		respondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, attachment)
+}
+
+func (h *InvoiceHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	attachmentID := chi.URLParam(r, "attachmentID")
+	attachment, content, err := h.service.OpenAttachment(r.Context(), id, attachmentID, userID)
+	if err != nil {
+		respondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+		return
+	}
+	defer content.Close()
+
+	respondAttachment(w, attachment, content)
+}
+
+func (h *InvoiceHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	attachmentID := chi.URLParam(r, "attachmentID")
+	if err := h.service.DeleteAttachment(r.Context(), id, attachmentID, userID); err != nil {
+		respondError(w, httperr.AttachmentErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attachmentPart returns the "file" part of a multipart upload, skipping any
+// other form fields.
+func attachmentPart(r *http.Request) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+func respondAttachment(w http.ResponseWriter, attachment *models.InvoiceAttachment, content io.Reader) {
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
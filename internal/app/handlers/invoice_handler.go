@@ -2,10 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
 	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
 	"github.com/nava1525/bilio-backend/internal/app/models"
 	"github.com/nava1525/bilio-backend/internal/app/services"
@@ -110,7 +115,7 @@ func (h *InvoiceHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	invoice, err := h.service.Update(r.Context(), id, userID, input)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondError(w, httperr.InvoiceErrorStatus(err), err.Error())
 		return
 	}
 
@@ -133,7 +138,7 @@ func (h *InvoiceHandler) MarkPaid(w http.ResponseWriter, r *http.Request) {
 
 	invoice, err := h.service.MarkPaid(r.Context(), id, userID, input)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondError(w, httperr.InvoiceErrorStatus(err), err.Error())
 		return
 	}
 
@@ -141,12 +146,138 @@ func (h *InvoiceHandler) MarkPaid(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *InvoiceHandler) Send(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement email sending
-	respondJSON(w, http.StatusOK, map[string]string{"message": "Invoice send functionality will be implemented"})
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.SendInvoiceInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			respondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	invoice, err := h.service.Send(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, invoice)
+}
+
+func (h *InvoiceHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.DuplicateInvoiceInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			respondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	invoice, err := h.service.Duplicate(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, invoice)
+}
+
+func (h *InvoiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		respondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *InvoiceHandler) Void(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.VoidInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	invoice, err := h.service.Void(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.InvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, invoice)
+}
+
+func (h *InvoiceHandler) History(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	events, err := h.service.History(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, events)
 }
 
 func (h *InvoiceHandler) GetPDF(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement PDF generation
-	respondJSON(w, http.StatusOK, map[string]string{"message": "PDF generation will be implemented"})
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	size, err := services.ParsePageSize(r.URL.Query().Get("size"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	document, err := h.service.GeneratePDF(r.Context(), id, userID, size)
+	if err != nil {
+		respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondPDF(w, document.Filename, document.Content)
 }
 
+func respondPDF(w http.ResponseWriter, filename string, content []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type InvoiceNumberingHandler struct {
+	service *services.InvoiceNumberingService
+}
+
+func NewInvoiceNumberingHandler(service *services.InvoiceNumberingService) *InvoiceNumberingHandler {
+	return &InvoiceNumberingHandler{service: service}
+}
+
+func (h *InvoiceNumberingHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	settings, err := h.service.Get(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+func (h *InvoiceNumberingHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.UpdateInvoiceNumberingInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	settings, err := h.service.Update(r.Context(), userID, input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if _, ok := services.AsValidationError(err); ok {
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type InvoiceShareHandler struct {
+	service *services.InvoiceShareService
+}
+
+func NewInvoiceShareHandler(service *services.InvoiceShareService) *InvoiceShareHandler {
+	return &InvoiceShareHandler{service: service}
+}
+
+func (h *InvoiceShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	links, err := h.service.List(r.Context(), invoiceID, userID)
+	if err != nil {
+		respondError(w, httperr.ShareErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, links)
+}
+
+func (h *InvoiceShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	var input services.CreateShareLinkInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			respondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	link, err := h.service.Create(r.Context(), invoiceID, userID, input)
+	if err != nil {
+		respondError(w, httperr.ShareErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, link)
+}
+
+func (h *InvoiceShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	linkID := chi.URLParam(r, "linkID")
+	if err := h.service.Revoke(r.Context(), invoiceID, linkID, userID); err != nil {
+		respondError(w, httperr.ShareErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// View serves a shared invoice without authentication. format selects html
+// (the default), json or pdf.
+func (h *InvoiceShareHandler) View(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	viewer := services.ShareViewer{
+		IPAddress: r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "html":
+		page, err := h.service.ViewHTML(r.Context(), token, viewer)
+		if err != nil {
+			respondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		respondHTML(w, page)
+	case "json":
+		invoice, err := h.service.View(r.Context(), token, viewer)
+		if err != nil {
+			respondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, invoice)
+	case "pdf":
+		size, err := services.ParsePageSize(r.URL.Query().Get("size"))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		document, err := h.service.ViewPDF(r.Context(), token, viewer, size)
+		if err != nil {
+			respondError(w, httperr.ShareErrorStatus(err), err.Error())
+			return
+		}
+		respondPDF(w, document.Filename, document.Content)
+	default:
+		respondError(w, http.StatusBadRequest, "unsupported format (use html, json or pdf)")
+	}
+}
+
+// respondHTML serves a rendered invoice page under InvoicePageCSP.
+func respondHTML(w http.ResponseWriter, page []byte) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(page)))
+	w.Header().Set("Content-Security-Policy", services.InvoicePageCSP)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(page)
+}
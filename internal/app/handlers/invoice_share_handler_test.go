@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/services"
+)
+
+func TestRespondHTMLSandboxesPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	respondHTML(rec, []byte("<html><body>Invoice</body></html>"))
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	if csp != services.InvoicePageCSP {
+		t.Fatalf("Content-Security-Policy = %q, want %q", csp, services.InvoicePageCSP)
+	}
+	for _, directive := range []string{"sandbox", "script-src 'none'", "form-action 'none'"} {
+		if !strings.Contains(csp, directive) {
+			t.Errorf("Content-Security-Policy %q lacks %q", csp, directive)
+		}
+	}
+	if strings.Contains(csp, "allow-scripts") || strings.Contains(csp, "allow-same-origin") {
+		t.Errorf("Content-Security-Policy %q lifts the sandbox", csp)
+	}
+}
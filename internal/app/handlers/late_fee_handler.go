@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type LateFeeHandler struct {
+	service *services.LateFeeService
+}
+
+func NewLateFeeHandler(service *services.LateFeeService) *LateFeeHandler {
+	return &LateFeeHandler{service: service}
+}
+
+func (h *LateFeeHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	policy, err := h.service.GetPolicy(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *LateFeeHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.UpdateLateFeePolicyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *LateFeeHandler) GetClientPolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	clientID := chi.URLParam(r, "id")
+	policy, err := h.service.GetClientPolicy(r.Context(), clientID, userID)
+	if err != nil {
+		respondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *LateFeeHandler) UpdateClientPolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.UpdateLateFeePolicyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	clientID := chi.URLParam(r, "id")
+	policy, err := h.service.UpdateClientPolicy(r.Context(), clientID, userID, input)
+	if err != nil {
+		respondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *LateFeeHandler) DeleteClientPolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	clientID := chi.URLParam(r, "id")
+	if err := h.service.DeleteClientPolicy(r.Context(), clientID, userID); err != nil {
+		respondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LateFeeHandler) ListForInvoice(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	fees, err := h.service.ListForInvoice(r.Context(), invoiceID, userID)
+	if err != nil {
+		respondError(w, httperr.LateFeeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fees)
+}
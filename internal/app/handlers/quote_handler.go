@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type QuoteHandler struct {
+	service *services.QuoteService
+}
+
+func NewQuoteHandler(service *services.QuoteService) *QuoteHandler {
+	return &QuoteHandler{service: service}
+}
+
+func (h *QuoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	filters := services.QuoteFilters{}
+	if status := r.URL.Query().Get("status"); status != "" {
+		s := models.QuoteStatus(status)
+		filters.Status = &s
+	}
+	if clientID := r.URL.Query().Get("client_id"); clientID != "" {
+		filters.ClientID = &clientID
+	}
+
+	quotes, err := h.service.List(r.Context(), userID, filters)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quotes)
+}
+
+func (h *QuoteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	quote, err := h.service.GetByID(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+func (h *QuoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.CreateQuoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	quote, err := h.service.Create(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, quote)
+}
+
+func (h *QuoteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.UpdateQuoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	quote, err := h.service.Update(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+func (h *QuoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *QuoteHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.ConvertQuoteInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			respondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	invoice, err := h.service.Convert(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, invoice)
+}
+
+// View, Accept and Decline are public: the signed token in the path is the
+// only credential.
+func (h *QuoteHandler) View(w http.ResponseWriter, r *http.Request) {
+	quote, err := h.service.View(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+func (h *QuoteHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var input services.AcceptQuoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	quote, err := h.service.Accept(r.Context(), chi.URLParam(r, "token"), input)
+	if err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+func (h *QuoteHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	var input services.DeclineQuoteInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+			respondError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+	}
+
+	quote, err := h.service.Decline(r.Context(), chi.URLParam(r, "token"), input)
+	if err != nil {
+		respondError(w, httperr.QuoteErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
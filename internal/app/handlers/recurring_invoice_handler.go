@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type RecurringInvoiceHandler struct {
+	service *services.RecurringInvoiceService
+}
+
+func NewRecurringInvoiceHandler(service *services.RecurringInvoiceService) *RecurringInvoiceHandler {
+	return &RecurringInvoiceHandler{service: service}
+}
+
+func (h *RecurringInvoiceHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	recurring, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, recurring)
+}
+
+func (h *RecurringInvoiceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	recurring, err := h.service.GetByID(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.RecurringInvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, recurring)
+}
+
+func (h *RecurringInvoiceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.CreateRecurringInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	recurring, err := h.service.Create(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.RecurringInvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, recurring)
+}
+
+func (h *RecurringInvoiceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.UpdateRecurringInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	recurring, err := h.service.Update(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.RecurringInvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, recurring)
+}
+
+func (h *RecurringInvoiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		respondError(w, httperr.RecurringInvoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
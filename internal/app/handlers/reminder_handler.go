@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type ReminderHandler struct {
+	service *services.ReminderService
+}
+
+func NewReminderHandler(service *services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{service: service}
+}
+
+func (h *ReminderHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	policy, err := h.service.GetPolicy(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *ReminderHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.UpdateReminderPolicyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.ReminderErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policy)
+}
+
+func (h *ReminderHandler) ListForInvoice(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	invoiceID := chi.URLParam(r, "id")
+	reminders, err := h.service.ListForInvoice(r.Context(), invoiceID, userID)
+	if err != nil {
+		respondError(w, httperr.ReminderErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, reminders)
+}
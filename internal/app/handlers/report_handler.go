@@ -5,8 +5,9 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
 	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
 )
 
 type ReportHandler struct {
@@ -38,7 +39,7 @@ func (h *ReportHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 
 	summary, err := h.service.GetSummary(r.Context(), userID, fromDate, toDate)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, httperr.ReportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -67,13 +68,42 @@ func (h *ReportHandler) GetClientProfitability(w http.ResponseWriter, r *http.Re
 
 	profitability, err := h.service.GetClientProfitability(r.Context(), userID, clientID, fromDate, toDate)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondError(w, httperr.ReportErrorStatus(err), err.Error())
 		return
 	}
 
 	respondJSON(w, http.StatusOK, profitability)
 }
 
+// GetItemRevenue breaks revenue down by catalog item.
+func (h *ReportHandler) GetItemRevenue(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var fromDate, toDate *time.Time
+	if fromDateStr := r.URL.Query().Get("from_date"); fromDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", fromDateStr); err == nil {
+			fromDate = &parsed
+		}
+	}
+	if toDateStr := r.URL.Query().Get("to_date"); toDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", toDateStr); err == nil {
+			toDate = &parsed
+		}
+	}
+
+	report, err := h.service.GetItemRevenue(r.Context(), userID, fromDate, toDate)
+	if err != nil {
+		respondError(w, httperr.ReportErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
 func (h *ReportHandler) GetTaxSummary(w http.ResponseWriter, r *http.Request) {
 	userID := pkgmiddleware.GetUserID(r.Context())
 	if userID == "" {
@@ -103,10 +133,9 @@ func (h *ReportHandler) GetTaxSummary(w http.ResponseWriter, r *http.Request) {
 
 	summary, err := h.service.GetTaxSummary(r.Context(), userID, fromDate, toDate)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondError(w, httperr.ReportErrorStatus(err), err.Error())
 		return
 	}
 
 	respondJSON(w, http.StatusOK, summary)
 }
-
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type TaxCodeHandler struct {
+	service *services.TaxCodeService
+}
+
+func NewTaxCodeHandler(service *services.TaxCodeService) *TaxCodeHandler {
+	return &TaxCodeHandler{service: service}
+}
+
+func (h *TaxCodeHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	taxCodes, err := h.service.List(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, taxCodes)
+}
+
+func (h *TaxCodeHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	taxCode, err := h.service.GetByID(r.Context(), id, userID)
+	if err != nil {
+		respondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, taxCode)
+}
+
+func (h *TaxCodeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.TaxCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	taxCode, err := h.service.Create(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, taxCode)
+}
+
+func (h *TaxCodeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	var input services.TaxCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	taxCode, err := h.service.Update(r.Context(), id, userID, input)
+	if err != nil {
+		respondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, taxCode)
+}
+
+func (h *TaxCodeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.service.Delete(r.Context(), id, userID); err != nil {
+		respondError(w, httperr.TaxCodeErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/nava1525/bilio-backend/internal/app/httperr"
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/services"
+	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
+)
+
+type WorkspaceHandler struct {
+	service *services.WorkspaceService
+}
+
+func NewWorkspaceHandler(service *services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{service: service}
+}
+
+func (h *WorkspaceHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	profile, err := h.service.GetProfile(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profile)
+}
+
+func (h *WorkspaceHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.UpdateWorkspaceProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profile)
+}
+
+func (h *WorkspaceHandler) GetLogo(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	logo, content, err := h.service.OpenLogo(r.Context(), userID)
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", logo.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(logo.SizeBytes, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+// UploadLogo takes a multipart/form-data request with the image in the
+// "file" field.
+func (h *WorkspaceHandler) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxLogoSize+multipartOverhead)
+	part, err := attachmentPart(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(w, http.StatusRequestEntityTooLarge, services.ErrLogoTooLarge.Error())
+			return
+		}
+		respondError(w, http.StatusBadRequest, "invalid upload: send the image as multipart/form-data in the \"file\" field")
+		return
+	}
+	defer part.Close()
+
+	profile, err := h.service.UploadLogo(r.Context(), userID, services.UploadLogoInput{Content: part})
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profile)
+}
+
+func (h *WorkspaceHandler) DeleteLogo(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.service.DeleteLogo(r.Context(), userID); err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	templates, err := h.service.ListTemplates(r.Context(), userID)
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, templates)
+}
+
+// GetTemplate returns the template in use for the kind, or with ?layout= the
+// built-in template of that layout.
+func (h *WorkspaceHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	kind := models.TemplateKind(chi.URLParam(r, "kind"))
+	layout := models.InvoiceLayout(r.URL.Query().Get("layout"))
+	template, err := h.service.GetTemplate(r.Context(), userID, kind, layout)
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, template)
+}
+
+func (h *WorkspaceHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.SaveTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	kind := models.TemplateKind(chi.URLParam(r, "kind"))
+	template, err := h.service.SaveTemplate(r.Context(), userID, kind, input)
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, template)
+}
+
+func (h *WorkspaceHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	kind := models.TemplateKind(chi.URLParam(r, "kind"))
+	if err := h.service.DeleteTemplate(r.Context(), userID, kind); err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	userID := pkgmiddleware.GetUserID(r.Context())
+	if userID == "" {
+		respondError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var input services.PreviewInvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	preview, err := h.service.Preview(r.Context(), userID, input)
+	if err != nil {
+		respondError(w, httperr.WorkspaceErrorStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", preview.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(preview.Content)))
+	w.Header().Set("Content-Security-Policy", services.InvoicePageCSP)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(preview.Content)
+}
@@ -0,0 +1,202 @@
+// Package httperr maps service errors to HTTP status codes. The chi handlers
+// and the serverless functions under api/v1 both use it, so it depends on
+// nothing but the services package.
+package httperr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/nava1525/bilio-backend/internal/app/services"
+)
+
+// CatalogItemErrorStatus maps catalog item service errors to HTTP status codes.
+func CatalogItemErrorStatus(err error) int {
+	if errors.Is(err, services.ErrCatalogItemNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ClientCreditErrorStatus maps client credit errors to HTTP status codes.
+func ClientCreditErrorStatus(err error) int {
+	if errors.Is(err, services.ErrClientNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// CreditNoteErrorStatus maps credit note service errors to HTTP status codes.
+func CreditNoteErrorStatus(err error) int {
+	if errors.Is(err, services.ErrCreditNoteNotFound) || errors.Is(err, services.ErrInvoiceNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsInvalidTransitionError(err); ok {
+		return http.StatusConflict
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ExchangeRateErrorStatus maps exchange rate service errors to HTTP status
+// codes.
+func ExchangeRateErrorStatus(err error) int {
+	if errors.Is(err, services.ErrExchangeRateNotFound) {
+		return http.StatusNotFound
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, services.ErrExchangeRateFileTooLarge) || errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// AttachmentErrorStatus maps invoice attachment errors to HTTP status codes.
+func AttachmentErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvoiceNotFound) || errors.Is(err, services.ErrAttachmentNotFound) {
+		return http.StatusNotFound
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, services.ErrAttachmentTooLarge) || errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, services.ErrUnsupportedAttachmentType) {
+		return http.StatusUnsupportedMediaType
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// InvoiceErrorStatus maps invoice service errors to HTTP status codes.
+func InvoiceErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvoiceNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, services.ErrInvoiceNotDeletable) || errors.Is(err, services.ErrDraftNumberInUse) {
+		return http.StatusConflict
+	}
+	if _, ok := services.AsInvalidTransitionError(err); ok {
+		return http.StatusConflict
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ShareErrorStatus maps invoice share service errors to HTTP status codes.
+func ShareErrorStatus(err error) int {
+	if errors.Is(err, services.ErrShareLinkNotFound) || errors.Is(err, services.ErrInvoiceNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// LateFeeErrorStatus maps late fee service errors to HTTP status codes.
+func LateFeeErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvoiceNotFound) || errors.Is(err, services.ErrClientNotFound) ||
+		errors.Is(err, services.ErrLateFeePolicyNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// QuoteErrorStatus maps quote service errors to HTTP status codes.
+func QuoteErrorStatus(err error) int {
+	if errors.Is(err, services.ErrQuoteNotFound) || errors.Is(err, services.ErrQuoteLinkNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, services.ErrQuoteNotDeletable) || errors.Is(err, services.ErrQuoteAlreadyConverted) ||
+		errors.Is(err, services.ErrQuoteChanged) {
+		return http.StatusConflict
+	}
+	if _, ok := services.AsInvalidQuoteTransitionError(err); ok {
+		return http.StatusConflict
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// RecurringInvoiceErrorStatus maps recurring invoice service errors to HTTP
+// status codes.
+func RecurringInvoiceErrorStatus(err error) int {
+	if errors.Is(err, services.ErrRecurringInvoiceNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ReminderErrorStatus maps reminder service errors to HTTP status codes.
+func ReminderErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvoiceNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ReportErrorStatus maps report errors to HTTP status codes. Missing exchange
+// rates are listed in the report rather than failing it.
+func ReportErrorStatus(err error) int {
+	if errors.Is(err, services.ErrClientNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// TaxCodeErrorStatus maps tax code service errors to HTTP status codes.
+func TaxCodeErrorStatus(err error) int {
+	if errors.Is(err, services.ErrTaxCodeNotFound) {
+		return http.StatusNotFound
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// WorkspaceErrorStatus maps workspace branding and template errors to HTTP
+// status codes.
+func WorkspaceErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvoiceNotFound) || errors.Is(err, services.ErrClientNotFound) ||
+		errors.Is(err, services.ErrLogoNotFound) || errors.Is(err, services.ErrWorkspaceTemplateNotFound) {
+		return http.StatusNotFound
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, services.ErrLogoTooLarge) || errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, services.ErrUnsupportedLogoType) {
+		return http.StatusUnsupportedMediaType
+	}
+	if _, ok := services.AsValidationError(err); ok {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
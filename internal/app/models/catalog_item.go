@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// CatalogUnit is what a catalog item's quantity counts.
+type CatalogUnit string
+
+const (
+	CatalogUnitHour  CatalogUnit = "hour"
+	CatalogUnitDay   CatalogUnit = "day"
+	CatalogUnitPiece CatalogUnit = "piece"
+)
+
+// CatalogItem is a product or service the workspace sells. Invoice and quote
+// items that reference it copy its values when they are priced.
+type CatalogItem struct {
+	ID          string             `json:"id"`
+	UserID      string             `json:"user_id"`
+	Name        string             `json:"name"`
+	Description *string            `json:"description,omitempty"`
+	SKU         *string            `json:"sku,omitempty"`
+	Unit        CatalogUnit        `json:"unit"`
+	Prices      []CatalogItemPrice `json:"prices"`
+	TaxCodeID   *string            `json:"tax_code_id,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// CatalogItemPrice is a catalog item's default unit price in one currency.
+type CatalogItemPrice struct {
+	Currency  string        `json:"currency"`
+	UnitPrice money.Decimal `json:"unit_price"`
+}
@@ -1,18 +1,44 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// PaymentTerms sets when a client's invoices fall due: "due_on_receipt",
+// "net_N" for N days after the issue date, or "eom" for the last day of the
+// issue month.
+type PaymentTerms string
+
+const (
+	PaymentTermsDueOnReceipt PaymentTerms = "due_on_receipt"
+	PaymentTermsEndOfMonth   PaymentTerms = "eom"
+)
 
 type Client struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Name      string    `json:"name"`
-	Email     *string   `json:"email,omitempty"`
-	Company   *string   `json:"company,omitempty"`
-	Phone     *string   `json:"phone,omitempty"`
-	Address   *string   `json:"address,omitempty"`
-	TaxID     *string   `json:"tax_id,omitempty"`
-	Currency  string    `json:"currency"`
+	ID       string  `json:"id"`
+	UserID   string  `json:"user_id"`
+	Name     string  `json:"name"`
+	Email    *string `json:"email,omitempty"`
+	Company  *string `json:"company,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+	Address  *string `json:"address,omitempty"`
+	TaxID    *string `json:"tax_id,omitempty"`
+	Currency string  `json:"currency"`
+
+	// Billing profile, applied to new invoices that leave the field out.
+	PaymentTerms     PaymentTerms   `json:"payment_terms,omitempty"`
+	DefaultTaxRate   *money.Decimal `json:"default_tax_rate,omitempty"`
+	DefaultTaxCodeID *string        `json:"default_tax_code_id,omitempty"`
+	Language         *string        `json:"language,omitempty"`
+	CCEmails         []string       `json:"cc_emails"`
+	NotesFooter      *string        `json:"notes_footer,omitempty"`
+
+	// CreditBalances is the client's available credit, one entry per
+	// currency. Only the client detail endpoint fills it.
+	CreditBalances []ClientCreditBalance `json:"credit_balances,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
-
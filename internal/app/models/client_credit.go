@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+type ClientCreditType string
+
+const (
+	ClientCreditDeposit     ClientCreditType = "deposit"
+	ClientCreditOverpayment ClientCreditType = "overpayment"
+	ClientCreditCreditNote  ClientCreditType = "credit_note"
+	ClientCreditApplied     ClientCreditType = "applied"
+)
+
+// PaymentMethodClientCredit marks payments settled from, or moved to, the
+// client's credit balance.
+const PaymentMethodClientCredit = "client_credit"
+
+// ClientCredit is one entry in a client's credit ledger. Amount is positive
+// for credit received and negative for credit applied to an invoice.
+type ClientCredit struct {
+	ID            string           `json:"id"`
+	UserID        string           `json:"user_id"`
+	ClientID      string           `json:"client_id"`
+	Type          ClientCreditType `json:"type"`
+	Amount        money.Decimal    `json:"amount"`
+	Currency      string           `json:"currency"`
+	InvoiceID     *string          `json:"invoice_id,omitempty"`
+	PaymentID     *string          `json:"payment_id,omitempty"`
+	CreditNoteID  *string          `json:"credit_note_id,omitempty"`
+	PaymentMethod *string          `json:"payment_method,omitempty"`
+	Notes         *string          `json:"notes,omitempty"`
+	EntryDate     time.Time        `json:"entry_date"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// ClientCreditBalance is a client's available credit in one currency.
+type ClientCreditBalance struct {
+	Currency string        `json:"currency"`
+	Amount   money.Decimal `json:"amount"`
+}
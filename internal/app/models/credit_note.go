@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// CreditNote reverses all or part of an issued invoice. Amounts are positive
+// and reduce the invoice's balance; a refund, when one is paid out, is
+// recorded as a negative payment on the invoice.
+type CreditNote struct {
+	ID               string           `json:"id"`
+	UserID           string           `json:"user_id"`
+	InvoiceID        string           `json:"invoice_id"`
+	ClientID         string           `json:"client_id"`
+	CreditNoteNumber string           `json:"credit_note_number"`
+	IssueDate        time.Time        `json:"issue_date"`
+	Currency         string           `json:"currency"`
+	Reason           *string          `json:"reason,omitempty"`
+	Subtotal         money.Decimal    `json:"subtotal"`
+	DiscountAmount   money.Decimal    `json:"discount_amount"`
+	TaxAmount        money.Decimal    `json:"tax_amount"`
+	TaxBreakdown     []InvoiceTaxLine `json:"tax_breakdown,omitempty"`
+	Total            money.Decimal    `json:"total"`
+	RefundedAmount   money.Decimal    `json:"refunded_amount"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Items            []CreditNoteItem `json:"items,omitempty"`
+	Refund           *Payment         `json:"refund,omitempty"`
+}
+
+type CreditNoteItem struct {
+	ID            string           `json:"id"`
+	CreditNoteID  string           `json:"credit_note_id"`
+	InvoiceItemID *string          `json:"invoice_item_id,omitempty"`
+	Description   string           `json:"description"`
+	Quantity      money.Decimal    `json:"quantity"`
+	UnitPrice     money.Decimal    `json:"unit_price"`
+	Amount        money.Decimal    `json:"amount"`
+	Taxes         []InvoiceItemTax `json:"taxes,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
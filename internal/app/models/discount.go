@@ -0,0 +1,28 @@
+package models
+
+import "github.com/nava1525/bilio-backend/pkg/money"
+
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFixed      DiscountType = "fixed"
+)
+
+// Discount is a reduction applied to an invoice item or to a whole invoice.
+// Percentage values are 0-100; fixed values are amounts in the invoice
+// currency.
+type Discount struct {
+	Type  DiscountType  `json:"type"`
+	Value money.Decimal `json:"value"`
+}
+
+// DiscountTiming says whether an invoice-level discount reduces the taxable
+// amount (pre_tax) or is taken off the total after tax (post_tax). Item
+// discounts always apply before tax.
+type DiscountTiming string
+
+const (
+	DiscountTimingPreTax  DiscountTiming = "pre_tax"
+	DiscountTimingPostTax DiscountTiming = "post_tax"
+)
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// ExchangeRateSource records how a rate was loaded.
+type ExchangeRateSource string
+
+const (
+	ExchangeRateSourceUpload   ExchangeRateSource = "upload"
+	ExchangeRateSourceProvider ExchangeRateSource = "provider"
+)
+
+// ExchangeRate is the value of one unit of Currency in BaseCurrency on
+// RateDate.
+type ExchangeRate struct {
+	ID           string             `json:"id"`
+	UserID       string             `json:"user_id"`
+	Currency     string             `json:"currency"`
+	BaseCurrency string             `json:"base_currency"`
+	RateDate     time.Time          `json:"rate_date"`
+	Rate         money.Decimal      `json:"rate"`
+	Source       ExchangeRateSource `json:"source"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
@@ -1,60 +1,93 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
 
 type InvoiceStatus string
 
 const (
-	InvoiceStatusDraft    InvoiceStatus = "draft"
-	InvoiceStatusPending InvoiceStatus = "pending"
-	InvoiceStatusPaid    InvoiceStatus = "paid"
-	InvoiceStatusOverdue InvoiceStatus = "overdue"
-	InvoiceStatusCancelled InvoiceStatus = "cancelled"
+	InvoiceStatusDraft         InvoiceStatus = "draft"
+	InvoiceStatusPending       InvoiceStatus = "pending"
+	InvoiceStatusPartiallyPaid InvoiceStatus = "partially_paid"
+	InvoiceStatusPaid          InvoiceStatus = "paid"
+	InvoiceStatusOverdue       InvoiceStatus = "overdue"
+	InvoiceStatusCancelled     InvoiceStatus = "cancelled"
+	InvoiceStatusVoid          InvoiceStatus = "void"
 )
 
 type Invoice struct {
-	ID           string         `json:"id"`
-	UserID       string         `json:"user_id"`
-	ClientID     string         `json:"client_id"`
-	InvoiceNumber string        `json:"invoice_number"`
-	Status       InvoiceStatus  `json:"status"`
-	IssueDate    time.Time      `json:"issue_date"`
-	DueDate      *time.Time     `json:"due_date,omitempty"`
-	Currency     string         `json:"currency"`
-	Subtotal     float64        `json:"subtotal"`
-	TaxRate      float64        `json:"tax_rate"`
-	TaxAmount    float64        `json:"tax_amount"`
-	Total        float64        `json:"total"`
-	Notes        *string        `json:"notes,omitempty"`
-	PaymentLink  *string        `json:"payment_link,omitempty"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	Items        []InvoiceItem  `json:"items,omitempty"`
-	Client       *Client        `json:"client,omitempty"`
-	Payments     []Payment      `json:"payments,omitempty"`
+	ID                 string           `json:"id"`
+	UserID             string           `json:"user_id"`
+	ClientID           string           `json:"client_id"`
+	InvoiceNumber      string           `json:"invoice_number"`
+	Status             InvoiceStatus    `json:"status"`
+	IssueDate          time.Time        `json:"issue_date"`
+	DueDate            *time.Time       `json:"due_date,omitempty"`
+	Currency           string           `json:"currency"`
+	Subtotal           money.Decimal    `json:"subtotal"`
+	LineDiscountAmount money.Decimal    `json:"line_discount_amount"`
+	Discount           *Discount        `json:"discount,omitempty"`
+	DiscountTiming     DiscountTiming   `json:"discount_timing"`
+	DiscountAmount     money.Decimal    `json:"discount_amount"`
+	TaxRate            money.Decimal    `json:"tax_rate"`
+	TaxAmount          money.Decimal    `json:"tax_amount"`
+	TaxBreakdown       []InvoiceTaxLine `json:"tax_breakdown,omitempty"`
+	Total              money.Decimal    `json:"total"`
+	AmountPaid         money.Decimal    `json:"amount_paid"`
+	CreditedAmount     money.Decimal    `json:"credited_amount"`
+	BalanceDue         money.Decimal    `json:"balance_due"`
+	Notes              *string          `json:"notes,omitempty"`
+	Language           *string          `json:"language,omitempty"`
+	PaymentLink        *string          `json:"payment_link,omitempty"`
+	SentAt             *time.Time       `json:"sent_at,omitempty"`
+	SentTo             *string          `json:"sent_to,omitempty"`
+	RecurringInvoiceID *string          `json:"recurring_invoice_id,omitempty"`
+	QuoteID            *string          `json:"quote_id,omitempty"`
+	VoidedAt           *time.Time       `json:"voided_at,omitempty"`
+	VoidReason         *string          `json:"void_reason,omitempty"`
+	RecurringPeriod    *time.Time       `json:"-"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	Items              []InvoiceItem    `json:"items,omitempty"`
+	Client             *Client          `json:"client,omitempty"`
+	Payments           []Payment        `json:"payments,omitempty"`
 }
 
 type InvoiceItem struct {
-	ID          string    `json:"id"`
-	InvoiceID   string    `json:"invoice_id"`
-	Description string    `json:"description"`
-	Quantity    float64   `json:"quantity"`
-	UnitPrice   float64   `json:"unit_price"`
-	Amount      float64   `json:"amount"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             string           `json:"id"`
+	InvoiceID      string           `json:"invoice_id"`
+	Description    string           `json:"description"`
+	Quantity       money.Decimal    `json:"quantity"`
+	UnitPrice      money.Decimal    `json:"unit_price"`
+	Discount       *Discount        `json:"discount,omitempty"`
+	DiscountAmount money.Decimal    `json:"discount_amount"`
+	Amount         money.Decimal    `json:"amount"`
+	TaxCodeID      *string          `json:"tax_code_id,omitempty"`
+	Taxes          []InvoiceItemTax `json:"taxes,omitempty"`
+	// CatalogItemID links a line billed from the catalog; Unit and SKU are
+	// copied from the catalog item when the line is priced.
+	CatalogItemID *string      `json:"catalog_item_id,omitempty"`
+	Unit          *CatalogUnit `json:"unit,omitempty"`
+	SKU           *string      `json:"sku,omitempty"`
+	// LateFee marks a line added by the late fee policy.
+	LateFee   bool      `json:"late_fee,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Payment struct {
-	ID            string         `json:"id"`
-	InvoiceID     string         `json:"invoice_id"`
-	Amount        float64        `json:"amount"`
-	Currency      string         `json:"currency"`
-	PaymentMethod *string        `json:"payment_method,omitempty"`
-	PaymentDate   time.Time      `json:"payment_date"`
-	TransactionID *string        `json:"transaction_id,omitempty"`
-	Notes         *string        `json:"notes,omitempty"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
+	ID            string        `json:"id"`
+	InvoiceID     string        `json:"invoice_id"`
+	Amount        money.Decimal `json:"amount"`
+	Currency      string        `json:"currency"`
+	PaymentMethod *string       `json:"payment_method,omitempty"`
+	PaymentDate   time.Time     `json:"payment_date"`
+	TransactionID *string       `json:"transaction_id,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+	CreditNoteID  *string       `json:"credit_note_id,omitempty"` // set on refunds
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
 }
-
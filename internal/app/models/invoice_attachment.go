@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// InvoiceAttachment is a file, such as a timesheet or signed statement of
+// work, kept with an invoice and sent along when it is emailed.
+type InvoiceAttachment struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	InvoiceID   string    `json:"invoice_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type InvoiceEventAction string
+
+const (
+	InvoiceEventVoided  InvoiceEventAction = "voided"
+	InvoiceEventDeleted InvoiceEventAction = "deleted"
+)
+
+// InvoiceEvent is an audit record of a void or deletion. Events are kept
+// after a draft is deleted, so InvoiceNumber records what it was.
+type InvoiceEvent struct {
+	ID            string             `json:"id"`
+	UserID        string             `json:"user_id"`
+	InvoiceID     string             `json:"invoice_id"`
+	InvoiceNumber string             `json:"invoice_number"`
+	Action        InvoiceEventAction `json:"action"`
+	FromStatus    InvoiceStatus      `json:"from_status"`
+	ToStatus      *InvoiceStatus     `json:"to_status,omitempty"`
+	Reason        *string            `json:"reason,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// InvoiceNumbering is a workspace's automatic invoice, credit note and quote
+// numbering settings. All sequences share the yearly reset setting.
+type InvoiceNumbering struct {
+	UserID                 string    `json:"user_id"`
+	Format                 string    `json:"format"`
+	YearlyReset            bool      `json:"yearly_reset"`
+	NextSequence           int64     `json:"next_sequence"`
+	NextNumber             string    `json:"next_number"`
+	CreditNoteFormat       string    `json:"credit_note_format"`
+	NextCreditNoteSequence int64     `json:"next_credit_note_sequence"`
+	NextCreditNoteNumber   string    `json:"next_credit_note_number"`
+	QuoteFormat            string    `json:"quote_format"`
+	NextQuoteSequence      int64     `json:"next_quote_sequence"`
+	NextQuoteNumber        string    `json:"next_quote_number"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
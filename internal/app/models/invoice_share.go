@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type InvoiceShareFormat string
+
+const (
+	InvoiceShareFormatHTML InvoiceShareFormat = "html"
+	InvoiceShareFormatJSON InvoiceShareFormat = "json"
+	InvoiceShareFormatPDF  InvoiceShareFormat = "pdf"
+)
+
+// InvoiceShareLink grants public, read-only access to one invoice until it
+// expires or is revoked. Token is derived from the link when it is loaded and
+// is never stored.
+type InvoiceShareLink struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	InvoiceID    string     `json:"invoice_id"`
+	Token        string     `json:"token"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ViewCount    int        `json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// InvoiceShareView records one time a share link was opened.
+type InvoiceShareView struct {
+	ID          string             `json:"id"`
+	ShareLinkID string             `json:"share_link_id"`
+	InvoiceID   string             `json:"invoice_id"`
+	Format      InvoiceShareFormat `json:"format"`
+	IPAddress   *string            `json:"ip_address,omitempty"`
+	UserAgent   *string            `json:"user_agent,omitempty"`
+	ViewedAt    time.Time          `json:"viewed_at"`
+}
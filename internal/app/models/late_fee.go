@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+type LateFeeType string
+
+const (
+	LateFeeFlat       LateFeeType = "flat"
+	LateFeePercentage LateFeeType = "percentage"
+)
+
+// LateFeePolicy decides what overdue invoices are charged. The workspace
+// policy has no ClientID; a client policy overrides it for that client.
+type LateFeePolicy struct {
+	UserID   string      `json:"user_id"`
+	ClientID *string     `json:"client_id,omitempty"`
+	Enabled  bool        `json:"enabled"`
+	FeeType  LateFeeType `json:"fee_type"`
+	// Amount is the flat fee, in the invoice's currency, or the monthly
+	// interest rate in percent.
+	Amount    money.Decimal `json:"amount"`
+	GraceDays int           `json:"grace_days"`
+	// MaxAmount caps the late fees charged on one invoice.
+	MaxAmount *money.Decimal `json:"max_amount,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// InvoiceLateFee records one late fee charged on an invoice.
+type InvoiceLateFee struct {
+	ID            string        `json:"id"`
+	UserID        string        `json:"user_id"`
+	InvoiceID     string        `json:"invoice_id"`
+	InvoiceItemID *string       `json:"invoice_item_id,omitempty"`
+	Period        int           `json:"period"`
+	Amount        money.Decimal `json:"amount"`
+	ChargedAt     time.Time     `json:"charged_at"`
+}
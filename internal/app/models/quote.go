@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+type QuoteStatus string
+
+const (
+	QuoteStatusDraft    QuoteStatus = "draft"
+	QuoteStatusSent     QuoteStatus = "sent"
+	QuoteStatusAccepted QuoteStatus = "accepted"
+	QuoteStatusDeclined QuoteStatus = "declined"
+	QuoteStatusExpired  QuoteStatus = "expired"
+)
+
+// Quote is an estimate sent to a client before any work is invoiced. It is
+// priced exactly like an invoice and, once accepted, converts into one.
+type Quote struct {
+	ID                 string           `json:"id"`
+	UserID             string           `json:"user_id"`
+	ClientID           string           `json:"client_id"`
+	QuoteNumber        string           `json:"quote_number"`
+	Status             QuoteStatus      `json:"status"`
+	IssueDate          time.Time        `json:"issue_date"`
+	ExpiryDate         *time.Time       `json:"expiry_date,omitempty"`
+	Currency           string           `json:"currency"`
+	Subtotal           money.Decimal    `json:"subtotal"`
+	LineDiscountAmount money.Decimal    `json:"line_discount_amount"`
+	Discount           *Discount        `json:"discount,omitempty"`
+	DiscountTiming     DiscountTiming   `json:"discount_timing"`
+	DiscountAmount     money.Decimal    `json:"discount_amount"`
+	TaxRate            money.Decimal    `json:"tax_rate"`
+	TaxAmount          money.Decimal    `json:"tax_amount"`
+	TaxBreakdown       []InvoiceTaxLine `json:"tax_breakdown,omitempty"`
+	Total              money.Decimal    `json:"total"`
+	Notes              *string          `json:"notes,omitempty"`
+	SentAt             *time.Time       `json:"sent_at,omitempty"`
+	AcceptedAt         *time.Time       `json:"accepted_at,omitempty"`
+	AcceptedBy         *string          `json:"accepted_by,omitempty"`
+	DeclinedAt         *time.Time       `json:"declined_at,omitempty"`
+	DeclineReason      *string          `json:"decline_reason,omitempty"`
+	InvoiceID          *string          `json:"invoice_id,omitempty"`
+	ConvertedAt        *time.Time       `json:"converted_at,omitempty"`
+	// Token is the credential for the client's public accept link. It is
+	// derived from the ID, not stored, and only set once the quote is sent.
+	Token     string      `json:"token,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Items     []QuoteItem `json:"items,omitempty"`
+	Client    *Client     `json:"client,omitempty"`
+}
+
+type QuoteItem struct {
+	ID             string           `json:"id"`
+	QuoteID        string           `json:"quote_id"`
+	Description    string           `json:"description"`
+	Quantity       money.Decimal    `json:"quantity"`
+	UnitPrice      money.Decimal    `json:"unit_price"`
+	Discount       *Discount        `json:"discount,omitempty"`
+	DiscountAmount money.Decimal    `json:"discount_amount"`
+	Amount         money.Decimal    `json:"amount"`
+	TaxCodeID      *string          `json:"tax_code_id,omitempty"`
+	Taxes          []InvoiceItemTax `json:"taxes,omitempty"`
+	CatalogItemID  *string          `json:"catalog_item_id,omitempty"`
+	Unit           *CatalogUnit     `json:"unit,omitempty"`
+	SKU            *string          `json:"sku,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
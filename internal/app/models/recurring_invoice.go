@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+type RecurringCadence string
+
+const (
+	RecurringCadenceWeekly    RecurringCadence = "weekly"
+	RecurringCadenceMonthly   RecurringCadence = "monthly"
+	RecurringCadenceQuarterly RecurringCadence = "quarterly"
+	RecurringCadenceYearly    RecurringCadence = "yearly"
+	RecurringCadenceCustom    RecurringCadence = "custom"
+)
+
+type RecurringInvoice struct {
+	ID               string                 `json:"id"`
+	UserID           string                 `json:"user_id"`
+	ClientID         string                 `json:"client_id"`
+	Name             string                 `json:"name"`
+	Currency         string                 `json:"currency"`
+	TaxRate          money.Decimal          `json:"tax_rate"`
+	Discount         *Discount              `json:"discount,omitempty"`
+	DiscountTiming   DiscountTiming         `json:"discount_timing"`
+	Notes            *string                `json:"notes,omitempty"`
+	Items            []RecurringInvoiceItem `json:"items"`
+	Cadence          RecurringCadence       `json:"cadence"`
+	CronExpression   *string                `json:"cron_expression,omitempty"`
+	StartDate        time.Time              `json:"start_date"`
+	EndDate          *time.Time             `json:"end_date,omitempty"`
+	NextRunDate      *time.Time             `json:"next_run_date,omitempty"`
+	LastRunAt        *time.Time             `json:"last_run_at,omitempty"`
+	PaymentTermsDays *int                   `json:"payment_terms_days,omitempty"`
+	AutoSend         bool                   `json:"auto_send"`
+	Active           bool                   `json:"active"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+}
+
+type RecurringInvoiceItem struct {
+	Description string        `json:"description"`
+	Quantity    money.Decimal `json:"quantity"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	Discount    *Discount     `json:"discount,omitempty"`
+	TaxCodeID   *string       `json:"tax_code_id,omitempty"`
+	// CatalogItemID, Unit and SKU carry a catalog item's link onto every
+	// generated invoice; the other fields were copied from it when the
+	// schedule was saved.
+	CatalogItemID *string      `json:"catalog_item_id,omitempty"`
+	Unit          *CatalogUnit `json:"unit,omitempty"`
+	SKU           *string      `json:"sku,omitempty"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ReminderPolicy is a workspace's schedule of payment reminders. Each step
+// fires once per open invoice, OffsetDays from its due date.
+type ReminderPolicy struct {
+	UserID    string         `json:"user_id"`
+	Enabled   bool           `json:"enabled"`
+	Steps     []ReminderStep `json:"steps"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ReminderStep is one reminder in a policy. Negative offsets are days before
+// the due date, positive ones days overdue. Subject and Body may contain
+// placeholders such as {invoice_number} and {amount_due}.
+type ReminderStep struct {
+	OffsetDays int    `json:"offset_days"`
+	Subject    string `json:"subject"`
+	Body       string `json:"body"`
+}
+
+type InvoiceReminderStatus string
+
+const (
+	InvoiceReminderSent    InvoiceReminderStatus = "sent"
+	InvoiceReminderSkipped InvoiceReminderStatus = "skipped"
+)
+
+// InvoiceReminder records a reminder step handled for an invoice.
+type InvoiceReminder struct {
+	ID         string                `json:"id"`
+	UserID     string                `json:"user_id"`
+	InvoiceID  string                `json:"invoice_id"`
+	OffsetDays int                   `json:"offset_days"`
+	Status     InvoiceReminderStatus `json:"status"`
+	SentTo     *string               `json:"sent_to,omitempty"`
+	Subject    string                `json:"subject"`
+	SentAt     time.Time             `json:"sent_at"`
+}
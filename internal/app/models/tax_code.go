@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// TaxCode groups the tax components charged together on a line, such as
+// CGST 9% + SGST 9% for intra-state GST, or a single VAT rate.
+type TaxCode struct {
+	ID         string         `json:"id"`
+	UserID     string         `json:"user_id"`
+	Code       string         `json:"code"`
+	Name       string         `json:"name"`
+	Components []TaxComponent `json:"components"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+type TaxComponent struct {
+	Name string        `json:"name"`
+	Rate money.Decimal `json:"rate"` // percentage
+}
+
+// InvoiceItemTax is one tax component as applied to a single invoice item.
+type InvoiceItemTax struct {
+	Name   string        `json:"name"`
+	Rate   money.Decimal `json:"rate"`
+	Amount money.Decimal `json:"amount"`
+}
+
+// InvoiceTaxLine totals one tax component across an invoice.
+type InvoiceTaxLine struct {
+	Name          string        `json:"name"`
+	Rate          money.Decimal `json:"rate"`
+	TaxableAmount money.Decimal `json:"taxable_amount"`
+	Amount        money.Decimal `json:"amount"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// InvoiceLayout selects the built-in design used for a workspace's invoice
+// PDFs, shared invoice pages and invoice emails.
+type InvoiceLayout string
+
+const (
+	InvoiceLayoutClassic InvoiceLayout = "classic"
+	InvoiceLayoutModern  InvoiceLayout = "modern"
+	InvoiceLayoutMinimal InvoiceLayout = "minimal"
+)
+
+// InvoiceLayouts lists the built-in layouts in display order.
+var InvoiceLayouts = []InvoiceLayout{InvoiceLayoutClassic, InvoiceLayoutModern, InvoiceLayoutMinimal}
+
+// WorkspaceProfile is the branding a workspace puts on its invoices.
+type WorkspaceProfile struct {
+	UserID      string  `json:"user_id"`
+	LegalName   *string `json:"legal_name,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	TaxID       *string `json:"tax_id,omitempty"`
+	BankDetails *string `json:"bank_details,omitempty"`
+	FooterText  *string `json:"footer_text,omitempty"`
+	// BrandColor and AccentColor are "#rrggbb" colours.
+	BrandColor    *string       `json:"brand_color,omitempty"`
+	AccentColor   *string       `json:"accent_color,omitempty"`
+	InvoiceLayout InvoiceLayout `json:"invoice_layout"`
+	// Locale, such as "en-IN", sets the language and number and date formats
+	// of invoices whose client has no language of its own.
+	Locale string `json:"locale"`
+	// BaseCurrency is the currency reports are converted into.
+	BaseCurrency string         `json:"base_currency"`
+	Logo         *WorkspaceLogo `json:"logo,omitempty"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// WorkspaceLogo describes an uploaded logo; the image itself is kept in the
+// blob store.
+type WorkspaceLogo struct {
+	StorageKey  string `json:"-"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// TemplateKind names the rendering a workspace template overrides.
+type TemplateKind string
+
+const (
+	TemplateKindShare TemplateKind = "share"
+	TemplateKindEmail TemplateKind = "email"
+)
+
+// WorkspaceTemplate is a workspace's own html/template source for one kind of
+// invoice rendering, used instead of its layout's built-in template.
+type WorkspaceTemplate struct {
+	UserID    string       `json:"user_id"`
+	Kind      TemplateKind `json:"kind"`
+	Content   string       `json:"content"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
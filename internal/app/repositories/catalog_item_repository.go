@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+var ErrCatalogItemSKUExists = errors.New("catalog item sku already exists")
+
+type CatalogItemRepository interface {
+	List(ctx context.Context, userID string, filters CatalogItemFilters) ([]models.CatalogItem, error)
+	GetByID(ctx context.Context, id string, userID string) (*models.CatalogItem, error)
+	Create(ctx context.Context, item *models.CatalogItem) (*models.CatalogItem, error)
+	Update(ctx context.Context, item *models.CatalogItem) (*models.CatalogItem, error)
+	Delete(ctx context.Context, id string, userID string) error
+}
+
+type CatalogItemFilters struct {
+	// Search matches name or SKU, case-insensitively.
+	Search *string
+}
+
+type postgresCatalogItemRepository struct {
+	db *sql.DB
+}
+
+func NewCatalogItemRepository(db *sql.DB) CatalogItemRepository {
+	return &postgresCatalogItemRepository{db: db}
+}
+
+const catalogItemColumns = `id, user_id, name, description, sku, unit, prices, tax_code_id, created_at, updated_at`
+
+func scanCatalogItem(row rowScanner) (*models.CatalogItem, error) {
+	var item models.CatalogItem
+	var description, sku, taxCodeID sql.NullString
+	var prices []byte
+	if err := row.Scan(&item.ID, &item.UserID, &item.Name, &description, &sku, &item.Unit, &prices, &taxCodeID,
+		&item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if description.Valid {
+		item.Description = &description.String
+	}
+	if sku.Valid {
+		item.SKU = &sku.String
+	}
+	if taxCodeID.Valid {
+		item.TaxCodeID = &taxCodeID.String
+	}
+	if err := json.Unmarshal(prices, &item.Prices); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func translateCatalogItemWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrCatalogItemSKUExists
+	}
+	return err
+}
+
+func (r *postgresCatalogItemRepository) List(ctx context.Context, userID string, filters CatalogItemFilters) ([]models.CatalogItem, error) {
+	query := `SELECT ` + catalogItemColumns + ` FROM catalog_items WHERE user_id = $1`
+	args := []interface{}{userID}
+
+	if filters.Search != nil {
+		query += ` AND (name ILIKE $2 OR sku ILIKE $2)`
+		args = append(args, "%"+*filters.Search+"%")
+	}
+
+	query += ` ORDER BY name, id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.CatalogItem
+	for rows.Next() {
+		item, err := scanCatalogItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *item)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresCatalogItemRepository) GetByID(ctx context.Context, id string, userID string) (*models.CatalogItem, error) {
+	item, err := scanCatalogItem(r.db.QueryRowContext(ctx,
+		`SELECT `+catalogItemColumns+` FROM catalog_items WHERE id = $1 AND user_id = $2`,
+		id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *postgresCatalogItemRepository) Create(ctx context.Context, item *models.CatalogItem) (*models.CatalogItem, error) {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	prices, err := marshalJSONList(item.Prices)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO catalog_items (id, user_id, name, description, sku, unit, prices, tax_code_id, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)`,
+		id, item.UserID, item.Name, item.Description, item.SKU, item.Unit, prices, item.TaxCodeID, now)
+	if err != nil {
+		return nil, translateCatalogItemWriteError(err)
+	}
+
+	item.ID = id
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	return item, nil
+}
+
+func (r *postgresCatalogItemRepository) Update(ctx context.Context, item *models.CatalogItem) (*models.CatalogItem, error) {
+	now := time.Now().UTC()
+
+	prices, err := marshalJSONList(item.Prices)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE catalog_items SET name = $1, description = $2, sku = $3, unit = $4, prices = $5, tax_code_id = $6,
+		 updated_at = $7
+		 WHERE id = $8 AND user_id = $9`,
+		item.Name, item.Description, item.SKU, item.Unit, prices, item.TaxCodeID, now, item.ID, item.UserID)
+	if err != nil {
+		return nil, translateCatalogItemWriteError(err)
+	}
+
+	item.UpdatedAt = now
+	return item, nil
+}
+
+func (r *postgresCatalogItemRepository) Delete(ctx context.Context, id string, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM catalog_items WHERE id = $1 AND user_id = $2`, id, userID)
+	return err
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// LockClient locks the client's row for the rest of the transaction so
+// concurrent writes to its credit ledger are serialised, reporting whether the
+// client exists.
+func (r *postgresInvoiceRepository) LockClient(ctx context.Context, clientID string, userID string) (bool, error) {
+	var id string
+	err := r.q.QueryRowContext(ctx,
+		`SELECT id FROM clients WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		clientID, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *postgresInvoiceRepository) ClientCreditBalance(ctx context.Context, clientID string, currency string) (money.Decimal, error) {
+	var balance money.Decimal
+	err := r.q.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM client_credits WHERE client_id = $1 AND currency = $2`,
+		clientID, currency).Scan(&balance)
+	return balance, err
+}
+
+// ClientCreditBalances returns the client's credit in each currency that
+// still has a balance.
+func (r *postgresInvoiceRepository) ClientCreditBalances(ctx context.Context, clientID string) ([]models.ClientCreditBalance, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT currency, SUM(amount) FROM client_credits WHERE client_id = $1
+		 GROUP BY currency HAVING SUM(amount) <> 0 ORDER BY currency`,
+		clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []models.ClientCreditBalance
+	for rows.Next() {
+		var balance models.ClientCreditBalance
+		if err := rows.Scan(&balance.Currency, &balance.Amount); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+func (r *postgresInvoiceRepository) ListClientCredits(ctx context.Context, clientID string, userID string) ([]models.ClientCredit, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, user_id, client_id, type, amount, currency, invoice_id, payment_id, credit_note_id,
+		 payment_method, notes, entry_date, created_at
+		 FROM client_credits WHERE client_id = $1 AND user_id = $2
+		 ORDER BY entry_date DESC, created_at DESC`,
+		clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credits []models.ClientCredit
+	for rows.Next() {
+		var credit models.ClientCredit
+		var invoiceID, paymentID, creditNoteID, paymentMethod, notes sql.NullString
+		if err := rows.Scan(&credit.ID, &credit.UserID, &credit.ClientID, &credit.Type, &credit.Amount,
+			&credit.Currency, &invoiceID, &paymentID, &creditNoteID, &paymentMethod, &notes,
+			&credit.EntryDate, &credit.CreatedAt); err != nil {
+			return nil, err
+		}
+		if invoiceID.Valid {
+			credit.InvoiceID = &invoiceID.String
+		}
+		if paymentID.Valid {
+			credit.PaymentID = &paymentID.String
+		}
+		if creditNoteID.Valid {
+			credit.CreditNoteID = &creditNoteID.String
+		}
+		if paymentMethod.Valid {
+			credit.PaymentMethod = &paymentMethod.String
+		}
+		if notes.Valid {
+			credit.Notes = &notes.String
+		}
+		credits = append(credits, credit)
+	}
+
+	return credits, rows.Err()
+}
+
+func (r *postgresInvoiceRepository) CreateClientCredit(ctx context.Context, credit *models.ClientCredit) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	_, err := r.q.ExecContext(ctx,
+		`INSERT INTO client_credits (id, user_id, client_id, type, amount, currency, invoice_id, payment_id,
+		 credit_note_id, payment_method, notes, entry_date, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		id, credit.UserID, credit.ClientID, credit.Type, credit.Amount, credit.Currency, credit.InvoiceID,
+		credit.PaymentID, credit.CreditNoteID, credit.PaymentMethod, credit.Notes, credit.EntryDate, now)
+	if err != nil {
+		return err
+	}
+
+	credit.ID = id
+	credit.CreatedAt = now
+	return nil
+}
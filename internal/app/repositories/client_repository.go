@@ -3,11 +3,13 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
 )
 
 type ClientRepository interface {
@@ -26,10 +28,74 @@ func NewClientRepository(db *sql.DB) ClientRepository {
 	return &postgresClientRepository{db: db}
 }
 
+const clientColumns = `id, user_id, name, email, company, phone, address, tax_id, currency, payment_terms,
+	default_tax_rate IS NOT NULL, COALESCE(default_tax_rate, 0), default_tax_code_id, language, cc_emails,
+	notes_footer, created_at, updated_at`
+
+func scanClient(row rowScanner) (*models.Client, error) {
+	var c models.Client
+	var email, company, phone, address, taxID, paymentTerms, taxCodeID, language, notesFooter sql.NullString
+	var hasTaxRate bool
+	var taxRate money.Decimal
+	var ccEmails []byte
+
+	if err := row.Scan(&c.ID, &c.UserID, &c.Name, &email, &company, &phone, &address, &taxID, &c.Currency,
+		&paymentTerms, &hasTaxRate, &taxRate, &taxCodeID, &language, &ccEmails, &notesFooter,
+		&c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ccEmails, &c.CCEmails); err != nil {
+		return nil, err
+	}
+	if c.CCEmails == nil {
+		c.CCEmails = []string{}
+	}
+
+	if email.Valid {
+		c.Email = &email.String
+	}
+	if company.Valid {
+		c.Company = &company.String
+	}
+	if phone.Valid {
+		c.Phone = &phone.String
+	}
+	if address.Valid {
+		c.Address = &address.String
+	}
+	if taxID.Valid {
+		c.TaxID = &taxID.String
+	}
+	if paymentTerms.Valid {
+		c.PaymentTerms = models.PaymentTerms(paymentTerms.String)
+	}
+	if hasTaxRate {
+		c.DefaultTaxRate = &taxRate
+	}
+	if taxCodeID.Valid {
+		c.DefaultTaxCodeID = &taxCodeID.String
+	}
+	if language.Valid {
+		c.Language = &language.String
+	}
+	if notesFooter.Valid {
+		c.NotesFooter = &notesFooter.String
+	}
+
+	return &c, nil
+}
+
+// paymentTermsArg stores unset payment terms as NULL.
+func paymentTermsArg(terms models.PaymentTerms) interface{} {
+	if terms == "" {
+		return nil
+	}
+	return terms
+}
+
 func (r *postgresClientRepository) List(ctx context.Context, userID string) ([]models.Client, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, user_id, name, email, company, phone, address, tax_id, currency, created_at, updated_at
-		 FROM clients WHERE user_id = $1 ORDER BY created_at DESC`,
+		`SELECT `+clientColumns+` FROM clients WHERE user_id = $1 ORDER BY created_at DESC`,
 		userID)
 	if err != nil {
 		return nil, err
@@ -38,41 +104,20 @@ func (r *postgresClientRepository) List(ctx context.Context, userID string) ([]m
 
 	var clients []models.Client
 	for rows.Next() {
-		var c models.Client
-		var email, company, phone, address, taxID sql.NullString
-		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &email, &company, &phone, &address, &taxID, &c.Currency, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		c, err := scanClient(rows)
+		if err != nil {
 			return nil, err
 		}
-		if email.Valid {
-			c.Email = &email.String
-		}
-		if company.Valid {
-			c.Company = &company.String
-		}
-		if phone.Valid {
-			c.Phone = &phone.String
-		}
-		if address.Valid {
-			c.Address = &address.String
-		}
-		if taxID.Valid {
-			c.TaxID = &taxID.String
-		}
-		clients = append(clients, c)
+		clients = append(clients, *c)
 	}
 
 	return clients, rows.Err()
 }
 
 func (r *postgresClientRepository) GetByID(ctx context.Context, id string, userID string) (*models.Client, error) {
-	var c models.Client
-	var email, company, phone, address, taxID sql.NullString
-
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, user_id, name, email, company, phone, address, tax_id, currency, created_at, updated_at
-		 FROM clients WHERE id = $1 AND user_id = $2`,
-		id, userID).Scan(&c.ID, &c.UserID, &c.Name, &email, &company, &phone, &address, &taxID, &c.Currency, &c.CreatedAt, &c.UpdatedAt)
-
+	c, err := scanClient(r.db.QueryRowContext(ctx,
+		`SELECT `+clientColumns+` FROM clients WHERE id = $1 AND user_id = $2`,
+		id, userID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -80,33 +125,25 @@ func (r *postgresClientRepository) GetByID(ctx context.Context, id string, userI
 		return nil, err
 	}
 
-	if email.Valid {
-		c.Email = &email.String
-	}
-	if company.Valid {
-		c.Company = &company.String
-	}
-	if phone.Valid {
-		c.Phone = &phone.String
-	}
-	if address.Valid {
-		c.Address = &address.String
-	}
-	if taxID.Valid {
-		c.TaxID = &taxID.String
-	}
-
-	return &c, nil
+	return c, nil
 }
 
 func (r *postgresClientRepository) Create(ctx context.Context, client *models.Client) (*models.Client, error) {
 	id := uuid.NewString()
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO clients (id, user_id, name, email, company, phone, address, tax_id, currency, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`,
-		id, client.UserID, client.Name, client.Email, client.Company, client.Phone, client.Address, client.TaxID, client.Currency, now)
+	ccEmails, err := marshalJSONList(client.CCEmails)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO clients (id, user_id, name, email, company, phone, address, tax_id, currency, payment_terms,
+		 default_tax_rate, default_tax_code_id, language, cc_emails, notes_footer, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)`,
+		id, client.UserID, client.Name, client.Email, client.Company, client.Phone, client.Address, client.TaxID,
+		client.Currency, paymentTermsArg(client.PaymentTerms), client.DefaultTaxRate, client.DefaultTaxCodeID,
+		client.Language, ccEmails, client.NotesFooter, now)
 	if err != nil {
 		return nil, err
 	}
@@ -120,10 +157,19 @@ func (r *postgresClientRepository) Create(ctx context.Context, client *models.Cl
 func (r *postgresClientRepository) Update(ctx context.Context, client *models.Client) (*models.Client, error) {
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE clients SET name = $1, email = $2, company = $3, phone = $4, address = $5, tax_id = $6, currency = $7, updated_at = $8
-		 WHERE id = $9 AND user_id = $10`,
-		client.Name, client.Email, client.Company, client.Phone, client.Address, client.TaxID, client.Currency, now, client.ID, client.UserID)
+	ccEmails, err := marshalJSONList(client.CCEmails)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE clients SET name = $1, email = $2, company = $3, phone = $4, address = $5, tax_id = $6, currency = $7,
+		 payment_terms = $8, default_tax_rate = $9, default_tax_code_id = $10, language = $11, cc_emails = $12,
+		 notes_footer = $13, updated_at = $14
+		 WHERE id = $15 AND user_id = $16`,
+		client.Name, client.Email, client.Company, client.Phone, client.Address, client.TaxID, client.Currency,
+		paymentTermsArg(client.PaymentTerms), client.DefaultTaxRate, client.DefaultTaxCodeID, client.Language,
+		ccEmails, client.NotesFooter, now, client.ID, client.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -136,4 +182,3 @@ func (r *postgresClientRepository) Delete(ctx context.Context, id string, userID
 	_, err := r.db.ExecContext(ctx, `DELETE FROM clients WHERE id = $1 AND user_id = $2`, id, userID)
 	return err
 }
-
@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// Credit notes are stored by the invoice repository so they can be written in
+// the same transaction as the invoice they credit and its refund payment.
+
+type CreditNoteFilters struct {
+	InvoiceID *string
+	FromDate  *time.Time
+	ToDate    *time.Time
+}
+
+const creditNoteColumns = `id, user_id, invoice_id, client_id, credit_note_number, issue_date, currency, reason,
+	subtotal, discount_amount, tax_amount, tax_breakdown, total, created_at, updated_at,
+	(SELECT COALESCE(-SUM(p.amount), 0) FROM payments p WHERE p.credit_note_id = credit_notes.id) AS refunded_amount`
+
+func scanCreditNote(row rowScanner) (*models.CreditNote, error) {
+	var cn models.CreditNote
+	var reason sql.NullString
+	var taxBreakdown []byte
+
+	err := row.Scan(&cn.ID, &cn.UserID, &cn.InvoiceID, &cn.ClientID, &cn.CreditNoteNumber, &cn.IssueDate,
+		&cn.Currency, &reason, &cn.Subtotal, &cn.DiscountAmount, &cn.TaxAmount, &taxBreakdown, &cn.Total,
+		&cn.CreatedAt, &cn.UpdatedAt, &cn.RefundedAmount)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(taxBreakdown, &cn.TaxBreakdown); err != nil {
+		return nil, err
+	}
+	if reason.Valid {
+		cn.Reason = &reason.String
+	}
+	return &cn, nil
+}
+
+func (r *postgresInvoiceRepository) ListCreditNotes(ctx context.Context, userID string, filters CreditNoteFilters) ([]models.CreditNote, error) {
+	query := `SELECT ` + creditNoteColumns + ` FROM credit_notes WHERE user_id = $1`
+	args := []interface{}{userID}
+	argPos := 2
+
+	if filters.InvoiceID != nil {
+		query += ` AND invoice_id = $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.InvoiceID)
+		argPos++
+	}
+	if filters.FromDate != nil {
+		query += ` AND issue_date >= $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.FromDate)
+		argPos++
+	}
+	if filters.ToDate != nil {
+		query += ` AND issue_date <= $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.ToDate)
+		argPos++
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var creditNotes []models.CreditNote
+	for rows.Next() {
+		cn, err := scanCreditNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		creditNotes = append(creditNotes, *cn)
+	}
+
+	return creditNotes, rows.Err()
+}
+
+func (r *postgresInvoiceRepository) GetCreditNote(ctx context.Context, id string, userID string) (*models.CreditNote, error) {
+	cn, err := scanCreditNote(r.q.QueryRowContext(ctx,
+		`SELECT `+creditNoteColumns+` FROM credit_notes WHERE id = $1 AND user_id = $2`,
+		id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cn, nil
+}
+
+// CreateCreditNote inserts the credit note header, allocating its number from
+// the workspace's credit note sequence when CreditNoteNumber is empty.
+func (r *postgresInvoiceRepository) CreateCreditNote(ctx context.Context, creditNote *models.CreditNote) error {
+	return r.inTx(ctx, func(txRepo *postgresInvoiceRepository) error {
+		if creditNote.CreditNoteNumber == "" {
+			number, err := allocateCreditNoteNumber(ctx, txRepo.tx, creditNote.UserID, creditNote.IssueDate)
+			if err != nil {
+				return fmt.Errorf("allocate credit note number: %w", err)
+			}
+			creditNote.CreditNoteNumber = number
+		}
+
+		id := uuid.NewString()
+		now := time.Now().UTC()
+
+		taxBreakdown, err := marshalJSONList(creditNote.TaxBreakdown)
+		if err != nil {
+			return err
+		}
+
+		_, err = txRepo.q.ExecContext(ctx,
+			`INSERT INTO credit_notes (id, user_id, invoice_id, client_id, credit_note_number, issue_date, currency,
+			 reason, subtotal, discount_amount, tax_amount, tax_breakdown, total, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)`,
+			id, creditNote.UserID, creditNote.InvoiceID, creditNote.ClientID, creditNote.CreditNoteNumber,
+			creditNote.IssueDate, creditNote.Currency, creditNote.Reason, creditNote.Subtotal,
+			creditNote.DiscountAmount, creditNote.TaxAmount, taxBreakdown, creditNote.Total, now)
+		if err != nil {
+			return err
+		}
+
+		creditNote.ID = id
+		creditNote.CreatedAt = now
+		creditNote.UpdatedAt = now
+		return nil
+	})
+}
+
+func (r *postgresInvoiceRepository) GetCreditNoteItems(ctx context.Context, creditNoteID string) ([]models.CreditNoteItem, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, credit_note_id, invoice_item_id, description, quantity, unit_price, amount, taxes, created_at
+		 FROM credit_note_items WHERE credit_note_id = $1 ORDER BY created_at, id`,
+		creditNoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.CreditNoteItem
+	for rows.Next() {
+		var item models.CreditNoteItem
+		var invoiceItemID sql.NullString
+		var taxes []byte
+		if err := rows.Scan(&item.ID, &item.CreditNoteID, &invoiceItemID, &item.Description, &item.Quantity,
+			&item.UnitPrice, &item.Amount, &taxes, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		if invoiceItemID.Valid {
+			item.InvoiceItemID = &invoiceItemID.String
+		}
+		if err := json.Unmarshal(taxes, &item.Taxes); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *postgresInvoiceRepository) CreateCreditNoteItem(ctx context.Context, item *models.CreditNoteItem) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	taxes, err := marshalJSONList(item.Taxes)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.q.ExecContext(ctx,
+		`INSERT INTO credit_note_items (id, credit_note_id, invoice_item_id, description, quantity, unit_price, amount,
+		 taxes, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		id, item.CreditNoteID, item.InvoiceItemID, item.Description, item.Quantity, item.UnitPrice, item.Amount,
+		taxes, now)
+	if err != nil {
+		return err
+	}
+
+	item.ID = id
+	item.CreatedAt = now
+	return nil
+}
+
+// CreditedQuantities returns, per invoice item, the quantity already credited
+// by the invoice's credit notes.
+func (r *postgresInvoiceRepository) CreditedQuantities(ctx context.Context, invoiceID string) (map[string]money.Decimal, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT ci.invoice_item_id, SUM(ci.quantity)
+		 FROM credit_note_items ci JOIN credit_notes c ON c.id = ci.credit_note_id
+		 WHERE c.invoice_id = $1 AND ci.invoice_item_id IS NOT NULL
+		 GROUP BY ci.invoice_item_id`,
+		invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credited := make(map[string]money.Decimal)
+	for rows.Next() {
+		var itemID string
+		var quantity money.Decimal
+		if err := rows.Scan(&itemID, &quantity); err != nil {
+			return nil, err
+		}
+		credited[itemID] = quantity
+	}
+
+	return credited, rows.Err()
+}
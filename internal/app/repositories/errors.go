@@ -0,0 +1,4 @@
+package repositories
+
+// uniqueViolationCode is the Postgres SQLSTATE for unique_violation.
+const uniqueViolationCode = "23505"
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+type ExchangeRateRepository interface {
+	List(ctx context.Context, userID string, filters ExchangeRateFilters) ([]models.ExchangeRate, error)
+	// Save stores the rates, replacing any already stored for the same
+	// currencies and dates.
+	Save(ctx context.Context, rates []models.ExchangeRate) error
+	Delete(ctx context.Context, id string, userID string) (bool, error)
+}
+
+type ExchangeRateFilters struct {
+	BaseCurrency string
+	Currency     *string
+	FromDate     *time.Time
+	ToDate       *time.Time
+}
+
+type postgresExchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) ExchangeRateRepository {
+	return &postgresExchangeRateRepository{db: db}
+}
+
+const exchangeRateColumns = `id, user_id, currency, base_currency, rate_date, rate, source, created_at, updated_at`
+
+func scanExchangeRate(row rowScanner) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	if err := row.Scan(&rate.ID, &rate.UserID, &rate.Currency, &rate.BaseCurrency, &rate.RateDate, &rate.Rate,
+		&rate.Source, &rate.CreatedAt, &rate.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *postgresExchangeRateRepository) List(ctx context.Context, userID string, filters ExchangeRateFilters) ([]models.ExchangeRate, error) {
+	query := `SELECT ` + exchangeRateColumns + ` FROM exchange_rates WHERE user_id = $1 AND base_currency = $2`
+	args := []interface{}{userID, filters.BaseCurrency}
+	argPos := 3
+
+	if filters.Currency != nil {
+		query += ` AND currency = $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.Currency)
+		argPos++
+	}
+	if filters.FromDate != nil {
+		query += ` AND rate_date >= $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.FromDate)
+		argPos++
+	}
+	if filters.ToDate != nil {
+		query += ` AND rate_date <= $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.ToDate)
+		argPos++
+	}
+
+	query += ` ORDER BY rate_date DESC, currency`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.ExchangeRate
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func (r *postgresExchangeRateRepository) Save(ctx context.Context, rates []models.ExchangeRate) error {
+	now := time.Now().UTC()
+	return runInTx(ctx, r.db, func(tx *sql.Tx) error {
+		for i := range rates {
+			rate := &rates[i]
+			err := tx.QueryRowContext(ctx,
+				`INSERT INTO exchange_rates (id, user_id, currency, base_currency, rate_date, rate, source, created_at, updated_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+				 ON CONFLICT (user_id, base_currency, currency, rate_date) DO UPDATE SET rate = EXCLUDED.rate,
+				 source = EXCLUDED.source, updated_at = EXCLUDED.updated_at
+				 RETURNING id, created_at`,
+				uuid.NewString(), rate.UserID, rate.Currency, rate.BaseCurrency, rate.RateDate, rate.Rate, rate.Source,
+				now).Scan(&rate.ID, &rate.CreatedAt)
+			if err != nil {
+				return err
+			}
+			rate.UpdatedAt = now
+		}
+		return nil
+	})
+}
+
+// Delete removes the rate and reports whether it existed.
+func (r *postgresExchangeRateRepository) Delete(ctx context.Context, id string, userID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM exchange_rates WHERE id = $1 AND user_id = $2`,
+		id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+type InvoiceAttachmentRepository interface {
+	ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceAttachment, error)
+	GetByID(ctx context.Context, id string, invoiceID string, userID string) (*models.InvoiceAttachment, error)
+	Create(ctx context.Context, attachment *models.InvoiceAttachment) error
+	Delete(ctx context.Context, id string, invoiceID string, userID string) (bool, error)
+}
+
+type postgresInvoiceAttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceAttachmentRepository(db *sql.DB) InvoiceAttachmentRepository {
+	return &postgresInvoiceAttachmentRepository{db: db}
+}
+
+const attachmentColumns = `id, user_id, invoice_id, file_name, content_type, size_bytes, storage_key, created_at`
+
+func scanAttachment(row rowScanner) (*models.InvoiceAttachment, error) {
+	var attachment models.InvoiceAttachment
+	if err := row.Scan(&attachment.ID, &attachment.UserID, &attachment.InvoiceID, &attachment.FileName,
+		&attachment.ContentType, &attachment.SizeBytes, &attachment.StorageKey, &attachment.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *postgresInvoiceAttachmentRepository) ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceAttachment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+attachmentColumns+` FROM invoice_attachments
+		 WHERE invoice_id = $1 AND user_id = $2 ORDER BY created_at, id`,
+		invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.InvoiceAttachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (r *postgresInvoiceAttachmentRepository) GetByID(ctx context.Context, id string, invoiceID string, userID string) (*models.InvoiceAttachment, error) {
+	attachment, err := scanAttachment(r.db.QueryRowContext(ctx,
+		`SELECT `+attachmentColumns+` FROM invoice_attachments
+		 WHERE id = $1 AND invoice_id = $2 AND user_id = $3`,
+		id, invoiceID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (r *postgresInvoiceAttachmentRepository) Create(ctx context.Context, attachment *models.InvoiceAttachment) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO invoice_attachments (id, user_id, invoice_id, file_name, content_type, size_bytes, storage_key, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		id, attachment.UserID, attachment.InvoiceID, attachment.FileName, attachment.ContentType,
+		attachment.SizeBytes, attachment.StorageKey, now)
+	if err != nil {
+		return err
+	}
+
+	attachment.ID = id
+	attachment.CreatedAt = now
+	return nil
+}
+
+// Delete removes the attachment record and reports whether it existed. The
+// stored file is left to the caller.
+func (r *postgresInvoiceAttachmentRepository) Delete(ctx context.Context, id string, invoiceID string, userID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM invoice_attachments WHERE id = $1 AND invoice_id = $2 AND user_id = $3`,
+		id, invoiceID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
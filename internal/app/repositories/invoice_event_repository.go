@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+func (r *postgresInvoiceRepository) CreateEvent(ctx context.Context, event *models.InvoiceEvent) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	_, err := r.q.ExecContext(ctx,
+		`INSERT INTO invoice_events (id, user_id, invoice_id, invoice_number, action, from_status, to_status, reason,
+		 created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		id, event.UserID, event.InvoiceID, event.InvoiceNumber, event.Action, event.FromStatus, event.ToStatus,
+		event.Reason, now)
+	if err != nil {
+		return err
+	}
+
+	event.ID = id
+	event.CreatedAt = now
+	return nil
+}
+
+func (r *postgresInvoiceRepository) ListEvents(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceEvent, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, user_id, invoice_id, invoice_number, action, from_status, to_status, reason, created_at
+		 FROM invoice_events WHERE invoice_id = $1 AND user_id = $2 ORDER BY created_at, id`,
+		invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.InvoiceEvent
+	for rows.Next() {
+		var event models.InvoiceEvent
+		var toStatus, reason sql.NullString
+		if err := rows.Scan(&event.ID, &event.UserID, &event.InvoiceID, &event.InvoiceNumber, &event.Action,
+			&event.FromStatus, &toStatus, &reason, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if toStatus.Valid {
+			status := models.InvoiceStatus(toStatus.String)
+			event.ToStatus = &status
+		}
+		if reason.Valid {
+			event.Reason = &reason.String
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+func (r *postgresInvoiceRepository) ListLateFees(ctx context.Context, invoiceID string) ([]models.InvoiceLateFee, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, user_id, invoice_id, invoice_item_id, period, amount, charged_at
+		 FROM invoice_late_fees WHERE invoice_id = $1 ORDER BY period`,
+		invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fees []models.InvoiceLateFee
+	for rows.Next() {
+		var fee models.InvoiceLateFee
+		var itemID sql.NullString
+		if err := rows.Scan(&fee.ID, &fee.UserID, &fee.InvoiceID, &itemID, &fee.Period, &fee.Amount,
+			&fee.ChargedAt); err != nil {
+			return nil, err
+		}
+		if itemID.Valid {
+			fee.InvoiceItemID = &itemID.String
+		}
+		fees = append(fees, fee)
+	}
+
+	return fees, rows.Err()
+}
+
+// CreateLateFee records the fee unless its period was already charged on the
+// invoice, reporting whether it was recorded.
+func (r *postgresInvoiceRepository) CreateLateFee(ctx context.Context, fee *models.InvoiceLateFee) (bool, error) {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	result, err := r.q.ExecContext(ctx,
+		`INSERT INTO invoice_late_fees (id, user_id, invoice_id, invoice_item_id, period, amount, charged_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (invoice_id, period) DO NOTHING`,
+		id, fee.UserID, fee.InvoiceID, fee.InvoiceItemID, fee.Period, fee.Amount, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	fee.ID = id
+	fee.ChargedAt = now
+	return true, nil
+}
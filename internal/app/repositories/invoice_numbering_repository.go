@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/numbering"
+)
+
+// DefaultInvoiceNumberFormat, DefaultCreditNoteNumberFormat and
+// DefaultQuoteNumberFormat are used by workspaces that never configured
+// numbering.
+const (
+	DefaultInvoiceNumberFormat    = "INV-{SEQ:4}"
+	DefaultCreditNoteNumberFormat = "CN-{SEQ:4}"
+	DefaultQuoteNumberFormat      = "QUO-{SEQ:4}"
+)
+
+// maxNumberProbe bounds how many already-taken numbers allocation skips over,
+// e.g. numbers a user entered by hand before enabling automatic numbering.
+const maxNumberProbe = 1000
+
+// numberSequence describes where one kind of document keeps its format,
+// counter and numbers. Invoices, credit notes and quotes are numbered
+// independently.
+type numberSequence struct {
+	kind          string
+	formatColumn  string
+	defaultFormat string
+	counterTable  string
+	documentTable string
+	numberColumn  string
+}
+
+var (
+	invoiceSequence = numberSequence{
+		kind:          "invoice",
+		formatColumn:  "format",
+		defaultFormat: DefaultInvoiceNumberFormat,
+		counterTable:  "invoice_number_counters",
+		documentTable: "invoices",
+		numberColumn:  "invoice_number",
+	}
+	creditNoteSequence = numberSequence{
+		kind:          "credit note",
+		formatColumn:  "credit_note_format",
+		defaultFormat: DefaultCreditNoteNumberFormat,
+		counterTable:  "credit_note_number_counters",
+		documentTable: "credit_notes",
+		numberColumn:  "credit_note_number",
+	}
+	quoteSequence = numberSequence{
+		kind:          "quote",
+		formatColumn:  "quote_format",
+		defaultFormat: DefaultQuoteNumberFormat,
+		counterTable:  "quote_number_counters",
+		documentTable: "quotes",
+		numberColumn:  "quote_number",
+	}
+)
+
+type InvoiceNumberingRepository interface {
+	Get(ctx context.Context, userID string, asOf time.Time) (*models.InvoiceNumbering, error)
+	Save(ctx context.Context, settings *models.InvoiceNumbering, asOf time.Time) error
+}
+
+type postgresInvoiceNumberingRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceNumberingRepository(db *sql.DB) InvoiceNumberingRepository {
+	return &postgresInvoiceNumberingRepository{db: db}
+}
+
+// Get returns the workspace's settings, falling back to the defaults, with
+// NextSequence taken from the counter for the period containing asOf.
+func (r *postgresInvoiceNumberingRepository) Get(ctx context.Context, userID string, asOf time.Time) (*models.InvoiceNumbering, error) {
+	settings := &models.InvoiceNumbering{
+		UserID:           userID,
+		Format:           DefaultInvoiceNumberFormat,
+		CreditNoteFormat: DefaultCreditNoteNumberFormat,
+		QuoteFormat:      DefaultQuoteNumberFormat,
+	}
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT format, credit_note_format, quote_format, yearly_reset, updated_at FROM invoice_numbering
+		 WHERE user_id = $1`,
+		userID).Scan(&settings.Format, &settings.CreditNoteFormat, &settings.QuoteFormat, &settings.YearlyReset,
+		&settings.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	period := numberingPeriod(settings.YearlyReset, asOf)
+	if settings.NextSequence, err = r.nextValue(ctx, invoiceSequence, userID, period); err != nil {
+		return nil, err
+	}
+	if settings.NextCreditNoteSequence, err = r.nextValue(ctx, creditNoteSequence, userID, period); err != nil {
+		return nil, err
+	}
+	if settings.NextQuoteSequence, err = r.nextValue(ctx, quoteSequence, userID, period); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *postgresInvoiceNumberingRepository) nextValue(ctx context.Context, seq numberSequence, userID string, period int) (int64, error) {
+	next := int64(1)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT next_value FROM `+seq.counterTable+` WHERE user_id = $1 AND period_year = $2`,
+		userID, period).Scan(&next)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	return next, nil
+}
+
+// Save stores the settings and sets the invoice, credit note and quote
+// counters for the period containing asOf to settings.NextSequence,
+// settings.NextCreditNoteSequence and settings.NextQuoteSequence.
+func (r *postgresInvoiceNumberingRepository) Save(ctx context.Context, settings *models.InvoiceNumbering, asOf time.Time) error {
+	now := time.Now().UTC()
+
+	err := runInTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO invoice_numbering (user_id, format, credit_note_format, quote_format, yearly_reset,
+			 created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $6)
+			 ON CONFLICT (user_id) DO UPDATE SET format = EXCLUDED.format,
+			 credit_note_format = EXCLUDED.credit_note_format, quote_format = EXCLUDED.quote_format,
+			 yearly_reset = EXCLUDED.yearly_reset, updated_at = EXCLUDED.updated_at`,
+			settings.UserID, settings.Format, settings.CreditNoteFormat, settings.QuoteFormat, settings.YearlyReset,
+			now)
+		if err != nil {
+			return err
+		}
+
+		period := numberingPeriod(settings.YearlyReset, asOf)
+		if err := saveNextValue(ctx, tx, invoiceSequence, settings.UserID, period, settings.NextSequence); err != nil {
+			return err
+		}
+		if err := saveNextValue(ctx, tx, creditNoteSequence, settings.UserID, period, settings.NextCreditNoteSequence); err != nil {
+			return err
+		}
+		return saveNextValue(ctx, tx, quoteSequence, settings.UserID, period, settings.NextQuoteSequence)
+	})
+	if err != nil {
+		return err
+	}
+
+	settings.UpdatedAt = now
+	return nil
+}
+
+func saveNextValue(ctx context.Context, tx *sql.Tx, seq numberSequence, userID string, period int, next int64) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO `+seq.counterTable+` (user_id, period_year, next_value) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, period_year) DO UPDATE SET next_value = EXCLUDED.next_value`,
+		userID, period, next)
+	return err
+}
+
+func numberingPeriod(yearlyReset bool, date time.Time) int {
+	if yearlyReset {
+		return date.Year()
+	}
+	return 0
+}
+
+// allocateInvoiceNumber reserves the next invoice number for userID inside
+// tx. See allocateNumber.
+func allocateInvoiceNumber(ctx context.Context, tx *sql.Tx, userID string, issueDate time.Time) (string, error) {
+	return allocateNumber(ctx, tx, invoiceSequence, userID, issueDate)
+}
+
+// releaseInvoiceNumber hands number back to userID's invoice sequence inside
+// tx. See releaseNumber.
+func releaseInvoiceNumber(ctx context.Context, tx *sql.Tx, userID string, number string, issueDate time.Time) (bool, error) {
+	return releaseNumber(ctx, tx, invoiceSequence, userID, number, issueDate)
+}
+
+// allocateCreditNoteNumber reserves the next credit note number for userID
+// inside tx.
+func allocateCreditNoteNumber(ctx context.Context, tx *sql.Tx, userID string, issueDate time.Time) (string, error) {
+	return allocateNumber(ctx, tx, creditNoteSequence, userID, issueDate)
+}
+
+// allocateQuoteNumber reserves the next quote number for userID inside tx.
+func allocateQuoteNumber(ctx context.Context, tx *sql.Tx, userID string, issueDate time.Time) (string, error) {
+	return allocateNumber(ctx, tx, quoteSequence, userID, issueDate)
+}
+
+// allocateNumber reserves the next number in seq for userID inside tx. The
+// counter row stays locked until tx ends and is only advanced if tx commits,
+// so concurrent allocations queue up and a failed insert leaves no gap.
+func allocateNumber(ctx context.Context, tx *sql.Tx, seq numberSequence, userID string, issueDate time.Time) (string, error) {
+	format := seq.defaultFormat
+	yearlyReset := false
+	err := tx.QueryRowContext(ctx,
+		`SELECT `+seq.formatColumn+`, yearly_reset FROM invoice_numbering WHERE user_id = $1`,
+		userID).Scan(&format, &yearlyReset)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	period := numberingPeriod(yearlyReset, issueDate)
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO `+seq.counterTable+` (user_id, period_year, next_value) VALUES ($1, $2, 1)
+		 ON CONFLICT (user_id, period_year) DO NOTHING`,
+		userID, period); err != nil {
+		return "", err
+	}
+
+	var next int64
+	if err := tx.QueryRowContext(ctx,
+		`SELECT next_value FROM `+seq.counterTable+` WHERE user_id = $1 AND period_year = $2 FOR UPDATE`,
+		userID, period).Scan(&next); err != nil {
+		return "", err
+	}
+
+	for probe := 0; ; probe++ {
+		if probe == maxNumberProbe {
+			return "", fmt.Errorf("no free %s number found after %d attempts", seq.kind, maxNumberProbe)
+		}
+		number := numbering.Format(format, next, issueDate)
+
+		var taken bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM `+seq.documentTable+` WHERE user_id = $1 AND `+seq.numberColumn+` = $2)`,
+			userID, number).Scan(&taken); err != nil {
+			return "", err
+		}
+		if !taken {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE `+seq.counterTable+` SET next_value = $1 WHERE user_id = $2 AND period_year = $3`,
+				next+1, userID, period); err != nil {
+				return "", err
+			}
+			return number, nil
+		}
+		next++
+	}
+}
+
+// releaseNumber steps seq's counter back when number is the last one it
+// allocated, so the next allocation hands it out again and the sequence keeps
+// no gap. Any other number reports false and the counter is left alone: later
+// numbers have already followed it.
+func releaseNumber(ctx context.Context, tx *sql.Tx, seq numberSequence, userID string, number string, issueDate time.Time) (bool, error) {
+	format := seq.defaultFormat
+	yearlyReset := false
+	err := tx.QueryRowContext(ctx,
+		`SELECT `+seq.formatColumn+`, yearly_reset FROM invoice_numbering WHERE user_id = $1`,
+		userID).Scan(&format, &yearlyReset)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	period := numberingPeriod(yearlyReset, issueDate)
+	var next int64
+	err = tx.QueryRowContext(ctx,
+		`SELECT next_value FROM `+seq.counterTable+` WHERE user_id = $1 AND period_year = $2 FOR UPDATE`,
+		userID, period).Scan(&next)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if next <= 1 || numbering.Format(format, next-1, issueDate) != number {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE `+seq.counterTable+` SET next_value = $1 WHERE user_id = $2 AND period_year = $3`,
+		next-1, userID, period); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -3,25 +3,90 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
 )
 
 type InvoiceRepository interface {
 	List(ctx context.Context, userID string, filters InvoiceFilters) ([]models.Invoice, error)
 	GetByID(ctx context.Context, id string, userID string) (*models.Invoice, error)
+	GetByIDForUpdate(ctx context.Context, id string, userID string) (*models.Invoice, error)
 	Create(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error)
 	Update(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error)
 	GetItems(ctx context.Context, invoiceID string) ([]models.InvoiceItem, error)
 	CreateItem(ctx context.Context, item *models.InvoiceItem) error
 	UpdateItem(ctx context.Context, item *models.InvoiceItem) error
 	DeleteItem(ctx context.Context, itemID string) error
+	DeleteItems(ctx context.Context, invoiceID string) error
 	GetPayments(ctx context.Context, invoiceID string) ([]models.Payment, error)
 	CreatePayment(ctx context.Context, payment *models.Payment) error
+	MarkSent(ctx context.Context, invoice *models.Invoice, to string, sentAt time.Time) error
+	MarkOverdue(ctx context.Context, fromStatuses []models.InvoiceStatus, asOf time.Time) ([]StatusTransition, error)
+	Void(ctx context.Context, invoice *models.Invoice) error
+	Delete(ctx context.Context, id string, userID string) error
+	ReleaseNumber(ctx context.Context, invoice *models.Invoice) (bool, error)
+	CreateEvent(ctx context.Context, event *models.InvoiceEvent) error
+	ListEvents(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceEvent, error)
+	ListCreditNotes(ctx context.Context, userID string, filters CreditNoteFilters) ([]models.CreditNote, error)
+	GetCreditNote(ctx context.Context, id string, userID string) (*models.CreditNote, error)
+	CreateCreditNote(ctx context.Context, creditNote *models.CreditNote) error
+	GetCreditNoteItems(ctx context.Context, creditNoteID string) ([]models.CreditNoteItem, error)
+	CreateCreditNoteItem(ctx context.Context, item *models.CreditNoteItem) error
+	CreditedQuantities(ctx context.Context, invoiceID string) (map[string]money.Decimal, error)
+	ListLateFees(ctx context.Context, invoiceID string) ([]models.InvoiceLateFee, error)
+	CreateLateFee(ctx context.Context, fee *models.InvoiceLateFee) (bool, error)
+	LockClient(ctx context.Context, clientID string, userID string) (bool, error)
+	ClientCreditBalance(ctx context.Context, clientID string, currency string) (money.Decimal, error)
+	ClientCreditBalances(ctx context.Context, clientID string) ([]models.ClientCreditBalance, error)
+	ListClientCredits(ctx context.Context, clientID string, userID string) ([]models.ClientCredit, error)
+	CreateClientCredit(ctx context.Context, credit *models.ClientCredit) error
+
+	// WithinTx runs fn with a repository bound to a single transaction. The
+	// transaction commits if fn returns nil and rolls back otherwise. Calls
+	// on a repository that is already transactional join that transaction.
+	WithinTx(ctx context.Context, fn func(tx InvoiceRepository) error) error
+}
+
+// StatusTransition describes an invoice whose status was changed by a bulk
+// operation such as the overdue sweep.
+type StatusTransition struct {
+	InvoiceID     string
+	UserID        string
+	InvoiceNumber string
+	From          models.InvoiceStatus
+	To            models.InvoiceStatus
+}
+
+var (
+	ErrInvoiceNumberExists          = errors.New("invoice number already exists")
+	ErrRecurringPeriodAlreadyBilled = errors.New("recurring period already invoiced")
+	ErrQuoteAlreadyInvoiced         = errors.New("quote already converted into an invoice")
+)
+
+// translateInvoiceWriteError maps unique-constraint violations on invoices to
+// sentinel errors callers can branch on.
+func translateInvoiceWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolationCode {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "idx_invoices_recurring_period":
+		return ErrRecurringPeriodAlreadyBilled
+	case "invoices_user_id_invoice_number_key":
+		return ErrInvoiceNumberExists
+	case "idx_invoices_quote_id":
+		return ErrQuoteAlreadyInvoiced
+	}
+	return err
 }
 
 type InvoiceFilters struct {
@@ -31,18 +96,145 @@ type InvoiceFilters struct {
 	ToDate   *time.Time
 }
 
+// invoiceColumns selects an invoice row together with the amount received so
+// far and the amount credited, which are always derived from the payments and
+// credit_notes tables rather than stored. Refunds are negative payments.
+const invoiceColumns = `id, user_id, client_id, invoice_number, status, issue_date, due_date, currency,
+	subtotal, line_discount_amount, discount_type, discount_value, discount_timing, discount_amount,
+	tax_rate, tax_amount, tax_breakdown, total, notes, payment_link, sent_at, sent_to, recurring_invoice_id,
+	quote_id, voided_at, void_reason, language, created_at, updated_at,
+	(SELECT COALESCE(SUM(p.amount), 0) FROM payments p WHERE p.invoice_id = invoices.id) AS amount_paid,
+	(SELECT COALESCE(SUM(c.total), 0) FROM credit_notes c WHERE c.invoice_id = invoices.id) AS credited_amount`
+
+// marshalJSONList encodes list for a NOT NULL JSONB column, storing an empty
+// array rather than null.
+func marshalJSONList[T any](list []T) ([]byte, error) {
+	if list == nil {
+		list = []T{}
+	}
+	return json.Marshal(list)
+}
+
+// scannedCatalogFields converts the nullable catalog columns of an invoice or
+// quote item.
+func scannedCatalogFields(catalogItemID, unit, sku sql.NullString) (*string, *models.CatalogUnit, *string) {
+	var id, code *string
+	var catalogUnit *models.CatalogUnit
+	if catalogItemID.Valid {
+		id = &catalogItemID.String
+	}
+	if unit.Valid {
+		u := models.CatalogUnit(unit.String)
+		catalogUnit = &u
+	}
+	if sku.Valid {
+		code = &sku.String
+	}
+	return id, catalogUnit, code
+}
+
+// discountArgs splits d into its discount_type and discount_value columns.
+func discountArgs(d *models.Discount) (interface{}, interface{}) {
+	if d == nil {
+		return nil, nil
+	}
+	return d.Type, d.Value
+}
+
+// scannedDiscount rebuilds a discount from its nullable columns.
+func scannedDiscount(discountType sql.NullString, value money.Decimal) *models.Discount {
+	if !discountType.Valid {
+		return nil
+	}
+	return &models.Discount{Type: models.DiscountType(discountType.String), Value: value}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInvoice(row rowScanner) (*models.Invoice, error) {
+	var inv models.Invoice
+	var dueDate, sentAt, voidedAt sql.NullTime
+	var notes, paymentLink, sentTo, recurringID, quoteID, voidReason, language, discountType sql.NullString
+	var discountValue money.Decimal
+	var taxBreakdown []byte
+
+	err := row.Scan(&inv.ID, &inv.UserID, &inv.ClientID, &inv.InvoiceNumber, &inv.Status,
+		&inv.IssueDate, &dueDate, &inv.Currency, &inv.Subtotal, &inv.LineDiscountAmount, &discountType,
+		&discountValue, &inv.DiscountTiming, &inv.DiscountAmount, &inv.TaxRate, &inv.TaxAmount, &taxBreakdown,
+		&inv.Total, &notes, &paymentLink, &sentAt, &sentTo, &recurringID, &quoteID, &voidedAt, &voidReason,
+		&language, &inv.CreatedAt, &inv.UpdatedAt, &inv.AmountPaid, &inv.CreditedAmount)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(taxBreakdown, &inv.TaxBreakdown); err != nil {
+		return nil, err
+	}
+	inv.Discount = scannedDiscount(discountType, discountValue)
+	inv.BalanceDue = inv.Total.Sub(inv.AmountPaid).Sub(inv.CreditedAmount)
+
+	if dueDate.Valid {
+		inv.DueDate = &dueDate.Time
+	}
+	if notes.Valid {
+		inv.Notes = &notes.String
+	}
+	if paymentLink.Valid {
+		inv.PaymentLink = &paymentLink.String
+	}
+	if sentAt.Valid {
+		inv.SentAt = &sentAt.Time
+	}
+	if sentTo.Valid {
+		inv.SentTo = &sentTo.String
+	}
+	if recurringID.Valid {
+		inv.RecurringInvoiceID = &recurringID.String
+	}
+	if quoteID.Valid {
+		inv.QuoteID = &quoteID.String
+	}
+	if voidedAt.Valid {
+		inv.VoidedAt = &voidedAt.Time
+	}
+	if voidReason.Valid {
+		inv.VoidReason = &voidReason.String
+	}
+	if language.Valid {
+		inv.Language = &language.String
+	}
+
+	return &inv, nil
+}
+
 type postgresInvoiceRepository struct {
 	db *sql.DB
+	q  dbtx
+	tx *sql.Tx
 }
 
 func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
-	return &postgresInvoiceRepository{db: db}
+	return &postgresInvoiceRepository{db: db, q: db}
+}
+
+func (r *postgresInvoiceRepository) WithinTx(ctx context.Context, fn func(tx InvoiceRepository) error) error {
+	return r.inTx(ctx, func(txRepo *postgresInvoiceRepository) error {
+		return fn(txRepo)
+	})
+}
+
+func (r *postgresInvoiceRepository) inTx(ctx context.Context, fn func(txRepo *postgresInvoiceRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+	return runInTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&postgresInvoiceRepository{db: r.db, q: tx, tx: tx})
+	})
 }
 
 func (r *postgresInvoiceRepository) List(ctx context.Context, userID string, filters InvoiceFilters) ([]models.Invoice, error) {
-	query := `SELECT id, user_id, client_id, invoice_number, status, issue_date, due_date, currency, 
-			  subtotal, tax_rate, tax_amount, total, notes, payment_link, created_at, updated_at
-			  FROM invoices WHERE user_id = $1`
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE user_id = $1`
 	args := []interface{}{userID}
 	argPos := 2
 
@@ -69,7 +261,7 @@ func (r *postgresInvoiceRepository) List(ctx context.Context, userID string, fil
 
 	query += ` ORDER BY created_at DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,45 +269,20 @@ func (r *postgresInvoiceRepository) List(ctx context.Context, userID string, fil
 
 	var invoices []models.Invoice
 	for rows.Next() {
-		var inv models.Invoice
-		var dueDate sql.NullTime
-		var notes, paymentLink sql.NullString
-
-		err := rows.Scan(&inv.ID, &inv.UserID, &inv.ClientID, &inv.InvoiceNumber, &inv.Status,
-			&inv.IssueDate, &dueDate, &inv.Currency, &inv.Subtotal, &inv.TaxRate, &inv.TaxAmount,
-			&inv.Total, &notes, &paymentLink, &inv.CreatedAt, &inv.UpdatedAt)
+		inv, err := scanInvoice(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if dueDate.Valid {
-			inv.DueDate = &dueDate.Time
-		}
-		if notes.Valid {
-			inv.Notes = &notes.String
-		}
-		if paymentLink.Valid {
-			inv.PaymentLink = &paymentLink.String
-		}
-
-		invoices = append(invoices, inv)
+		invoices = append(invoices, *inv)
 	}
 
 	return invoices, rows.Err()
 }
 
 func (r *postgresInvoiceRepository) GetByID(ctx context.Context, id string, userID string) (*models.Invoice, error) {
-	var inv models.Invoice
-	var dueDate sql.NullTime
-	var notes, paymentLink sql.NullString
-
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, user_id, client_id, invoice_number, status, issue_date, due_date, currency,
-		 subtotal, tax_rate, tax_amount, total, notes, payment_link, created_at, updated_at
-		 FROM invoices WHERE id = $1 AND user_id = $2`,
-		id, userID).Scan(&inv.ID, &inv.UserID, &inv.ClientID, &inv.InvoiceNumber, &inv.Status,
-		&inv.IssueDate, &dueDate, &inv.Currency, &inv.Subtotal, &inv.TaxRate, &inv.TaxAmount,
-		&inv.Total, &notes, &paymentLink, &inv.CreatedAt, &inv.UpdatedAt)
+	inv, err := scanInvoice(r.q.QueryRowContext(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 AND user_id = $2`,
+		id, userID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -124,32 +291,97 @@ func (r *postgresInvoiceRepository) GetByID(ctx context.Context, id string, user
 		return nil, err
 	}
 
-	if dueDate.Valid {
-		inv.DueDate = &dueDate.Time
+	return inv, nil
+}
+
+// GetByIDForUpdate is GetByID with a row lock held until the surrounding
+// transaction ends. Call it through WithinTx.
+func (r *postgresInvoiceRepository) GetByIDForUpdate(ctx context.Context, id string, userID string) (*models.Invoice, error) {
+	inv, err := scanInvoice(r.q.QueryRowContext(ctx,
+		`SELECT `+invoiceColumns+` FROM invoices WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	if notes.Valid {
-		inv.Notes = &notes.String
+	if err != nil {
+		return nil, err
 	}
-	if paymentLink.Valid {
-		inv.PaymentLink = &paymentLink.String
+	return inv, nil
+}
+
+// MarkSent records when and to whom invoice was emailed. The status is left
+// alone; callers change it under the invoice's row lock.
+func (r *postgresInvoiceRepository) MarkSent(ctx context.Context, invoice *models.Invoice, to string, sentAt time.Time) error {
+	_, err := r.q.ExecContext(ctx,
+		`UPDATE invoices SET sent_at = $1, sent_to = $2, updated_at = $1
+		 WHERE id = $3 AND user_id = $4`,
+		sentAt, to, invoice.ID, invoice.UserID)
+	if err != nil {
+		return err
 	}
 
-	return &inv, nil
+	invoice.SentAt = &sentAt
+	invoice.SentTo = &to
+	invoice.UpdatedAt = sentAt
+	return nil
 }
 
+// Create inserts invoice. When InvoiceNumber is empty the next number in the
+// workspace's sequence is allocated in the same transaction as the insert.
 func (r *postgresInvoiceRepository) Create(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
+	if invoice.InvoiceNumber != "" {
+		return insertInvoice(ctx, r.q, invoice)
+	}
+
+	var created *models.Invoice
+	err := r.inTx(ctx, func(txRepo *postgresInvoiceRepository) error {
+		numberDate := invoice.IssueDate
+		if numberDate.IsZero() {
+			numberDate = time.Now().UTC()
+		}
+		number, err := allocateInvoiceNumber(ctx, txRepo.tx, invoice.UserID, numberDate)
+		if err != nil {
+			return fmt.Errorf("allocate invoice number: %w", err)
+		}
+
+		invoice.InvoiceNumber = number
+		created, err = insertInvoice(ctx, txRepo.tx, invoice)
+		return err
+	})
+	if err != nil {
+		invoice.InvoiceNumber = ""
+		return nil, err
+	}
+	return created, nil
+}
+
+func insertInvoice(ctx context.Context, exec dbtx, invoice *models.Invoice) (*models.Invoice, error) {
 	id := uuid.NewString()
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
+	taxBreakdown, err := marshalJSONList(invoice.TaxBreakdown)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.DiscountTiming == "" {
+		invoice.DiscountTiming = models.DiscountTimingPreTax
+	}
+	discountType, discountValue := discountArgs(invoice.Discount)
+
+	_, err = exec.ExecContext(ctx,
 		`INSERT INTO invoices (id, user_id, client_id, invoice_number, status, issue_date, due_date, currency,
-		 subtotal, tax_rate, tax_amount, total, notes, payment_link, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15)`,
+		 subtotal, line_discount_amount, discount_type, discount_value, discount_timing, discount_amount,
+		 tax_rate, tax_amount, tax_breakdown, total, notes, payment_link, recurring_invoice_id, recurring_period,
+		 quote_id, language, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+		 $23, $24, $25, $25)`,
 		id, invoice.UserID, invoice.ClientID, invoice.InvoiceNumber, invoice.Status, invoice.IssueDate,
-		invoice.DueDate, invoice.Currency, invoice.Subtotal, invoice.TaxRate, invoice.TaxAmount,
-		invoice.Total, invoice.Notes, invoice.PaymentLink, now)
+		invoice.DueDate, invoice.Currency, invoice.Subtotal, invoice.LineDiscountAmount, discountType, discountValue,
+		invoice.DiscountTiming, invoice.DiscountAmount, invoice.TaxRate, invoice.TaxAmount, taxBreakdown,
+		invoice.Total, invoice.Notes, invoice.PaymentLink, invoice.RecurringInvoiceID, invoice.RecurringPeriod,
+		invoice.QuoteID, invoice.Language, now)
 	if err != nil {
-		return nil, err
+		return nil, translateInvoiceWriteError(err)
 	}
 
 	invoice.ID = id
@@ -161,12 +393,25 @@ func (r *postgresInvoiceRepository) Create(ctx context.Context, invoice *models.
 func (r *postgresInvoiceRepository) Update(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
+	taxBreakdown, err := marshalJSONList(invoice.TaxBreakdown)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.DiscountTiming == "" {
+		invoice.DiscountTiming = models.DiscountTimingPreTax
+	}
+	discountType, discountValue := discountArgs(invoice.Discount)
+
+	_, err = r.q.ExecContext(ctx,
 		`UPDATE invoices SET status = $1, issue_date = $2, due_date = $3, currency = $4,
-		 subtotal = $5, tax_rate = $6, tax_amount = $7, total = $8, notes = $9, payment_link = $10, updated_at = $11
-		 WHERE id = $12 AND user_id = $13`,
+		 subtotal = $5, line_discount_amount = $6, discount_type = $7, discount_value = $8, discount_timing = $9,
+		 discount_amount = $10, tax_rate = $11, tax_amount = $12, tax_breakdown = $13, total = $14, notes = $15,
+		 payment_link = $16, language = $17, updated_at = $18
+		 WHERE id = $19 AND user_id = $20`,
 		invoice.Status, invoice.IssueDate, invoice.DueDate, invoice.Currency, invoice.Subtotal,
-		invoice.TaxRate, invoice.TaxAmount, invoice.Total, invoice.Notes, invoice.PaymentLink, now, invoice.ID, invoice.UserID)
+		invoice.LineDiscountAmount, discountType, discountValue, invoice.DiscountTiming, invoice.DiscountAmount,
+		invoice.TaxRate, invoice.TaxAmount, taxBreakdown, invoice.Total, invoice.Notes, invoice.PaymentLink,
+		invoice.Language, now, invoice.ID, invoice.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -175,10 +420,53 @@ func (r *postgresInvoiceRepository) Update(ctx context.Context, invoice *models.
 	return invoice, nil
 }
 
+// Void records the invoice's void status, time and reason. Nothing else on
+// the invoice is written.
+func (r *postgresInvoiceRepository) Void(ctx context.Context, invoice *models.Invoice) error {
+	now := time.Now().UTC()
+	_, err := r.q.ExecContext(ctx,
+		`UPDATE invoices SET status = $1, voided_at = $2, void_reason = $3, updated_at = $4
+		 WHERE id = $5 AND user_id = $6`,
+		invoice.Status, invoice.VoidedAt, invoice.VoidReason, now, invoice.ID, invoice.UserID)
+	if err != nil {
+		return err
+	}
+
+	invoice.UpdatedAt = now
+	return nil
+}
+
+// Delete removes a draft invoice together with its items. Invoices in any
+// other status are left untouched.
+func (r *postgresInvoiceRepository) Delete(ctx context.Context, id string, userID string) error {
+	_, err := r.q.ExecContext(ctx,
+		`DELETE FROM invoices WHERE id = $1 AND user_id = $2 AND status = $3`,
+		id, userID, models.InvoiceStatusDraft)
+	return err
+}
+
+// ReleaseNumber hands the invoice's number back to the workspace sequence if
+// it is the latest one allocated, and reports whether it did. Create numbers
+// an invoice by its issue date, or by the time of creation without one.
+func (r *postgresInvoiceRepository) ReleaseNumber(ctx context.Context, invoice *models.Invoice) (bool, error) {
+	var released bool
+	err := r.inTx(ctx, func(txRepo *postgresInvoiceRepository) error {
+		numberDate := invoice.IssueDate
+		if numberDate.IsZero() {
+			numberDate = invoice.CreatedAt
+		}
+		var err error
+		released, err = releaseInvoiceNumber(ctx, txRepo.tx, invoice.UserID, invoice.InvoiceNumber, numberDate)
+		return err
+	})
+	return released, err
+}
+
 func (r *postgresInvoiceRepository) GetItems(ctx context.Context, invoiceID string) ([]models.InvoiceItem, error) {
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, invoice_id, description, quantity, unit_price, amount, created_at, updated_at
-		 FROM invoice_items WHERE invoice_id = $1 ORDER BY created_at`,
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, invoice_id, description, quantity, unit_price, discount_type, discount_value, discount_amount,
+		 amount, tax_code_id, taxes, catalog_item_id, unit, sku, late_fee, created_at, updated_at
+		 FROM invoice_items WHERE invoice_id = $1 ORDER BY created_at, id`,
 		invoiceID)
 	if err != nil {
 		return nil, err
@@ -188,8 +476,20 @@ func (r *postgresInvoiceRepository) GetItems(ctx context.Context, invoiceID stri
 	var items []models.InvoiceItem
 	for rows.Next() {
 		var item models.InvoiceItem
-		if err := rows.Scan(&item.ID, &item.InvoiceID, &item.Description, &item.Quantity,
-			&item.UnitPrice, &item.Amount, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		var taxCodeID, discountType, catalogItemID, unit, sku sql.NullString
+		var discountValue money.Decimal
+		var taxes []byte
+		if err := rows.Scan(&item.ID, &item.InvoiceID, &item.Description, &item.Quantity, &item.UnitPrice,
+			&discountType, &discountValue, &item.DiscountAmount, &item.Amount, &taxCodeID, &taxes, &catalogItemID,
+			&unit, &sku, &item.LateFee, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		item.Discount = scannedDiscount(discountType, discountValue)
+		if taxCodeID.Valid {
+			item.TaxCodeID = &taxCodeID.String
+		}
+		item.CatalogItemID, item.Unit, item.SKU = scannedCatalogFields(catalogItemID, unit, sku)
+		if err := json.Unmarshal(taxes, &item.Taxes); err != nil {
 			return nil, err
 		}
 		items = append(items, item)
@@ -202,10 +502,19 @@ func (r *postgresInvoiceRepository) CreateItem(ctx context.Context, item *models
 	id := uuid.NewString()
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO invoice_items (id, invoice_id, description, quantity, unit_price, amount, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`,
-		id, item.InvoiceID, item.Description, item.Quantity, item.UnitPrice, item.Amount, now)
+	taxes, err := marshalJSONList(item.Taxes)
+	if err != nil {
+		return err
+	}
+
+	discountType, discountValue := discountArgs(item.Discount)
+
+	_, err = r.q.ExecContext(ctx,
+		`INSERT INTO invoice_items (id, invoice_id, description, quantity, unit_price, discount_type, discount_value,
+		 discount_amount, amount, tax_code_id, taxes, catalog_item_id, unit, sku, late_fee, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)`,
+		id, item.InvoiceID, item.Description, item.Quantity, item.UnitPrice, discountType, discountValue,
+		item.DiscountAmount, item.Amount, item.TaxCodeID, taxes, item.CatalogItemID, item.Unit, item.SKU, item.LateFee, now)
 	if err != nil {
 		return err
 	}
@@ -219,21 +528,37 @@ func (r *postgresInvoiceRepository) CreateItem(ctx context.Context, item *models
 func (r *postgresInvoiceRepository) UpdateItem(ctx context.Context, item *models.InvoiceItem) error {
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
-		`UPDATE invoice_items SET description = $1, quantity = $2, unit_price = $3, amount = $4, updated_at = $5
-		 WHERE id = $6`,
-		item.Description, item.Quantity, item.UnitPrice, item.Amount, now, item.ID)
+	taxes, err := marshalJSONList(item.Taxes)
+	if err != nil {
+		return err
+	}
+
+	discountType, discountValue := discountArgs(item.Discount)
+
+	_, err = r.q.ExecContext(ctx,
+		`UPDATE invoice_items SET description = $1, quantity = $2, unit_price = $3, discount_type = $4,
+		 discount_value = $5, discount_amount = $6, amount = $7, tax_code_id = $8, taxes = $9, catalog_item_id = $10,
+		 unit = $11, sku = $12, updated_at = $13
+		 WHERE id = $14`,
+		item.Description, item.Quantity, item.UnitPrice, discountType, discountValue, item.DiscountAmount,
+		item.Amount, item.TaxCodeID, taxes, item.CatalogItemID, item.Unit, item.SKU, now, item.ID)
 	return err
 }
 
 func (r *postgresInvoiceRepository) DeleteItem(ctx context.Context, itemID string) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM invoice_items WHERE id = $1`, itemID)
+	_, err := r.q.ExecContext(ctx, `DELETE FROM invoice_items WHERE id = $1`, itemID)
+	return err
+}
+
+func (r *postgresInvoiceRepository) DeleteItems(ctx context.Context, invoiceID string) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM invoice_items WHERE invoice_id = $1`, invoiceID)
 	return err
 }
 
 func (r *postgresInvoiceRepository) GetPayments(ctx context.Context, invoiceID string) ([]models.Payment, error) {
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, invoice_id, amount, currency, payment_method, payment_date, transaction_id, notes, created_at, updated_at
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, invoice_id, amount, currency, payment_method, payment_date, transaction_id, notes, credit_note_id,
+		 created_at, updated_at
 		 FROM payments WHERE invoice_id = $1 ORDER BY payment_date DESC`,
 		invoiceID)
 	if err != nil {
@@ -244,10 +569,10 @@ func (r *postgresInvoiceRepository) GetPayments(ctx context.Context, invoiceID s
 	var payments []models.Payment
 	for rows.Next() {
 		var p models.Payment
-		var paymentMethod, transactionID, notes sql.NullString
+		var paymentMethod, transactionID, notes, creditNoteID sql.NullString
 
 		if err := rows.Scan(&p.ID, &p.InvoiceID, &p.Amount, &p.Currency, &paymentMethod,
-			&p.PaymentDate, &transactionID, &notes, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			&p.PaymentDate, &transactionID, &notes, &creditNoteID, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
 
@@ -260,6 +585,9 @@ func (r *postgresInvoiceRepository) GetPayments(ctx context.Context, invoiceID s
 		if notes.Valid {
 			p.Notes = &notes.String
 		}
+		if creditNoteID.Valid {
+			p.CreditNoteID = &creditNoteID.String
+		}
 
 		payments = append(payments, p)
 	}
@@ -271,11 +599,12 @@ func (r *postgresInvoiceRepository) CreatePayment(ctx context.Context, payment *
 	id := uuid.NewString()
 	now := time.Now().UTC()
 
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO payments (id, invoice_id, amount, currency, payment_method, payment_date, transaction_id, notes, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)`,
+	_, err := r.q.ExecContext(ctx,
+		`INSERT INTO payments (id, invoice_id, amount, currency, payment_method, payment_date, transaction_id, notes,
+		 credit_note_id, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`,
 		id, payment.InvoiceID, payment.Amount, payment.Currency, payment.PaymentMethod,
-		payment.PaymentDate, payment.TransactionID, payment.Notes, now)
+		payment.PaymentDate, payment.TransactionID, payment.Notes, payment.CreditNoteID, now)
 	if err != nil {
 		return err
 	}
@@ -286,3 +615,39 @@ func (r *postgresInvoiceRepository) CreatePayment(ctx context.Context, payment *
 	return nil
 }
 
+// MarkOverdue moves every invoice in one of fromStatuses whose due date is
+// before asOf to overdue, across all workspaces. Rows are claimed with
+// FOR UPDATE SKIP LOCKED so concurrent sweeps on several replicas never
+// transition the same invoice twice, and re-running the sweep is a no-op.
+func (r *postgresInvoiceRepository) MarkOverdue(ctx context.Context, fromStatuses []models.InvoiceStatus, asOf time.Time) ([]StatusTransition, error) {
+	statuses := make([]string, len(fromStatuses))
+	for i, status := range fromStatuses {
+		statuses[i] = string(status)
+	}
+
+	rows, err := r.q.QueryContext(ctx,
+		`WITH due AS (
+			SELECT id, status FROM invoices
+			WHERE status = ANY($1) AND due_date IS NOT NULL AND due_date < $2
+			FOR UPDATE SKIP LOCKED
+		 )
+		 UPDATE invoices i SET status = $3, updated_at = $4
+		 FROM due WHERE i.id = due.id
+		 RETURNING i.id, i.user_id, i.invoice_number, due.status`,
+		statuses, asOf, models.InvoiceStatusOverdue, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []StatusTransition
+	for rows.Next() {
+		t := StatusTransition{To: models.InvoiceStatusOverdue}
+		if err := rows.Scan(&t.InvoiceID, &t.UserID, &t.InvoiceNumber, &t.From); err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, t)
+	}
+
+	return transitions, rows.Err()
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+type InvoiceShareRepository interface {
+	ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceShareLink, error)
+	// GetByID looks a link up without a user scope; it backs the public,
+	// unauthenticated view and callers must verify the token first.
+	GetByID(ctx context.Context, id string) (*models.InvoiceShareLink, error)
+	Create(ctx context.Context, link *models.InvoiceShareLink) error
+	Revoke(ctx context.Context, id string, invoiceID string, userID string, at time.Time) (bool, error)
+	RecordView(ctx context.Context, view *models.InvoiceShareView) error
+}
+
+type postgresInvoiceShareRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceShareRepository(db *sql.DB) InvoiceShareRepository {
+	return &postgresInvoiceShareRepository{db: db}
+}
+
+// shareLinkColumns selects a link with its view statistics, which are derived
+// from invoice_share_views.
+const shareLinkColumns = `id, user_id, invoice_id, expires_at, revoked_at, created_at,
+	(SELECT COUNT(*) FROM invoice_share_views v WHERE v.share_link_id = invoice_share_links.id) AS view_count,
+	(SELECT MAX(v.viewed_at) FROM invoice_share_views v WHERE v.share_link_id = invoice_share_links.id) AS last_viewed_at`
+
+func scanShareLink(row rowScanner) (*models.InvoiceShareLink, error) {
+	var link models.InvoiceShareLink
+	var revokedAt, lastViewedAt sql.NullTime
+	if err := row.Scan(&link.ID, &link.UserID, &link.InvoiceID, &link.ExpiresAt, &revokedAt, &link.CreatedAt,
+		&link.ViewCount, &lastViewedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	if lastViewedAt.Valid {
+		link.LastViewedAt = &lastViewedAt.Time
+	}
+	return &link, nil
+}
+
+func (r *postgresInvoiceShareRepository) ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceShareLink, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+shareLinkColumns+` FROM invoice_share_links
+		 WHERE invoice_id = $1 AND user_id = $2 ORDER BY created_at DESC`,
+		invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.InvoiceShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *postgresInvoiceShareRepository) GetByID(ctx context.Context, id string) (*models.InvoiceShareLink, error) {
+	link, err := scanShareLink(r.db.QueryRowContext(ctx,
+		`SELECT `+shareLinkColumns+` FROM invoice_share_links WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (r *postgresInvoiceShareRepository) Create(ctx context.Context, link *models.InvoiceShareLink) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO invoice_share_links (id, user_id, invoice_id, expires_at, created_at)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		id, link.UserID, link.InvoiceID, link.ExpiresAt, now)
+	if err != nil {
+		return err
+	}
+
+	link.ID = id
+	link.CreatedAt = now
+	return nil
+}
+
+// Revoke marks the link revoked. It reports false when no matching link
+// exists; revoking an already revoked link keeps the original time.
+func (r *postgresInvoiceShareRepository) Revoke(ctx context.Context, id string, invoiceID string, userID string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE invoice_share_links SET revoked_at = COALESCE(revoked_at, $1)
+		 WHERE id = $2 AND invoice_id = $3 AND user_id = $4`,
+		at, id, invoiceID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *postgresInvoiceShareRepository) RecordView(ctx context.Context, view *models.InvoiceShareView) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO invoice_share_views (id, share_link_id, invoice_id, format, ip_address, user_agent, viewed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		id, view.ShareLinkID, view.InvoiceID, view.Format, view.IPAddress, view.UserAgent, now)
+	if err != nil {
+		return err
+	}
+
+	view.ID = id
+	view.ViewedAt = now
+	return nil
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// LateFeeCandidate is an invoice in the given status whose effective late fee
+// policy has a period due that has not been charged yet.
+type LateFeeCandidate struct {
+	InvoiceID string
+	UserID    string
+	Policy    models.LateFeePolicy
+}
+
+type LateFeeRepository interface {
+	// GetPolicy returns the client's override when clientID is set and the
+	// workspace policy otherwise, or nil when none has been saved.
+	GetPolicy(ctx context.Context, userID string, clientID *string) (*models.LateFeePolicy, error)
+	SavePolicy(ctx context.Context, policy *models.LateFeePolicy) error
+	DeleteClientPolicy(ctx context.Context, userID string, clientID string) (bool, error)
+	ListDue(ctx context.Context, status models.InvoiceStatus, today time.Time, limit int) ([]LateFeeCandidate, error)
+}
+
+type postgresLateFeeRepository struct {
+	db *sql.DB
+}
+
+func NewLateFeeRepository(db *sql.DB) LateFeeRepository {
+	return &postgresLateFeeRepository{db: db}
+}
+
+func scanLateFeePolicy(row rowScanner) (*models.LateFeePolicy, error) {
+	var policy models.LateFeePolicy
+	var clientID sql.NullString
+	var maxAmount money.Decimal
+	var hasMax bool
+	if err := row.Scan(&policy.UserID, &clientID, &policy.Enabled, &policy.FeeType, &policy.Amount,
+		&policy.GraceDays, &hasMax, &maxAmount, &policy.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if clientID.Valid {
+		policy.ClientID = &clientID.String
+	}
+	if hasMax {
+		policy.MaxAmount = &maxAmount
+	}
+	return &policy, nil
+}
+
+const lateFeePolicyColumns = `user_id, client_id, enabled, fee_type, amount, grace_days,
+	max_amount IS NOT NULL, COALESCE(max_amount, 0), updated_at`
+
+func (r *postgresLateFeeRepository) GetPolicy(ctx context.Context, userID string, clientID *string) (*models.LateFeePolicy, error) {
+	var row *sql.Row
+	if clientID == nil {
+		row = r.db.QueryRowContext(ctx,
+			`SELECT `+lateFeePolicyColumns+` FROM late_fee_policies WHERE user_id = $1 AND client_id IS NULL`,
+			userID)
+	} else {
+		row = r.db.QueryRowContext(ctx,
+			`SELECT `+lateFeePolicyColumns+` FROM late_fee_policies WHERE user_id = $1 AND client_id = $2`,
+			userID, *clientID)
+	}
+
+	policy, err := scanLateFeePolicy(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (r *postgresLateFeeRepository) SavePolicy(ctx context.Context, policy *models.LateFeePolicy) error {
+	conflict := `(user_id) WHERE client_id IS NULL`
+	if policy.ClientID != nil {
+		conflict = `(user_id, client_id) WHERE client_id IS NOT NULL`
+	}
+
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO late_fee_policies (id, user_id, client_id, enabled, fee_type, amount, grace_days, max_amount,
+		 updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT `+conflict+` DO UPDATE SET enabled = EXCLUDED.enabled, fee_type = EXCLUDED.fee_type,
+		 amount = EXCLUDED.amount, grace_days = EXCLUDED.grace_days, max_amount = EXCLUDED.max_amount,
+		 updated_at = EXCLUDED.updated_at`,
+		uuid.NewString(), policy.UserID, policy.ClientID, policy.Enabled, policy.FeeType, policy.Amount,
+		policy.GraceDays, policy.MaxAmount, now)
+	if err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = now
+	return nil
+}
+
+func (r *postgresLateFeeRepository) DeleteClientPolicy(ctx context.Context, userID string, clientID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM late_fee_policies WHERE user_id = $1 AND client_id = $2`,
+		userID, clientID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ListDue finds invoices in status whose effective policy (the client's
+// override, else the workspace's) is enabled and whose next period is due by
+// today. Period n falls n-1 months after the grace period ends; flat fees
+// only have period 1.
+func (r *postgresLateFeeRepository) ListDue(ctx context.Context, status models.InvoiceStatus, today time.Time, limit int) ([]LateFeeCandidate, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT i.id, p.user_id, p.client_id, p.enabled, p.fee_type, p.amount, p.grace_days,
+		 p.max_amount IS NOT NULL, COALESCE(p.max_amount, 0), p.updated_at
+		 FROM invoices i
+		 JOIN LATERAL (
+			SELECT * FROM late_fee_policies lp
+			WHERE lp.user_id = i.user_id AND (lp.client_id = i.client_id OR lp.client_id IS NULL)
+			ORDER BY lp.client_id NULLS LAST LIMIT 1
+		 ) p ON p.enabled
+		 LEFT JOIN LATERAL (
+			SELECT MAX(f.period) AS period FROM invoice_late_fees f WHERE f.invoice_id = i.id
+		 ) last ON TRUE
+		 WHERE i.status = $1 AND i.due_date IS NOT NULL
+		 AND (p.fee_type = 'percentage' OR last.period IS NULL)
+		 AND i.due_date + p.grace_days + make_interval(months => COALESCE(last.period, 0)) <= $2
+		 ORDER BY i.due_date, i.id
+		 LIMIT $3`,
+		status, today, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []LateFeeCandidate
+	for rows.Next() {
+		var c LateFeeCandidate
+		var clientID sql.NullString
+		var maxAmount money.Decimal
+		var hasMax bool
+		if err := rows.Scan(&c.InvoiceID, &c.Policy.UserID, &clientID, &c.Policy.Enabled, &c.Policy.FeeType,
+			&c.Policy.Amount, &c.Policy.GraceDays, &hasMax, &maxAmount, &c.Policy.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if clientID.Valid {
+			c.Policy.ClientID = &clientID.String
+		}
+		if hasMax {
+			c.Policy.MaxAmount = &maxAmount
+		}
+		c.UserID = c.Policy.UserID
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
@@ -0,0 +1,434 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+type QuoteRepository interface {
+	List(ctx context.Context, userID string, filters QuoteFilters) ([]models.Quote, error)
+	GetByID(ctx context.Context, id string, userID string) (*models.Quote, error)
+	// GetForLink loads a quote by ID alone, for the client's public link.
+	GetForLink(ctx context.Context, id string) (*models.Quote, error)
+	GetItems(ctx context.Context, quoteID string) ([]models.QuoteItem, error)
+	// Create inserts the quote and its items in one transaction, allocating
+	// the quote number from the workspace's sequence when QuoteNumber is
+	// empty.
+	Create(ctx context.Context, quote *models.Quote) error
+	// Update writes the quote header, including its status, and replaces its
+	// items. It returns ErrQuoteStatusChanged, writing nothing, unless the
+	// stored status is still expected.
+	Update(ctx context.Context, quote *models.Quote, expected models.QuoteStatus) error
+	// UpdateStatus writes the status and the sent, acceptance and decline
+	// details only, under the same expected-status check as Update.
+	UpdateStatus(ctx context.Context, quote *models.Quote, expected models.QuoteStatus) error
+	Delete(ctx context.Context, id string, userID string) error
+	// MarkConverted links the quote to invoiceID unless it already has an
+	// invoice, reporting whether it did.
+	MarkConverted(ctx context.Context, id string, userID string, invoiceID string, at time.Time) (bool, error)
+	// ExpireSent moves sent quotes whose expiry date is before asOf to
+	// expired, across all workspaces, and returns how many changed.
+	ExpireSent(ctx context.Context, asOf time.Time) (int64, error)
+
+	// WithinTx runs fn with quote and invoice repositories bound to a single
+	// transaction, so a quote's conversion commits together with its invoice.
+	// The transaction commits if fn returns nil and rolls back otherwise.
+	WithinTx(ctx context.Context, fn func(quotes QuoteRepository, invoices InvoiceRepository) error) error
+}
+
+var (
+	ErrQuoteNumberExists = errors.New("quote number already exists")
+	// ErrQuoteStatusChanged is returned when a quote's status changed between
+	// being read and being written.
+	ErrQuoteStatusChanged = errors.New("quote status changed")
+)
+
+func translateQuoteWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrQuoteNumberExists
+	}
+	return err
+}
+
+type QuoteFilters struct {
+	Status   *models.QuoteStatus
+	ClientID *string
+}
+
+type postgresQuoteRepository struct {
+	db *sql.DB
+	q  dbtx
+	tx *sql.Tx
+}
+
+func NewQuoteRepository(db *sql.DB) QuoteRepository {
+	return &postgresQuoteRepository{db: db, q: db}
+}
+
+func (r *postgresQuoteRepository) WithinTx(ctx context.Context, fn func(quotes QuoteRepository, invoices InvoiceRepository) error) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return fn(&postgresQuoteRepository{db: r.db, q: tx, tx: tx}, &postgresInvoiceRepository{db: r.db, q: tx, tx: tx})
+	})
+}
+
+// inTx runs fn in the repository's transaction, or in a new one when it is
+// not transactional.
+func (r *postgresQuoteRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	return runInTx(ctx, r.db, fn)
+}
+
+// checkStatusWrite reports ErrQuoteStatusChanged when a status-guarded
+// update matched no row.
+func checkStatusWrite(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrQuoteStatusChanged
+	}
+	return nil
+}
+
+const quoteColumns = `id, user_id, client_id, quote_number, status, issue_date, expiry_date, currency,
+	subtotal, line_discount_amount, discount_type, discount_value, discount_timing, discount_amount,
+	tax_rate, tax_amount, tax_breakdown, total, notes, sent_at, accepted_at, accepted_by, declined_at,
+	decline_reason, invoice_id, converted_at, created_at, updated_at`
+
+func scanQuote(row rowScanner) (*models.Quote, error) {
+	var q models.Quote
+	var expiryDate, sentAt, acceptedAt, declinedAt, convertedAt sql.NullTime
+	var notes, acceptedBy, declineReason, invoiceID, discountType sql.NullString
+	var discountValue money.Decimal
+	var taxBreakdown []byte
+
+	err := row.Scan(&q.ID, &q.UserID, &q.ClientID, &q.QuoteNumber, &q.Status, &q.IssueDate, &expiryDate,
+		&q.Currency, &q.Subtotal, &q.LineDiscountAmount, &discountType, &discountValue, &q.DiscountTiming,
+		&q.DiscountAmount, &q.TaxRate, &q.TaxAmount, &taxBreakdown, &q.Total, &notes, &sentAt, &acceptedAt,
+		&acceptedBy, &declinedAt, &declineReason, &invoiceID, &convertedAt, &q.CreatedAt, &q.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(taxBreakdown, &q.TaxBreakdown); err != nil {
+		return nil, err
+	}
+	q.Discount = scannedDiscount(discountType, discountValue)
+
+	if expiryDate.Valid {
+		q.ExpiryDate = &expiryDate.Time
+	}
+	if notes.Valid {
+		q.Notes = &notes.String
+	}
+	if sentAt.Valid {
+		q.SentAt = &sentAt.Time
+	}
+	if acceptedAt.Valid {
+		q.AcceptedAt = &acceptedAt.Time
+	}
+	if acceptedBy.Valid {
+		q.AcceptedBy = &acceptedBy.String
+	}
+	if declinedAt.Valid {
+		q.DeclinedAt = &declinedAt.Time
+	}
+	if declineReason.Valid {
+		q.DeclineReason = &declineReason.String
+	}
+	if invoiceID.Valid {
+		q.InvoiceID = &invoiceID.String
+	}
+	if convertedAt.Valid {
+		q.ConvertedAt = &convertedAt.Time
+	}
+
+	return &q, nil
+}
+
+func (r *postgresQuoteRepository) List(ctx context.Context, userID string, filters QuoteFilters) ([]models.Quote, error) {
+	query := `SELECT ` + quoteColumns + ` FROM quotes WHERE user_id = $1`
+	args := []interface{}{userID}
+	argPos := 2
+
+	if filters.Status != nil {
+		query += ` AND status = $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.Status)
+		argPos++
+	}
+	if filters.ClientID != nil {
+		query += ` AND client_id = $` + fmt.Sprintf("%d", argPos)
+		args = append(args, *filters.ClientID)
+		argPos++
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotes []models.Quote
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, *q)
+	}
+
+	return quotes, rows.Err()
+}
+
+func (r *postgresQuoteRepository) GetByID(ctx context.Context, id string, userID string) (*models.Quote, error) {
+	q, err := scanQuote(r.q.QueryRowContext(ctx,
+		`SELECT `+quoteColumns+` FROM quotes WHERE id = $1 AND user_id = $2`,
+		id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (r *postgresQuoteRepository) GetForLink(ctx context.Context, id string) (*models.Quote, error) {
+	q, err := scanQuote(r.q.QueryRowContext(ctx,
+		`SELECT `+quoteColumns+` FROM quotes WHERE id = $1`,
+		id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (r *postgresQuoteRepository) GetItems(ctx context.Context, quoteID string) ([]models.QuoteItem, error) {
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, quote_id, description, quantity, unit_price, discount_type, discount_value, discount_amount,
+		 amount, tax_code_id, taxes, catalog_item_id, unit, sku, created_at
+		 FROM quote_items WHERE quote_id = $1 ORDER BY position, id`,
+		quoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.QuoteItem
+	for rows.Next() {
+		var item models.QuoteItem
+		var taxCodeID, discountType, catalogItemID, unit, sku sql.NullString
+		var discountValue money.Decimal
+		var taxes []byte
+		if err := rows.Scan(&item.ID, &item.QuoteID, &item.Description, &item.Quantity, &item.UnitPrice,
+			&discountType, &discountValue, &item.DiscountAmount, &item.Amount, &taxCodeID, &taxes,
+			&catalogItemID, &unit, &sku, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		item.Discount = scannedDiscount(discountType, discountValue)
+		if taxCodeID.Valid {
+			item.TaxCodeID = &taxCodeID.String
+		}
+		item.CatalogItemID, item.Unit, item.SKU = scannedCatalogFields(catalogItemID, unit, sku)
+		if err := json.Unmarshal(taxes, &item.Taxes); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *postgresQuoteRepository) Create(ctx context.Context, quote *models.Quote) error {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	taxBreakdown, err := marshalJSONList(quote.TaxBreakdown)
+	if err != nil {
+		return err
+	}
+	if quote.DiscountTiming == "" {
+		quote.DiscountTiming = models.DiscountTimingPreTax
+	}
+	discountType, discountValue := discountArgs(quote.Discount)
+	number := quote.QuoteNumber
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		if number == "" {
+			allocated, err := allocateQuoteNumber(ctx, tx, quote.UserID, quote.IssueDate)
+			if err != nil {
+				return fmt.Errorf("allocate quote number: %w", err)
+			}
+			number = allocated
+		}
+
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO quotes (id, user_id, client_id, quote_number, status, issue_date, expiry_date, currency,
+			 subtotal, line_discount_amount, discount_type, discount_value, discount_timing, discount_amount,
+			 tax_rate, tax_amount, tax_breakdown, total, notes, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $20)`,
+			id, quote.UserID, quote.ClientID, number, quote.Status, quote.IssueDate, quote.ExpiryDate,
+			quote.Currency, quote.Subtotal, quote.LineDiscountAmount, discountType, discountValue,
+			quote.DiscountTiming, quote.DiscountAmount, quote.TaxRate, quote.TaxAmount, taxBreakdown, quote.Total,
+			quote.Notes, now)
+		if err != nil {
+			return translateQuoteWriteError(err)
+		}
+		return insertQuoteItems(ctx, tx, id, quote.Items, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	quote.ID = id
+	quote.QuoteNumber = number
+	quote.CreatedAt = now
+	quote.UpdatedAt = now
+	for i := range quote.Items {
+		quote.Items[i].QuoteID = id
+	}
+	return nil
+}
+
+func (r *postgresQuoteRepository) Update(ctx context.Context, quote *models.Quote, expected models.QuoteStatus) error {
+	now := time.Now().UTC()
+
+	taxBreakdown, err := marshalJSONList(quote.TaxBreakdown)
+	if err != nil {
+		return err
+	}
+	if quote.DiscountTiming == "" {
+		quote.DiscountTiming = models.DiscountTimingPreTax
+	}
+	discountType, discountValue := discountArgs(quote.Discount)
+
+	err = r.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE quotes SET status = $1, issue_date = $2, expiry_date = $3, currency = $4, subtotal = $5,
+			 line_discount_amount = $6, discount_type = $7, discount_value = $8, discount_timing = $9,
+			 discount_amount = $10, tax_rate = $11, tax_amount = $12, tax_breakdown = $13, total = $14, notes = $15,
+			 sent_at = $16, accepted_at = $17, accepted_by = $18, declined_at = $19, decline_reason = $20,
+			 updated_at = $21
+			 WHERE id = $22 AND user_id = $23 AND status = $24`,
+			quote.Status, quote.IssueDate, quote.ExpiryDate, quote.Currency, quote.Subtotal,
+			quote.LineDiscountAmount, discountType, discountValue, quote.DiscountTiming, quote.DiscountAmount,
+			quote.TaxRate, quote.TaxAmount, taxBreakdown, quote.Total, quote.Notes, quote.SentAt, quote.AcceptedAt,
+			quote.AcceptedBy, quote.DeclinedAt, quote.DeclineReason, now, quote.ID, quote.UserID, expected)
+		if err != nil {
+			return err
+		}
+		if err := checkStatusWrite(result); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM quote_items WHERE quote_id = $1`, quote.ID); err != nil {
+			return err
+		}
+		return insertQuoteItems(ctx, tx, quote.ID, quote.Items, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	quote.UpdatedAt = now
+	return nil
+}
+
+func insertQuoteItems(ctx context.Context, tx *sql.Tx, quoteID string, items []models.QuoteItem, now time.Time) error {
+	for i := range items {
+		item := &items[i]
+		taxes, err := marshalJSONList(item.Taxes)
+		if err != nil {
+			return err
+		}
+		discountType, discountValue := discountArgs(item.Discount)
+
+		id := uuid.NewString()
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO quote_items (id, quote_id, description, quantity, unit_price, discount_type,
+			 discount_value, discount_amount, amount, tax_code_id, taxes, catalog_item_id, unit, sku, position, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+			id, quoteID, item.Description, item.Quantity, item.UnitPrice, discountType, discountValue,
+			item.DiscountAmount, item.Amount, item.TaxCodeID, taxes, item.CatalogItemID, item.Unit, item.SKU, i, now)
+		if err != nil {
+			return err
+		}
+
+		item.ID = id
+		item.QuoteID = quoteID
+		item.CreatedAt = now
+	}
+	return nil
+}
+
+func (r *postgresQuoteRepository) UpdateStatus(ctx context.Context, quote *models.Quote, expected models.QuoteStatus) error {
+	now := time.Now().UTC()
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE quotes SET status = $1, sent_at = $2, accepted_at = $3, accepted_by = $4, declined_at = $5,
+		 decline_reason = $6, updated_at = $7
+		 WHERE id = $8 AND user_id = $9 AND status = $10`,
+		quote.Status, quote.SentAt, quote.AcceptedAt, quote.AcceptedBy, quote.DeclinedAt, quote.DeclineReason,
+		now, quote.ID, quote.UserID, expected)
+	if err != nil {
+		return err
+	}
+	if err := checkStatusWrite(result); err != nil {
+		return err
+	}
+
+	quote.UpdatedAt = now
+	return nil
+}
+
+// Delete removes a draft quote together with its items. Quotes in any other
+// status are left untouched.
+func (r *postgresQuoteRepository) Delete(ctx context.Context, id string, userID string) error {
+	_, err := r.q.ExecContext(ctx,
+		`DELETE FROM quotes WHERE id = $1 AND user_id = $2 AND status = $3`,
+		id, userID, models.QuoteStatusDraft)
+	return err
+}
+
+func (r *postgresQuoteRepository) MarkConverted(ctx context.Context, id string, userID string, invoiceID string, at time.Time) (bool, error) {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE quotes SET invoice_id = $1, converted_at = $2, updated_at = $2
+		 WHERE id = $3 AND user_id = $4 AND invoice_id IS NULL`,
+		invoiceID, at, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *postgresQuoteRepository) ExpireSent(ctx context.Context, asOf time.Time) (int64, error) {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE quotes SET status = $1, updated_at = $2
+		 WHERE status = $3 AND expiry_date IS NOT NULL AND expiry_date < $4`,
+		models.QuoteStatusExpired, time.Now().UTC(), models.QuoteStatusSent, asOf)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+type RecurringInvoiceRepository interface {
+	List(ctx context.Context, userID string) ([]models.RecurringInvoice, error)
+	GetByID(ctx context.Context, id string, userID string) (*models.RecurringInvoice, error)
+	Create(ctx context.Context, recurring *models.RecurringInvoice) (*models.RecurringInvoice, error)
+	Update(ctx context.Context, recurring *models.RecurringInvoice) (*models.RecurringInvoice, error)
+	Delete(ctx context.Context, id string, userID string) error
+	ListDue(ctx context.Context, asOf time.Time, limit int) ([]models.RecurringInvoice, error)
+	Advance(ctx context.Context, id string, previousRun time.Time, nextRun *time.Time, ranAt time.Time) (bool, error)
+}
+
+type postgresRecurringInvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewRecurringInvoiceRepository(db *sql.DB) RecurringInvoiceRepository {
+	return &postgresRecurringInvoiceRepository{db: db}
+}
+
+const recurringInvoiceColumns = `id, user_id, client_id, name, currency, tax_rate, discount_type, discount_value,
+	discount_timing, notes, items, cadence, cron_expression,
+	start_date, end_date, next_run_date, last_run_at, payment_terms_days, auto_send, active, created_at, updated_at`
+
+func scanRecurringInvoice(row rowScanner) (*models.RecurringInvoice, error) {
+	var ri models.RecurringInvoice
+	var notes, cronExpr, discountType sql.NullString
+	var discountValue money.Decimal
+	var endDate, nextRun, lastRun sql.NullTime
+	var terms sql.NullInt64
+	var items []byte
+
+	err := row.Scan(&ri.ID, &ri.UserID, &ri.ClientID, &ri.Name, &ri.Currency, &ri.TaxRate, &discountType, &discountValue,
+		&ri.DiscountTiming, &notes, &items,
+		&ri.Cadence, &cronExpr, &ri.StartDate, &endDate, &nextRun, &lastRun, &terms, &ri.AutoSend, &ri.Active,
+		&ri.CreatedAt, &ri.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(items, &ri.Items); err != nil {
+		return nil, err
+	}
+	ri.Discount = scannedDiscount(discountType, discountValue)
+	if notes.Valid {
+		ri.Notes = &notes.String
+	}
+	if cronExpr.Valid {
+		ri.CronExpression = &cronExpr.String
+	}
+	if endDate.Valid {
+		ri.EndDate = &endDate.Time
+	}
+	if nextRun.Valid {
+		ri.NextRunDate = &nextRun.Time
+	}
+	if lastRun.Valid {
+		ri.LastRunAt = &lastRun.Time
+	}
+	if terms.Valid {
+		days := int(terms.Int64)
+		ri.PaymentTermsDays = &days
+	}
+
+	return &ri, nil
+}
+
+func (r *postgresRecurringInvoiceRepository) List(ctx context.Context, userID string) ([]models.RecurringInvoice, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+recurringInvoiceColumns+` FROM recurring_invoices WHERE user_id = $1 ORDER BY created_at DESC`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.RecurringInvoice
+	for rows.Next() {
+		ri, err := scanRecurringInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *ri)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresRecurringInvoiceRepository) GetByID(ctx context.Context, id string, userID string) (*models.RecurringInvoice, error) {
+	ri, err := scanRecurringInvoice(r.db.QueryRowContext(ctx,
+		`SELECT `+recurringInvoiceColumns+` FROM recurring_invoices WHERE id = $1 AND user_id = $2`,
+		id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ri, nil
+}
+
+func (r *postgresRecurringInvoiceRepository) Create(ctx context.Context, recurring *models.RecurringInvoice) (*models.RecurringInvoice, error) {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	items, err := json.Marshal(recurring.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurring.DiscountTiming == "" {
+		recurring.DiscountTiming = models.DiscountTimingPreTax
+	}
+	discountType, discountValue := discountArgs(recurring.Discount)
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO recurring_invoices (id, user_id, client_id, name, currency, tax_rate, discount_type, discount_value,
+		 discount_timing, notes, items, cadence, cron_expression, start_date, end_date, next_run_date, payment_terms_days,
+		 auto_send, active, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $20)`,
+		id, recurring.UserID, recurring.ClientID, recurring.Name, recurring.Currency, recurring.TaxRate, discountType,
+		discountValue, recurring.DiscountTiming, recurring.Notes, items, recurring.Cadence, recurring.CronExpression,
+		recurring.StartDate, recurring.EndDate, recurring.NextRunDate, recurring.PaymentTermsDays, recurring.AutoSend,
+		recurring.Active, now)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring.ID = id
+	recurring.CreatedAt = now
+	recurring.UpdatedAt = now
+	return recurring, nil
+}
+
+func (r *postgresRecurringInvoiceRepository) Update(ctx context.Context, recurring *models.RecurringInvoice) (*models.RecurringInvoice, error) {
+	now := time.Now().UTC()
+
+	items, err := json.Marshal(recurring.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurring.DiscountTiming == "" {
+		recurring.DiscountTiming = models.DiscountTimingPreTax
+	}
+	discountType, discountValue := discountArgs(recurring.Discount)
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE recurring_invoices SET client_id = $1, name = $2, currency = $3, tax_rate = $4, discount_type = $5,
+		 discount_value = $6, discount_timing = $7, notes = $8, items = $9, cadence = $10, cron_expression = $11,
+		 start_date = $12, end_date = $13, next_run_date = $14, payment_terms_days = $15, auto_send = $16, active = $17,
+		 updated_at = $18
+		 WHERE id = $19 AND user_id = $20`,
+		recurring.ClientID, recurring.Name, recurring.Currency, recurring.TaxRate, discountType, discountValue,
+		recurring.DiscountTiming, recurring.Notes, items,
+		recurring.Cadence, recurring.CronExpression, recurring.StartDate, recurring.EndDate, recurring.NextRunDate,
+		recurring.PaymentTermsDays, recurring.AutoSend, recurring.Active, now, recurring.ID, recurring.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring.UpdatedAt = now
+	return recurring, nil
+}
+
+func (r *postgresRecurringInvoiceRepository) Delete(ctx context.Context, id string, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM recurring_invoices WHERE id = $1 AND user_id = $2`, id, userID)
+	return err
+}
+
+// ListDue returns active schedules, across all workspaces, whose next run date
+// is on or before asOf.
+func (r *postgresRecurringInvoiceRepository) ListDue(ctx context.Context, asOf time.Time, limit int) ([]models.RecurringInvoice, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+recurringInvoiceColumns+` FROM recurring_invoices
+		 WHERE active AND next_run_date IS NOT NULL AND next_run_date <= $1
+		 ORDER BY next_run_date LIMIT $2`,
+		asOf, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.RecurringInvoice
+	for rows.Next() {
+		ri, err := scanRecurringInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *ri)
+	}
+
+	return result, rows.Err()
+}
+
+// Advance moves a schedule from previousRun to nextRun. The compare-and-set on
+// next_run_date means that when two workers process the same occurrence only
+// one of them advances it; the other gets false.
+func (r *postgresRecurringInvoiceRepository) Advance(ctx context.Context, id string, previousRun time.Time, nextRun *time.Time, ranAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE recurring_invoices SET next_run_date = $1, last_run_at = $2, updated_at = $2
+		 WHERE id = $3 AND next_run_date = $4`,
+		nextRun, ranAt, id, previousRun)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
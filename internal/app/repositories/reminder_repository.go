@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+// ReminderCandidate is an open invoice in a workspace with reminders enabled
+// that has at least one step due and not yet handled.
+type ReminderCandidate struct {
+	InvoiceID string
+	UserID    string
+	DueDate   time.Time
+	Steps     []models.ReminderStep
+	// LastOffset is the latest step already handled for the invoice, if any.
+	LastOffset *int
+}
+
+type ReminderRepository interface {
+	// GetPolicy returns nil when the workspace has not saved a policy.
+	GetPolicy(ctx context.Context, userID string) (*models.ReminderPolicy, error)
+	SavePolicy(ctx context.Context, policy *models.ReminderPolicy) error
+	ListDue(ctx context.Context, statuses []models.InvoiceStatus, today time.Time, limit int) ([]ReminderCandidate, error)
+	ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceReminder, error)
+	// Claim records the reminder unless its step was already handled for the
+	// invoice, reporting whether this caller now owns it.
+	Claim(ctx context.Context, reminder *models.InvoiceReminder) (bool, error)
+	// Release removes a claim whose email could not be sent so the step is
+	// retried on a later run.
+	Release(ctx context.Context, id string) error
+}
+
+type postgresReminderRepository struct {
+	db *sql.DB
+}
+
+func NewReminderRepository(db *sql.DB) ReminderRepository {
+	return &postgresReminderRepository{db: db}
+}
+
+func (r *postgresReminderRepository) GetPolicy(ctx context.Context, userID string) (*models.ReminderPolicy, error) {
+	policy := models.ReminderPolicy{UserID: userID}
+	var steps []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT enabled, steps, updated_at FROM reminder_policies WHERE user_id = $1`,
+		userID).Scan(&policy.Enabled, &steps, &policy.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(steps, &policy.Steps); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *postgresReminderRepository) SavePolicy(ctx context.Context, policy *models.ReminderPolicy) error {
+	steps, err := marshalJSONList(policy.Steps)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO reminder_policies (user_id, enabled, steps, updated_at)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id) DO UPDATE SET enabled = EXCLUDED.enabled, steps = EXCLUDED.steps,
+		 updated_at = EXCLUDED.updated_at`,
+		policy.UserID, policy.Enabled, steps, now)
+	if err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = now
+	return nil
+}
+
+// ListDue finds invoices in statuses with a due date whose policy has a step
+// on or before today that is later than any step already handled. Earlier
+// steps that were missed are not returned on their own.
+func (r *postgresReminderRepository) ListDue(ctx context.Context, statuses []models.InvoiceStatus, today time.Time, limit int) ([]ReminderCandidate, error) {
+	statusList := make([]string, len(statuses))
+	for i, status := range statuses {
+		statusList[i] = string(status)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT i.id, i.user_id, i.due_date, p.steps, last.offset_days
+		 FROM invoices i
+		 JOIN reminder_policies p ON p.user_id = i.user_id AND p.enabled
+		 LEFT JOIN LATERAL (
+			SELECT MAX(r.offset_days) AS offset_days FROM invoice_reminders r WHERE r.invoice_id = i.id
+		 ) last ON TRUE
+		 WHERE i.status = ANY($1) AND i.due_date IS NOT NULL
+		 AND EXISTS (
+			SELECT 1 FROM jsonb_array_elements(p.steps) s
+			WHERE i.due_date + (s->>'offset_days')::int <= $2
+			AND (last.offset_days IS NULL OR (s->>'offset_days')::int > last.offset_days)
+		 )
+		 ORDER BY i.due_date, i.id
+		 LIMIT $3`,
+		statusList, today, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []ReminderCandidate
+	for rows.Next() {
+		var c ReminderCandidate
+		var steps []byte
+		var lastOffset sql.NullInt64
+		if err := rows.Scan(&c.InvoiceID, &c.UserID, &c.DueDate, &steps, &lastOffset); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(steps, &c.Steps); err != nil {
+			return nil, err
+		}
+		if lastOffset.Valid {
+			offset := int(lastOffset.Int64)
+			c.LastOffset = &offset
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+func (r *postgresReminderRepository) ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceReminder, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, user_id, invoice_id, offset_days, status, sent_to, subject, sent_at
+		 FROM invoice_reminders WHERE invoice_id = $1 AND user_id = $2 ORDER BY sent_at, offset_days`,
+		invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []models.InvoiceReminder
+	for rows.Next() {
+		var reminder models.InvoiceReminder
+		var sentTo sql.NullString
+		if err := rows.Scan(&reminder.ID, &reminder.UserID, &reminder.InvoiceID, &reminder.OffsetDays,
+			&reminder.Status, &sentTo, &reminder.Subject, &reminder.SentAt); err != nil {
+			return nil, err
+		}
+		if sentTo.Valid {
+			reminder.SentTo = &sentTo.String
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+func (r *postgresReminderRepository) Claim(ctx context.Context, reminder *models.InvoiceReminder) (bool, error) {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO invoice_reminders (id, user_id, invoice_id, offset_days, status, sent_to, subject, sent_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (invoice_id, offset_days) DO NOTHING`,
+		id, reminder.UserID, reminder.InvoiceID, reminder.OffsetDays, reminder.Status, reminder.SentTo,
+		reminder.Subject, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	reminder.ID = id
+	reminder.SentAt = now
+	return true, nil
+}
+
+func (r *postgresReminderRepository) Release(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM invoice_reminders WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+var ErrTaxCodeExists = errors.New("tax code already exists")
+
+type TaxCodeRepository interface {
+	List(ctx context.Context, userID string) ([]models.TaxCode, error)
+	GetByID(ctx context.Context, id string, userID string) (*models.TaxCode, error)
+	Create(ctx context.Context, taxCode *models.TaxCode) (*models.TaxCode, error)
+	Update(ctx context.Context, taxCode *models.TaxCode) (*models.TaxCode, error)
+	Delete(ctx context.Context, id string, userID string) error
+}
+
+type postgresTaxCodeRepository struct {
+	db *sql.DB
+}
+
+func NewTaxCodeRepository(db *sql.DB) TaxCodeRepository {
+	return &postgresTaxCodeRepository{db: db}
+}
+
+const taxCodeColumns = `id, user_id, code, name, components, created_at, updated_at`
+
+func scanTaxCode(row rowScanner) (*models.TaxCode, error) {
+	var tc models.TaxCode
+	var components []byte
+	if err := row.Scan(&tc.ID, &tc.UserID, &tc.Code, &tc.Name, &components, &tc.CreatedAt, &tc.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(components, &tc.Components); err != nil {
+		return nil, err
+	}
+	return &tc, nil
+}
+
+func translateTaxCodeWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrTaxCodeExists
+	}
+	return err
+}
+
+func (r *postgresTaxCodeRepository) List(ctx context.Context, userID string) ([]models.TaxCode, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+taxCodeColumns+` FROM tax_codes WHERE user_id = $1 ORDER BY code`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.TaxCode
+	for rows.Next() {
+		tc, err := scanTaxCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *tc)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresTaxCodeRepository) GetByID(ctx context.Context, id string, userID string) (*models.TaxCode, error) {
+	tc, err := scanTaxCode(r.db.QueryRowContext(ctx,
+		`SELECT `+taxCodeColumns+` FROM tax_codes WHERE id = $1 AND user_id = $2`,
+		id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+func (r *postgresTaxCodeRepository) Create(ctx context.Context, taxCode *models.TaxCode) (*models.TaxCode, error) {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+
+	components, err := json.Marshal(taxCode.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO tax_codes (id, user_id, code, name, components, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $6)`,
+		id, taxCode.UserID, taxCode.Code, taxCode.Name, components, now)
+	if err != nil {
+		return nil, translateTaxCodeWriteError(err)
+	}
+
+	taxCode.ID = id
+	taxCode.CreatedAt = now
+	taxCode.UpdatedAt = now
+	return taxCode, nil
+}
+
+func (r *postgresTaxCodeRepository) Update(ctx context.Context, taxCode *models.TaxCode) (*models.TaxCode, error) {
+	now := time.Now().UTC()
+
+	components, err := json.Marshal(taxCode.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE tax_codes SET code = $1, name = $2, components = $3, updated_at = $4
+		 WHERE id = $5 AND user_id = $6`,
+		taxCode.Code, taxCode.Name, components, now, taxCode.ID, taxCode.UserID)
+	if err != nil {
+		return nil, translateTaxCodeWriteError(err)
+	}
+
+	taxCode.UpdatedAt = now
+	return taxCode, nil
+}
+
+func (r *postgresTaxCodeRepository) Delete(ctx context.Context, id string, userID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM tax_codes WHERE id = $1 AND user_id = $2`, id, userID)
+	return err
+}
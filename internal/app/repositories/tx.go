@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// dbtx is the subset of *sql.DB and *sql.Tx the repositories use, so the same
+// query code runs inside or outside a transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// runInTx runs fn inside a transaction that is committed when fn returns nil
+// and rolled back otherwise, including when fn panics.
+func runInTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+type WorkspaceRepository interface {
+	// GetProfile returns the workspace's profile, or nil when none has been
+	// saved.
+	GetProfile(ctx context.Context, userID string) (*models.WorkspaceProfile, error)
+	// SaveProfile stores the profile's branding fields, leaving the logo as
+	// it is.
+	SaveProfile(ctx context.Context, profile *models.WorkspaceProfile) error
+	// SetLogo records the workspace's logo; nil removes it.
+	SetLogo(ctx context.Context, userID string, logo *models.WorkspaceLogo) error
+	// InvoiceCurrency returns the currency most of the workspace's invoices
+	// are in, or "" when it has none.
+	InvoiceCurrency(ctx context.Context, userID string) (string, error)
+	// PinBaseCurrency stores currency as the workspace's base currency
+	// unless it has already chosen one.
+	PinBaseCurrency(ctx context.Context, userID string, currency string) error
+	ListTemplates(ctx context.Context, userID string) ([]models.WorkspaceTemplate, error)
+	GetTemplate(ctx context.Context, userID string, kind models.TemplateKind) (*models.WorkspaceTemplate, error)
+	SaveTemplate(ctx context.Context, template *models.WorkspaceTemplate) error
+	DeleteTemplate(ctx context.Context, userID string, kind models.TemplateKind) (bool, error)
+}
+
+type postgresWorkspaceRepository struct {
+	db *sql.DB
+}
+
+func NewWorkspaceRepository(db *sql.DB) WorkspaceRepository {
+	return &postgresWorkspaceRepository{db: db}
+}
+
+func (r *postgresWorkspaceRepository) GetProfile(ctx context.Context, userID string) (*models.WorkspaceProfile, error) {
+	var profile models.WorkspaceProfile
+	var legalName, address, taxID, bankDetails, footerText, brandColor, accentColor sql.NullString
+	var logoKey, logoType, baseCurrency sql.NullString
+	var logoSize sql.NullInt64
+	var logoWidth, logoHeight sql.NullInt32
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id, legal_name, address, tax_id, bank_details, footer_text, brand_color, accent_color,
+		 invoice_layout, locale, base_currency, logo_key, logo_content_type, logo_size_bytes, logo_width, logo_height, updated_at
+		 FROM workspace_profiles WHERE user_id = $1`,
+		userID).Scan(&profile.UserID, &legalName, &address, &taxID, &bankDetails, &footerText, &brandColor,
+		&accentColor, &profile.InvoiceLayout, &profile.Locale, &baseCurrency, &logoKey, &logoType, &logoSize, &logoWidth, &logoHeight,
+		&profile.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile.BaseCurrency = baseCurrency.String
+	if legalName.Valid {
+		profile.LegalName = &legalName.String
+	}
+	if address.Valid {
+		profile.Address = &address.String
+	}
+	if taxID.Valid {
+		profile.TaxID = &taxID.String
+	}
+	if bankDetails.Valid {
+		profile.BankDetails = &bankDetails.String
+	}
+	if footerText.Valid {
+		profile.FooterText = &footerText.String
+	}
+	if brandColor.Valid {
+		profile.BrandColor = &brandColor.String
+	}
+	if accentColor.Valid {
+		profile.AccentColor = &accentColor.String
+	}
+	if logoKey.Valid {
+		profile.Logo = &models.WorkspaceLogo{
+			StorageKey:  logoKey.String,
+			ContentType: logoType.String,
+			SizeBytes:   logoSize.Int64,
+			Width:       int(logoWidth.Int32),
+			Height:      int(logoHeight.Int32),
+		}
+	}
+
+	return &profile, nil
+}
+
+func (r *postgresWorkspaceRepository) SaveProfile(ctx context.Context, profile *models.WorkspaceProfile) error {
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO workspace_profiles (user_id, legal_name, address, tax_id, bank_details, footer_text,
+		 brand_color, accent_color, invoice_layout, locale, base_currency, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 ON CONFLICT (user_id) DO UPDATE SET legal_name = EXCLUDED.legal_name, address = EXCLUDED.address,
+		 tax_id = EXCLUDED.tax_id, bank_details = EXCLUDED.bank_details, footer_text = EXCLUDED.footer_text,
+		 brand_color = EXCLUDED.brand_color, accent_color = EXCLUDED.accent_color,
+		 invoice_layout = EXCLUDED.invoice_layout, locale = EXCLUDED.locale,
+		 base_currency = EXCLUDED.base_currency, updated_at = EXCLUDED.updated_at`,
+		profile.UserID, profile.LegalName, profile.Address, profile.TaxID, profile.BankDetails, profile.FooterText,
+		profile.BrandColor, profile.AccentColor, profile.InvoiceLayout, profile.Locale, baseCurrencyArg(profile.BaseCurrency), now)
+	if err != nil {
+		return err
+	}
+
+	profile.UpdatedAt = now
+	return nil
+}
+
+// baseCurrencyArg stores a base currency the workspace has not chosen as NULL.
+func baseCurrencyArg(currency string) interface{} {
+	if currency == "" {
+		return nil
+	}
+	return currency
+}
+
+func (r *postgresWorkspaceRepository) InvoiceCurrency(ctx context.Context, userID string) (string, error) {
+	var currency string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT currency FROM invoices WHERE user_id = $1 AND status <> $2
+		 GROUP BY currency ORDER BY COUNT(*) DESC, MAX(created_at) DESC LIMIT 1`,
+		userID, models.InvoiceStatusVoid).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return currency, err
+}
+
+func (r *postgresWorkspaceRepository) PinBaseCurrency(ctx context.Context, userID string, currency string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO workspace_profiles (user_id, base_currency, updated_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET base_currency = EXCLUDED.base_currency, updated_at = EXCLUDED.updated_at
+		 WHERE workspace_profiles.base_currency IS NULL`,
+		userID, currency, time.Now().UTC())
+	return err
+}
+
+func (r *postgresWorkspaceRepository) SetLogo(ctx context.Context, userID string, logo *models.WorkspaceLogo) error {
+	var key, contentType interface{}
+	var size, width, height interface{}
+	if logo != nil {
+		key, contentType = logo.StorageKey, logo.ContentType
+		size, width, height = logo.SizeBytes, logo.Width, logo.Height
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO workspace_profiles (user_id, logo_key, logo_content_type, logo_size_bytes, logo_width,
+		 logo_height, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (user_id) DO UPDATE SET logo_key = EXCLUDED.logo_key,
+		 logo_content_type = EXCLUDED.logo_content_type, logo_size_bytes = EXCLUDED.logo_size_bytes,
+		 logo_width = EXCLUDED.logo_width, logo_height = EXCLUDED.logo_height, updated_at = EXCLUDED.updated_at`,
+		userID, key, contentType, size, width, height, time.Now().UTC())
+	return err
+}
+
+func (r *postgresWorkspaceRepository) ListTemplates(ctx context.Context, userID string) ([]models.WorkspaceTemplate, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, kind, content, updated_at FROM workspace_templates WHERE user_id = $1 ORDER BY kind`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.WorkspaceTemplate
+	for rows.Next() {
+		var template models.WorkspaceTemplate
+		if err := rows.Scan(&template.UserID, &template.Kind, &template.Content, &template.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (r *postgresWorkspaceRepository) GetTemplate(ctx context.Context, userID string, kind models.TemplateKind) (*models.WorkspaceTemplate, error) {
+	var template models.WorkspaceTemplate
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id, kind, content, updated_at FROM workspace_templates WHERE user_id = $1 AND kind = $2`,
+		userID, kind).Scan(&template.UserID, &template.Kind, &template.Content, &template.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *postgresWorkspaceRepository) SaveTemplate(ctx context.Context, template *models.WorkspaceTemplate) error {
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO workspace_templates (user_id, kind, content, updated_at)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id, kind) DO UPDATE SET content = EXCLUDED.content, updated_at = EXCLUDED.updated_at`,
+		template.UserID, template.Kind, template.Content, now)
+	if err != nil {
+		return err
+	}
+
+	template.UpdatedAt = now
+	return nil
+}
+
+func (r *postgresWorkspaceRepository) DeleteTemplate(ctx context.Context, userID string, kind models.TemplateKind) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM workspace_templates WHERE user_id = $1 AND kind = $2`,
+		userID, kind)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
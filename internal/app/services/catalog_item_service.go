@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+var ErrCatalogItemNotFound = errors.New("catalog item not found")
+
+type CatalogItemService struct {
+	items    repositories.CatalogItemRepository
+	taxCodes repositories.TaxCodeRepository
+}
+
+// CatalogItemInput describes a catalog item. Unit defaults to "piece".
+type CatalogItemInput struct {
+	Name        string                    `json:"name"`
+	Description *string                   `json:"description,omitempty"`
+	SKU         *string                   `json:"sku,omitempty"`
+	Unit        models.CatalogUnit        `json:"unit,omitempty"`
+	Prices      []models.CatalogItemPrice `json:"prices"`
+	TaxCodeID   *string                   `json:"tax_code_id,omitempty"`
+}
+
+type CatalogItemFilters struct {
+	Search *string
+}
+
+func NewCatalogItemService(itemRepo repositories.CatalogItemRepository, taxCodeRepo repositories.TaxCodeRepository) *CatalogItemService {
+	return &CatalogItemService{
+		items:    itemRepo,
+		taxCodes: taxCodeRepo,
+	}
+}
+
+func (s *CatalogItemService) List(ctx context.Context, userID string, filters CatalogItemFilters) ([]models.CatalogItem, error) {
+	repoFilters := repositories.CatalogItemFilters{}
+	if filters.Search != nil {
+		if search := strings.TrimSpace(*filters.Search); search != "" {
+			repoFilters.Search = &search
+		}
+	}
+	return s.items.List(ctx, userID, repoFilters)
+}
+
+func (s *CatalogItemService) GetByID(ctx context.Context, id string, userID string) (*models.CatalogItem, error) {
+	item, err := s.items.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrCatalogItemNotFound
+	}
+	return item, nil
+}
+
+func (s *CatalogItemService) Create(ctx context.Context, userID string, input CatalogItemInput) (*models.CatalogItem, error) {
+	item := &models.CatalogItem{UserID: userID}
+	if err := s.applyInput(ctx, item, input); err != nil {
+		return nil, err
+	}
+
+	created, err := s.items.Create(ctx, item)
+	if errors.Is(err, repositories.ErrCatalogItemSKUExists) {
+		return nil, newValidationError(fmt.Sprintf("sku %q is already used by another item", *item.SKU))
+	}
+	return created, err
+}
+
+// Update changes a catalog item for future invoices. Invoice and quote items
+// already billed from it keep the values they copied.
+func (s *CatalogItemService) Update(ctx context.Context, id string, userID string, input CatalogItemInput) (*models.CatalogItem, error) {
+	item, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyInput(ctx, item, input); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.items.Update(ctx, item)
+	if errors.Is(err, repositories.ErrCatalogItemSKUExists) {
+		return nil, newValidationError(fmt.Sprintf("sku %q is already used by another item", *item.SKU))
+	}
+	return updated, err
+}
+
+// Delete removes a catalog item. Items billed from it keep their values but
+// lose the link, so reports count them with items billed as free text.
+func (s *CatalogItemService) Delete(ctx context.Context, id string, userID string) error {
+	if _, err := s.GetByID(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.items.Delete(ctx, id, userID)
+}
+
+func (s *CatalogItemService) applyInput(ctx context.Context, item *models.CatalogItem, input CatalogItemInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return newValidationError("name is required")
+	}
+	unit, err := normalizeCatalogUnit(input.Unit)
+	if err != nil {
+		return err
+	}
+
+	prices := make([]models.CatalogItemPrice, len(input.Prices))
+	seen := make(map[string]bool, len(input.Prices))
+	for i, price := range input.Prices {
+		currency := strings.ToUpper(strings.TrimSpace(price.Currency))
+		if !currencyCodePattern.MatchString(currency) {
+			return newValidationError(fmt.Sprintf("prices[%d].currency %q is not a three-letter currency code", i, price.Currency))
+		}
+		if seen[currency] {
+			return newValidationError(fmt.Sprintf("prices lists %s more than once", currency))
+		}
+		seen[currency] = true
+		if price.UnitPrice.IsNegative() {
+			return newValidationError(fmt.Sprintf("prices[%d].unit_price cannot be negative", i))
+		}
+		if !price.UnitPrice.Round(maxUnitPriceScale, money.Down).Equal(price.UnitPrice) {
+			return newValidationError(fmt.Sprintf("prices[%d].unit_price %s has more than %d decimal places", i, price.UnitPrice, maxUnitPriceScale))
+		}
+		prices[i] = models.CatalogItemPrice{Currency: currency, UnitPrice: price.UnitPrice}
+	}
+
+	if input.TaxCodeID != nil {
+		taxCode, err := s.taxCodes.GetByID(ctx, *input.TaxCodeID, item.UserID)
+		if err != nil {
+			return err
+		}
+		if taxCode == nil {
+			return newValidationError(fmt.Sprintf("tax code %s not found", *input.TaxCodeID))
+		}
+	}
+
+	item.Name = name
+	item.Description = trimmedOrNil(input.Description)
+	item.SKU = trimmedOrNil(input.SKU)
+	item.Unit = unit
+	item.Prices = prices
+	item.TaxCodeID = input.TaxCodeID
+	return nil
+}
+
+func normalizeCatalogUnit(unit models.CatalogUnit) (models.CatalogUnit, error) {
+	if unit == "" {
+		return models.CatalogUnitPiece, nil
+	}
+	if !validCatalogUnit(unit) {
+		return "", newValidationError(fmt.Sprintf("unit %q is not one of hour, day or piece", unit))
+	}
+	return unit, nil
+}
+
+func validCatalogUnit(unit models.CatalogUnit) bool {
+	switch unit {
+	case models.CatalogUnitHour, models.CatalogUnitDay, models.CatalogUnitPiece:
+		return true
+	}
+	return false
+}
+
+// trimmedOrNil trims value, treating a blank string as unset.
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
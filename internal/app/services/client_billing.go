@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+const (
+	maxPaymentTermsDays = 365
+	maxClientCCEmails   = 5
+	maxNotesFooterSize  = 2000
+	// maxTaxRateScale matches the tax_rate columns.
+	maxTaxRateScale = 2
+)
+
+var (
+	netTermsPattern = regexp.MustCompile(`^net_([0-9]{1,3})$`)
+	languagePattern = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}))?$`)
+)
+
+// ClientBillingInput is a client's billing profile. Each field is a default
+// for the client's new invoices; leaving it out means invoices must set the
+// value themselves.
+type ClientBillingInput struct {
+	PaymentTerms     models.PaymentTerms `json:"payment_terms,omitempty"`
+	DefaultTaxRate   *money.Decimal      `json:"default_tax_rate,omitempty"`
+	DefaultTaxCodeID *string             `json:"default_tax_code_id,omitempty"`
+	Language         *string             `json:"language,omitempty"`
+	CCEmails         []string            `json:"cc_emails,omitempty"`
+	NotesFooter      *string             `json:"notes_footer,omitempty"`
+}
+
+// applyBillingProfile validates input and copies it onto client.
+func (s *ClientService) applyBillingProfile(ctx context.Context, userID string, client *models.Client, input ClientBillingInput) error {
+	if input.PaymentTerms != "" {
+		if _, err := dueDateForTerms(input.PaymentTerms, time.Time{}); err != nil {
+			return err
+		}
+	}
+
+	if input.DefaultTaxRate != nil && input.DefaultTaxCodeID != nil {
+		return newValidationError("set either default_tax_rate or default_tax_code_id, not both")
+	}
+	if input.DefaultTaxRate != nil {
+		if err := validateTaxRate(*input.DefaultTaxRate, "default_tax_rate"); err != nil {
+			return err
+		}
+	}
+	if input.DefaultTaxCodeID != nil {
+		code, err := s.taxCodes.GetByID(ctx, *input.DefaultTaxCodeID, userID)
+		if err != nil {
+			return err
+		}
+		if code == nil {
+			return newValidationError(fmt.Sprintf("tax code %s not found", *input.DefaultTaxCodeID))
+		}
+	}
+
+	language, err := normalizeLanguage(input.Language)
+	if err != nil {
+		return err
+	}
+
+	ccEmails, err := normalizeCCEmails(input.CCEmails)
+	if err != nil {
+		return err
+	}
+
+	var notesFooter *string
+	if input.NotesFooter != nil {
+		footer := strings.TrimSpace(*input.NotesFooter)
+		if len(footer) > maxNotesFooterSize {
+			return newValidationError(fmt.Sprintf("notes_footer must be at most %d characters", maxNotesFooterSize))
+		}
+		if footer != "" {
+			notesFooter = &footer
+		}
+	}
+
+	client.PaymentTerms = input.PaymentTerms
+	client.DefaultTaxRate = input.DefaultTaxRate
+	client.DefaultTaxCodeID = input.DefaultTaxCodeID
+	client.Language = language
+	client.CCEmails = ccEmails
+	client.NotesFooter = notesFooter
+	return nil
+}
+
+// applyClientDefaults fills the fields input leaves out from the client's
+// billing profile, except for tax, which applyClientTax fills once catalog
+// items have contributed their own tax codes.
+func applyClientDefaults(input *CreateInvoiceInput, client *models.Client) error {
+	if input.Currency == "" {
+		input.Currency = client.Currency
+	}
+	if input.DueDate == nil && client.PaymentTerms != "" && !input.IssueDate.IsZero() {
+		dueDate, err := dueDateForTerms(client.PaymentTerms, input.IssueDate)
+		if err != nil {
+			return err
+		}
+		input.DueDate = &dueDate
+	}
+	if input.Notes == nil {
+		input.Notes = client.NotesFooter
+	}
+	if input.Language == nil {
+		input.Language = client.Language
+	}
+	return nil
+}
+
+// applyClientTax fills an omitted tax rate from the client's billing profile:
+// the client's default tax code goes on every item that neither the request
+// nor its catalog item gave a code, or else the default rate applies.
+func applyClientTax(input *CreateInvoiceInput, client *models.Client) {
+	if input.TaxRate != nil {
+		return
+	}
+	switch {
+	case client.DefaultTaxCodeID != nil:
+		items := make([]CreateInvoiceItemInput, len(input.Items))
+		for i, item := range input.Items {
+			if item.TaxCodeID == nil && !item.LateFee {
+				item.TaxCodeID = client.DefaultTaxCodeID
+			}
+			items[i] = item
+		}
+		input.Items = items
+	case client.DefaultTaxRate != nil:
+		input.TaxRate = client.DefaultTaxRate
+	}
+}
+
+// dueDateForTerms returns the date an invoice issued on issueDate falls due
+// under terms.
+func dueDateForTerms(terms models.PaymentTerms, issueDate time.Time) (time.Time, error) {
+	issueDate = dateOnly(issueDate)
+	switch terms {
+	case models.PaymentTermsDueOnReceipt:
+		return issueDate, nil
+	case models.PaymentTermsEndOfMonth:
+		return time.Date(issueDate.Year(), issueDate.Month()+1, 0, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	match := netTermsPattern.FindStringSubmatch(string(terms))
+	if match == nil {
+		return time.Time{}, newValidationError(`payment_terms must be "due_on_receipt", "eom" or "net_N", e.g. "net_30"`)
+	}
+	days, _ := strconv.Atoi(match[1])
+	if days < 1 || days > maxPaymentTermsDays {
+		return time.Time{}, newValidationError(fmt.Sprintf("net payment terms must be between 1 and %d days", maxPaymentTermsDays))
+	}
+	return issueDate.AddDate(0, 0, days), nil
+}
+
+func validateTaxRate(rate money.Decimal, field string) error {
+	if rate.IsNegative() || rate.GreaterThan(money.NewFromInt(100)) {
+		return newValidationError(fmt.Sprintf("%s must be between 0 and 100", field))
+	}
+	if !rate.Round(maxTaxRateScale, money.Down).Equal(rate) {
+		return newValidationError(fmt.Sprintf("%s %s has more than %d decimal places", field, rate, maxTaxRateScale))
+	}
+	return nil
+}
+
+// normalizeLanguage accepts a language code with an optional region, such as
+// "de" or "en-IN", and returns it in its canonical case. Blank means unset.
+func normalizeLanguage(language *string) (*string, error) {
+	if language == nil || strings.TrimSpace(*language) == "" {
+		return nil, nil
+	}
+	match := languagePattern.FindStringSubmatch(strings.TrimSpace(*language))
+	if match == nil {
+		return nil, newValidationError(fmt.Sprintf("language %q must be a language code such as \"en\" or \"en-IN\"", *language))
+	}
+	normalized := strings.ToLower(match[1])
+	if match[2] != "" {
+		normalized += "-" + strings.ToUpper(match[2])
+	}
+	return &normalized, nil
+}
+
+func normalizeCCEmails(input []string) ([]string, error) {
+	emails := make([]string, 0, len(input))
+	seen := make(map[string]bool, len(input))
+	for _, raw := range input {
+		parsed, err := mail.ParseAddress(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, newValidationError(fmt.Sprintf("cc_emails: %q is not a valid email address", raw))
+		}
+		key := strings.ToLower(parsed.Address)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		emails = append(emails, parsed.Address)
+	}
+	if len(emails) > maxClientCCEmails {
+		return nil, newValidationError(fmt.Sprintf("cc_emails may list at most %d addresses", maxClientCCEmails))
+	}
+	return emails, nil
+}
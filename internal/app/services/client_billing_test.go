@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+func TestDueDateForTerms(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		terms   models.PaymentTerms
+		issued  time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{terms: models.PaymentTermsDueOnReceipt, issued: time.Date(2024, time.March, 9, 15, 30, 0, 0, time.UTC), want: date(2024, time.March, 9)},
+		{terms: "net_30", issued: date(2024, time.March, 9), want: date(2024, time.April, 8)},
+		{terms: "net_1", issued: date(2024, time.December, 31), want: date(2025, time.January, 1)},
+		{terms: "net_15", issued: date(2024, time.February, 20), want: date(2024, time.March, 6)},
+		{terms: "net_365", issued: date(2024, time.January, 1), want: date(2024, time.December, 31)},
+		{terms: models.PaymentTermsEndOfMonth, issued: date(2024, time.February, 10), want: date(2024, time.February, 29)},
+		{terms: models.PaymentTermsEndOfMonth, issued: date(2023, time.February, 28), want: date(2023, time.February, 28)},
+		{terms: models.PaymentTermsEndOfMonth, issued: date(2024, time.December, 1), want: date(2024, time.December, 31)},
+		{terms: "net_0", issued: date(2024, time.March, 9), wantErr: true},
+		{terms: "net_366", issued: date(2024, time.March, 9), wantErr: true},
+		{terms: "net_1000", issued: date(2024, time.March, 9), wantErr: true},
+		{terms: "net_-5", issued: date(2024, time.March, 9), wantErr: true},
+		{terms: "net30", issued: date(2024, time.March, 9), wantErr: true},
+		{terms: "", issued: date(2024, time.March, 9), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := dueDateForTerms(tt.terms, tt.issued)
+		if tt.wantErr {
+			if _, ok := AsValidationError(err); !ok {
+				t.Errorf("dueDateForTerms(%q) = %s, %v, want validation error", tt.terms, got, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("dueDateForTerms(%q) returned error: %v", tt.terms, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("dueDateForTerms(%q, %s) = %s, want %s", tt.terms, tt.issued.Format(time.DateOnly), got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+type fakeClientRepository struct {
+	repositories.ClientRepository
+	client *models.Client
+}
+
+func (r fakeClientRepository) GetByID(ctx context.Context, id string, userID string) (*models.Client, error) {
+	if r.client.ID != id || r.client.UserID != userID {
+		return nil, nil
+	}
+	copied := *r.client
+	return &copied, nil
+}
+
+type fakeCatalogItemRepository struct {
+	repositories.CatalogItemRepository
+	items map[string]*models.CatalogItem
+}
+
+func (r fakeCatalogItemRepository) GetByID(ctx context.Context, id string, userID string) (*models.CatalogItem, error) {
+	return r.items[id], nil
+}
+
+type fakeTaxCodeRepository struct {
+	repositories.TaxCodeRepository
+	codes map[string]*models.TaxCode
+}
+
+func (r fakeTaxCodeRepository) GetByID(ctx context.Context, id string, userID string) (*models.TaxCode, error) {
+	return r.codes[id], nil
+}
+
+func TestCreateInvoiceKeepsCatalogTaxCode(t *testing.T) {
+	gst, reduced := "tax-gst", "tax-reduced"
+	catalogID := "catalog-1"
+	svc := &InvoiceService{
+		invoices: newFakeInvoiceRepository(),
+		clients: fakeClientRepository{client: &models.Client{
+			ID: "client-1", UserID: "user-1", Currency: "USD", DefaultTaxCodeID: &gst,
+		}},
+		catalog: fakeCatalogItemRepository{items: map[string]*models.CatalogItem{
+			catalogID: {ID: catalogID, UserID: "user-1", Name: "Books", TaxCodeID: &reduced,
+				Prices: []models.CatalogItemPrice{{Currency: "USD", UnitPrice: dec("100")}}},
+		}},
+		taxCodes: fakeTaxCodeRepository{codes: map[string]*models.TaxCode{
+			gst:     {ID: gst, UserID: "user-1", Code: "GST", Components: []models.TaxComponent{{Name: "GST", Rate: dec("10")}}},
+			reduced: {ID: reduced, UserID: "user-1", Code: "RED", Components: []models.TaxComponent{{Name: "Reduced", Rate: dec("5")}}},
+		}},
+	}
+
+	created, err := svc.Create(context.Background(), "user-1", CreateInvoiceInput{
+		ClientID: "client-1",
+		Items: []CreateInvoiceItemInput{
+			{CatalogItemID: &catalogID, Quantity: dec("1")},
+			{Description: "Consulting", Quantity: dec("1"), UnitPrice: dec("1000")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Items) != 2 {
+		t.Fatalf("created %d items, want 2", len(created.Items))
+	}
+	for i, want := range []string{reduced, gst} {
+		got := created.Items[i].TaxCodeID
+		if got == nil || *got != want {
+			t.Errorf("items[%d].TaxCodeID = %v, want %s", i, got, want)
+		}
+	}
+	assertAmount(t, "tax", created.TaxAmount, "105")
+	assertAmount(t, "total", created.Total, "1205")
+}
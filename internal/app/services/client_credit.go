@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// CreateDepositInput records money received from a client before there is an
+// invoice to pay, such as a deposit or retainer. Currency defaults to the
+// client's currency and EntryDate to today.
+type CreateDepositInput struct {
+	Amount        money.Decimal `json:"amount"`
+	Currency      string        `json:"currency,omitempty"`
+	PaymentMethod *string       `json:"payment_method,omitempty"`
+	EntryDate     *time.Time    `json:"entry_date,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+}
+
+// ListCredits returns the client's credit ledger, newest first.
+func (s *ClientService) ListCredits(ctx context.Context, clientID string, userID string) ([]models.ClientCredit, error) {
+	if _, err := s.ensureClient(ctx, clientID, userID); err != nil {
+		return nil, err
+	}
+	return s.invoices.ListClientCredits(ctx, clientID, userID)
+}
+
+// AddDeposit adds a deposit to the client's credit balance.
+func (s *ClientService) AddDeposit(ctx context.Context, clientID string, userID string, input CreateDepositInput) (*models.ClientCredit, error) {
+	client, err := s.ensureClient(ctx, clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if currency == "" {
+		currency = client.Currency
+	}
+	amount := roundMoney(input.Amount, currency)
+	if !amount.IsPositive() {
+		return nil, newValidationError("amount must be greater than 0")
+	}
+	if input.PaymentMethod != nil && *input.PaymentMethod == models.PaymentMethodClientCredit {
+		return nil, newValidationError(fmt.Sprintf("payment_method %q cannot be used for deposits", models.PaymentMethodClientCredit))
+	}
+
+	entryDate := dateOnly(time.Now().UTC())
+	if input.EntryDate != nil {
+		entryDate = dateOnly(*input.EntryDate)
+	}
+
+	deposit := &models.ClientCredit{
+		UserID:        userID,
+		ClientID:      clientID,
+		Type:          models.ClientCreditDeposit,
+		Amount:        amount,
+		Currency:      currency,
+		PaymentMethod: input.PaymentMethod,
+		Notes:         input.Notes,
+		EntryDate:     entryDate,
+	}
+	if err := s.invoices.CreateClientCredit(ctx, deposit); err != nil {
+		return nil, fmt.Errorf("failed to record deposit: %w", err)
+	}
+	return deposit, nil
+}
+
+func (s *ClientService) ensureClient(ctx context.Context, clientID string, userID string) (*models.Client, error) {
+	client, err := s.clients.GetByID(ctx, clientID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, ErrClientNotFound
+	}
+	return client, nil
+}
+
+// recordClientCredit adds a ledger entry for a payment on invoice. The
+// client must already be locked in tx.
+func recordClientCredit(ctx context.Context, tx repositories.InvoiceRepository, invoice *models.Invoice, payment *models.Payment, creditType models.ClientCreditType, amount money.Decimal, creditNoteID *string) error {
+	credit := &models.ClientCredit{
+		UserID:       invoice.UserID,
+		ClientID:     invoice.ClientID,
+		Type:         creditType,
+		Amount:       amount,
+		Currency:     invoice.Currency,
+		InvoiceID:    &invoice.ID,
+		PaymentID:    &payment.ID,
+		CreditNoteID: creditNoteID,
+		EntryDate:    dateOnly(payment.PaymentDate),
+	}
+	// Only an overpayment brings in new money; the other entries move credit
+	// that is already held.
+	if creditType == models.ClientCreditOverpayment {
+		credit.PaymentMethod = payment.PaymentMethod
+	}
+	if err := tx.CreateClientCredit(ctx, credit); err != nil {
+		return fmt.Errorf("failed to record client credit: %w", err)
+	}
+	return nil
+}
+
+// creditOverpaidBalance moves a negative balance due, money the client paid
+// beyond what the invoice is still worth after creditNote, to the client's
+// credit balance. It is recorded as a negative client_credit payment so the
+// invoice nets to zero.
+func creditOverpaidBalance(ctx context.Context, tx repositories.InvoiceRepository, invoice *models.Invoice, userID string, creditNote *models.CreditNote) error {
+	if _, err := tx.LockClient(ctx, invoice.ClientID, userID); err != nil {
+		return err
+	}
+
+	method := models.PaymentMethodClientCredit
+	note := fmt.Sprintf("Moved to client credit by credit note %s", creditNote.CreditNoteNumber)
+	transfer := &models.Payment{
+		InvoiceID:     invoice.ID,
+		Amount:        invoice.BalanceDue,
+		Currency:      invoice.Currency,
+		PaymentMethod: &method,
+		PaymentDate:   creditNote.IssueDate,
+		Notes:         &note,
+	}
+	if err := tx.CreatePayment(ctx, transfer); err != nil {
+		return fmt.Errorf("failed to record client credit: %w", err)
+	}
+	if err := recordClientCredit(ctx, tx, invoice, transfer, models.ClientCreditCreditNote, invoice.BalanceDue.Neg(), &creditNote.ID); err != nil {
+		return err
+	}
+
+	invoice.AmountPaid = invoice.AmountPaid.Add(invoice.BalanceDue)
+	invoice.BalanceDue = money.Zero
+	return nil
+}
@@ -8,8 +8,12 @@ import (
 	"github.com/nava1525/bilio-backend/internal/app/repositories"
 )
 
+var ErrClientNotFound = errors.New("client not found")
+
 type ClientService struct {
-	clients repositories.ClientRepository
+	clients  repositories.ClientRepository
+	taxCodes repositories.TaxCodeRepository
+	invoices repositories.InvoiceRepository
 }
 
 type CreateClientInput struct {
@@ -20,6 +24,7 @@ type CreateClientInput struct {
 	Address  *string `json:"address,omitempty"`
 	TaxID    *string `json:"tax_id,omitempty"`
 	Currency string  `json:"currency"`
+	ClientBillingInput
 }
 
 type UpdateClientInput struct {
@@ -30,10 +35,11 @@ type UpdateClientInput struct {
 	Address  *string `json:"address,omitempty"`
 	TaxID    *string `json:"tax_id,omitempty"`
 	Currency string  `json:"currency"`
+	ClientBillingInput
 }
 
-func NewClientService(clientRepo repositories.ClientRepository) *ClientService {
-	return &ClientService{clients: clientRepo}
+func NewClientService(clientRepo repositories.ClientRepository, taxCodeRepo repositories.TaxCodeRepository, invoiceRepo repositories.InvoiceRepository) *ClientService {
+	return &ClientService{clients: clientRepo, taxCodes: taxCodeRepo, invoices: invoiceRepo}
 }
 
 func (s *ClientService) List(ctx context.Context, userID string) ([]models.Client, error) {
@@ -46,7 +52,12 @@ func (s *ClientService) GetByID(ctx context.Context, id string, userID string) (
 		return nil, err
 	}
 	if client == nil {
-		return nil, errors.New("client not found")
+		return nil, ErrClientNotFound
+	}
+
+	client.CreditBalances, err = s.invoices.ClientCreditBalances(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 	return client, nil
 }
@@ -69,6 +80,9 @@ func (s *ClientService) Create(ctx context.Context, userID string, input CreateC
 		TaxID:    input.TaxID,
 		Currency: input.Currency,
 	}
+	if err := s.applyBillingProfile(ctx, userID, client, input.ClientBillingInput); err != nil {
+		return nil, err
+	}
 
 	return s.clients.Create(ctx, client)
 }
@@ -89,6 +103,9 @@ func (s *ClientService) Update(ctx context.Context, id string, userID string, in
 	client.Address = input.Address
 	client.TaxID = input.TaxID
 	client.Currency = input.Currency
+	if err := s.applyBillingProfile(ctx, userID, client, input.ClientBillingInput); err != nil {
+		return nil, err
+	}
 
 	return s.clients.Update(ctx, client)
 }
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+var ErrCreditNoteNotFound = errors.New("credit note not found")
+
+type CreditNoteService struct {
+	invoices repositories.InvoiceRepository
+}
+
+type CreateCreditNoteInput struct {
+	IssueDate *time.Time `json:"issue_date,omitempty"`
+	Reason    *string    `json:"reason,omitempty"`
+	// Items lists the invoice lines and quantities to credit. When empty,
+	// everything on the invoice that has not been credited yet is credited.
+	Items  []CreditNoteItemInput `json:"items,omitempty"`
+	Refund *RefundInput          `json:"refund,omitempty"`
+}
+
+type CreditNoteItemInput struct {
+	InvoiceItemID string        `json:"invoice_item_id"`
+	Quantity      money.Decimal `json:"quantity"`
+}
+
+// RefundInput pays money back to the client against a credit note.
+type RefundInput struct {
+	Amount        money.Decimal `json:"amount"`
+	PaymentMethod *string       `json:"payment_method,omitempty"`
+	PaymentDate   *time.Time    `json:"payment_date,omitempty"`
+	TransactionID *string       `json:"transaction_id,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+}
+
+type CreditNoteFilters struct {
+	InvoiceID *string
+	FromDate  *time.Time
+	ToDate    *time.Time
+}
+
+func NewCreditNoteService(invoiceRepo repositories.InvoiceRepository) *CreditNoteService {
+	return &CreditNoteService{invoices: invoiceRepo}
+}
+
+func (s *CreditNoteService) List(ctx context.Context, userID string, filters CreditNoteFilters) ([]models.CreditNote, error) {
+	return s.invoices.ListCreditNotes(ctx, userID, repositories.CreditNoteFilters{
+		InvoiceID: filters.InvoiceID,
+		FromDate:  filters.FromDate,
+		ToDate:    filters.ToDate,
+	})
+}
+
+func (s *CreditNoteService) GetByID(ctx context.Context, id string, userID string) (*models.CreditNote, error) {
+	creditNote, err := s.invoices.GetCreditNote(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if creditNote == nil {
+		return nil, ErrCreditNoteNotFound
+	}
+
+	items, err := s.invoices.GetCreditNoteItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	creditNote.Items = items
+
+	payments, err := s.invoices.GetPayments(ctx, creditNote.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range payments {
+		if payments[i].CreditNoteID != nil && *payments[i].CreditNoteID == id {
+			creditNote.Refund = &payments[i]
+			break
+		}
+	}
+
+	return creditNote, nil
+}
+
+// Create issues a credit note against an invoice and, when requested, records
+// the refund as a negative payment. The invoice is locked for the duration so
+// concurrent credits and payments cannot over-credit it. An unpaid balance
+// that the credit clears marks the invoice as paid, and whatever the client
+// has paid beyond the credited value, less the refund, becomes client credit.
+func (s *CreditNoteService) Create(ctx context.Context, invoiceID string, userID string, input CreateCreditNoteInput) (*models.CreditNote, error) {
+	var created *models.CreditNote
+	err := s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		var err error
+		created, err = s.create(ctx, tx, invoiceID, userID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *CreditNoteService) create(ctx context.Context, tx repositories.InvoiceRepository, invoiceID string, userID string, input CreateCreditNoteInput) (*models.CreditNote, error) {
+	invoice, err := tx.GetByIDForUpdate(ctx, invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	switch invoice.Status {
+	case models.InvoiceStatusPending, models.InvoiceStatusPartiallyPaid, models.InvoiceStatusPaid, models.InvoiceStatusOverdue:
+	default:
+		return nil, newValidationError(fmt.Sprintf("cannot credit a %s invoice", invoice.Status))
+	}
+
+	items, err := tx.GetItems(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	credited, err := tx.CreditedQuantities(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	prior, err := tx.ListCreditNotes(ctx, userID, repositories.CreditNoteFilters{InvoiceID: &invoiceID})
+	if err != nil {
+		return nil, err
+	}
+
+	creditNote, lines, err := priceCreditNote(invoice, items, credited, prior, input.Items)
+	if err != nil {
+		return nil, err
+	}
+	creditNote.IssueDate = time.Now().UTC()
+	if input.IssueDate != nil {
+		creditNote.IssueDate = *input.IssueDate
+	}
+	creditNote.Reason = input.Reason
+
+	var refund *models.Payment
+	if input.Refund != nil {
+		amount := roundMoney(input.Refund.Amount, invoice.Currency)
+		if !amount.IsPositive() {
+			return nil, newValidationError("refund amount must be greater than 0")
+		}
+		if amount.GreaterThan(creditNote.Total) {
+			return nil, newValidationError(fmt.Sprintf("refund of %s exceeds the credit note total of %s",
+				formatMoney(amount, invoice.Currency), formatMoney(creditNote.Total, invoice.Currency)))
+		}
+		if amount.GreaterThan(invoice.AmountPaid) {
+			return nil, newValidationError(fmt.Sprintf("refund of %s exceeds the %s paid on the invoice",
+				formatMoney(amount, invoice.Currency), formatMoney(invoice.AmountPaid, invoice.Currency)))
+		}
+		refund = &models.Payment{
+			InvoiceID:     invoice.ID,
+			Amount:        amount.Neg(),
+			Currency:      invoice.Currency,
+			PaymentMethod: input.Refund.PaymentMethod,
+			PaymentDate:   time.Now().UTC(),
+			TransactionID: input.Refund.TransactionID,
+			Notes:         input.Refund.Notes,
+		}
+		if input.Refund.PaymentDate != nil {
+			refund.PaymentDate = *input.Refund.PaymentDate
+		}
+	}
+
+	if err := tx.CreateCreditNote(ctx, creditNote); err != nil {
+		return nil, fmt.Errorf("failed to create credit note: %w", err)
+	}
+	for i := range lines {
+		lines[i].CreditNoteID = creditNote.ID
+		if err := tx.CreateCreditNoteItem(ctx, &lines[i]); err != nil {
+			return nil, fmt.Errorf("failed to create credit note item: %w", err)
+		}
+	}
+	creditNote.Items = lines
+
+	if refund != nil {
+		refund.CreditNoteID = &creditNote.ID
+		if err := tx.CreatePayment(ctx, refund); err != nil {
+			return nil, fmt.Errorf("failed to record refund: %w", err)
+		}
+		creditNote.Refund = refund
+		creditNote.RefundedAmount = refund.Amount.Neg()
+		invoice.AmountPaid = invoice.AmountPaid.Add(refund.Amount)
+	}
+
+	invoice.CreditedAmount = invoice.CreditedAmount.Add(creditNote.Total)
+	invoice.BalanceDue = invoice.Total.Sub(invoice.AmountPaid).Sub(invoice.CreditedAmount)
+	if invoice.BalanceDue.IsNegative() {
+		if err := creditOverpaidBalance(ctx, tx, invoice, userID, creditNote); err != nil {
+			return nil, err
+		}
+	}
+	if !invoice.BalanceDue.IsPositive() && invoice.Status != models.InvoiceStatusPaid {
+		if err := transitionInvoice(invoice, models.InvoiceStatusPaid); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Update(ctx, invoice); err != nil {
+			return nil, err
+		}
+	}
+
+	return creditNote, nil
+}
+
+// priceCreditNote works out the lines and totals of a credit note for the
+// requested invoice lines, or for everything not yet credited when requests
+// is empty.
+//
+// Each credited line takes its share of the original line's amount and of
+// any pre-tax invoice discount, and is taxed with the components the line
+// was invoiced with. When the credit leaves nothing on the invoice
+// uncredited, the totals are instead taken as the invoice's totals minus the
+// earlier credit notes, so rounding never leaves a residual balance.
+func priceCreditNote(invoice *models.Invoice, items []models.InvoiceItem, credited map[string]money.Decimal, prior []models.CreditNote, requests []CreditNoteItemInput) (*models.CreditNote, []models.CreditNoteItem, error) {
+	currency := invoice.Currency
+	remaining := make(map[string]money.Decimal, len(items))
+	for _, item := range items {
+		remaining[item.ID] = item.Quantity.Sub(credited[item.ID])
+	}
+
+	if len(requests) == 0 {
+		for _, item := range items {
+			if remaining[item.ID].IsPositive() {
+				requests = append(requests, CreditNoteItemInput{InvoiceItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		if len(requests) == 0 {
+			return nil, nil, newValidationError("invoice has already been fully credited")
+		}
+	}
+
+	// Rebuild each line's taxable amount exactly as pricing did, so credits
+	// of discounted invoices reverse the tax that was actually charged. Late
+	// fees take no share of the discount and are never taxed.
+	index := make(map[string]int, len(items))
+	var billed []int
+	var billedAmounts []money.Decimal
+	billedSubtotal := money.Zero
+	for i, item := range items {
+		index[item.ID] = i
+		if !item.LateFee {
+			billed = append(billed, i)
+			billedAmounts = append(billedAmounts, item.Amount)
+			billedSubtotal = billedSubtotal.Add(item.Amount)
+		}
+	}
+	shares := make([]money.Decimal, len(items))
+	if invoice.DiscountTiming != models.DiscountTimingPostTax {
+		for j, share := range allocateDiscount(invoice.DiscountAmount, billedAmounts, currency) {
+			shares[billed[j]] = share
+		}
+	}
+
+	lines := make([]models.CreditNoteItem, 0, len(requests))
+	breakdown := newTaxBreakdown()
+	subtotal := money.Zero
+	billedCredited := money.Zero
+	preTaxDiscount := money.Zero
+	seen := make(map[string]bool, len(requests))
+
+	for n, request := range requests {
+		i, ok := index[request.InvoiceItemID]
+		if !ok {
+			return nil, nil, newValidationError(fmt.Sprintf("items[%d]: invoice item %s not found", n, request.InvoiceItemID))
+		}
+		if seen[request.InvoiceItemID] {
+			return nil, nil, newValidationError(fmt.Sprintf("items[%d]: invoice item %s is listed more than once", n, request.InvoiceItemID))
+		}
+		seen[request.InvoiceItemID] = true
+
+		item := items[i]
+		if !request.Quantity.IsPositive() {
+			return nil, nil, newValidationError(fmt.Sprintf("items[%d].quantity must be greater than 0", n))
+		}
+		if request.Quantity.GreaterThan(remaining[item.ID]) {
+			return nil, nil, newValidationError(fmt.Sprintf("items[%d].quantity exceeds the %s not yet credited",
+				n, formatQuantity(remaining[item.ID])))
+		}
+		remaining[item.ID] = remaining[item.ID].Sub(request.Quantity)
+
+		places := money.MinorUnits(currency)
+		amount := item.Amount.Mul(request.Quantity).Div(item.Quantity, places, money.HalfUp)
+		taxable := item.Amount.Sub(shares[i]).Mul(request.Quantity).Div(item.Quantity, places, money.HalfUp)
+
+		line := models.CreditNoteItem{
+			InvoiceItemID: &items[i].ID,
+			Description:   item.Description,
+			Quantity:      request.Quantity,
+			UnitPrice:     item.UnitPrice,
+			Amount:        amount,
+		}
+		if !item.LateFee {
+			for _, tax := range item.Taxes {
+				line.Taxes = append(line.Taxes, models.InvoiceItemTax{
+					Name:   tax.Name,
+					Rate:   tax.Rate,
+					Amount: roundMoney(percentOf(taxable, tax.Rate), currency),
+				})
+				breakdown.add(models.TaxComponent{Name: tax.Name, Rate: tax.Rate}, taxable)
+			}
+			billedCredited = billedCredited.Add(amount)
+			preTaxDiscount = preTaxDiscount.Add(amount.Sub(taxable))
+		}
+		lines = append(lines, line)
+		subtotal = subtotal.Add(amount)
+	}
+
+	creditNote := &models.CreditNote{
+		UserID:       invoice.UserID,
+		InvoiceID:    invoice.ID,
+		ClientID:     invoice.ClientID,
+		Currency:     currency,
+		Subtotal:     subtotal,
+		TaxBreakdown: breakdown.lines(currency),
+	}
+	creditNote.TaxAmount = sumTaxLines(creditNote.TaxBreakdown)
+	if invoice.DiscountTiming == models.DiscountTimingPostTax {
+		taxed := billedSubtotal.Add(invoice.TaxAmount)
+		if taxed.IsPositive() {
+			creditNote.DiscountAmount = invoice.DiscountAmount.Mul(billedCredited.Add(creditNote.TaxAmount)).
+				Div(taxed, money.MinorUnits(currency), money.HalfUp)
+		}
+	} else {
+		creditNote.DiscountAmount = preTaxDiscount
+	}
+
+	fullyCredited := true
+	for _, qty := range remaining {
+		if qty.IsPositive() {
+			fullyCredited = false
+			break
+		}
+	}
+	if fullyCredited {
+		creditRemainder(creditNote, invoice, prior)
+	}
+
+	creditNote.Total = creditNote.Subtotal.Sub(creditNote.DiscountAmount).Add(creditNote.TaxAmount)
+	uncredited := invoice.Total.Sub(invoice.CreditedAmount)
+	if !creditNote.Total.IsPositive() {
+		return nil, nil, newValidationError("credit note total must be greater than 0")
+	}
+	if creditNote.Total.GreaterThan(uncredited) {
+		return nil, nil, newValidationError(fmt.Sprintf("credit of %s exceeds the %s not yet credited",
+			formatMoney(creditNote.Total, currency), formatMoney(uncredited, currency)))
+	}
+
+	return creditNote, lines, nil
+}
+
+// creditRemainder sets the credit note's figures to whatever of the invoice
+// the earlier credit notes have not already reversed.
+func creditRemainder(creditNote *models.CreditNote, invoice *models.Invoice, prior []models.CreditNote) {
+	creditNote.Subtotal = invoice.Subtotal
+	creditNote.DiscountAmount = invoice.DiscountAmount
+	lines := append([]models.InvoiceTaxLine(nil), invoice.TaxBreakdown...)
+
+	for _, cn := range prior {
+		creditNote.Subtotal = creditNote.Subtotal.Sub(cn.Subtotal)
+		creditNote.DiscountAmount = creditNote.DiscountAmount.Sub(cn.DiscountAmount)
+		for _, credit := range cn.TaxBreakdown {
+			for i := range lines {
+				if lines[i].Name == credit.Name && lines[i].Rate.Equal(credit.Rate) {
+					lines[i].TaxableAmount = lines[i].TaxableAmount.Sub(credit.TaxableAmount)
+					lines[i].Amount = lines[i].Amount.Sub(credit.Amount)
+					break
+				}
+			}
+		}
+	}
+
+	creditNote.TaxBreakdown = lines
+	creditNote.TaxAmount = sumTaxLines(lines)
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// creditTestInvoice is priced so that line taxes and the pre-tax discount
+// shares do not divide evenly.
+func creditTestInvoice(t *testing.T, timing models.DiscountTiming) (*models.Invoice, []models.InvoiceItem) {
+	t.Helper()
+	invoice := &models.Invoice{
+		Currency:       "USD",
+		TaxRate:        dec("7.25"),
+		Discount:       &models.Discount{Type: models.DiscountTypeFixed, Value: dec("10")},
+		DiscountTiming: timing,
+	}
+	items := pricedInvoice(t, invoice, []CreateInvoiceItemInput{
+		{Description: "Design", Quantity: dec("3"), UnitPrice: dec("33.33")},
+		{Description: "Build", Quantity: dec("7"), UnitPrice: dec("14.29")},
+		{Description: "Support", Quantity: dec("1"), UnitPrice: dec("0.05")},
+	})
+	return invoice, items
+}
+
+func assertReverses(t *testing.T, name string, creditNote *models.CreditNote, invoice *models.Invoice) {
+	t.Helper()
+	assertAmount(t, name+" subtotal", creditNote.Subtotal, invoice.Subtotal.String())
+	assertAmount(t, name+" discount", creditNote.DiscountAmount, invoice.DiscountAmount.String())
+	assertAmount(t, name+" tax", creditNote.TaxAmount, invoice.TaxAmount.String())
+	assertAmount(t, name+" total", creditNote.Total, invoice.Total.String())
+	if len(creditNote.TaxBreakdown) != len(invoice.TaxBreakdown) {
+		t.Fatalf("%s: tax breakdown = %+v, want %+v", name, creditNote.TaxBreakdown, invoice.TaxBreakdown)
+	}
+	for i, line := range invoice.TaxBreakdown {
+		assertAmount(t, name+" breakdown "+line.Name+" taxable", creditNote.TaxBreakdown[i].TaxableAmount, line.TaxableAmount.String())
+		assertAmount(t, name+" breakdown "+line.Name+" amount", creditNote.TaxBreakdown[i].Amount, line.Amount.String())
+	}
+}
+
+func TestPriceCreditNoteFullCredit(t *testing.T) {
+	for _, timing := range []models.DiscountTiming{models.DiscountTimingPreTax, models.DiscountTimingPostTax} {
+		invoice, items := creditTestInvoice(t, timing)
+		creditNote, lines, err := priceCreditNote(invoice, items, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", timing, err)
+		}
+		if len(lines) != len(items) {
+			t.Errorf("%s: credited %d lines, want %d", timing, len(lines), len(items))
+		}
+		assertReverses(t, string(timing), creditNote, invoice)
+	}
+}
+
+func TestPriceCreditNotePartialThenRest(t *testing.T) {
+	invoice, items := creditTestInvoice(t, models.DiscountTimingPreTax)
+
+	first, _, err := priceCreditNote(invoice, items, nil, nil, []CreditNoteItemInput{
+		{InvoiceItemID: items[0].ID, Quantity: dec("1")},
+		{InvoiceItemID: items[1].ID, Quantity: dec("7")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Total.IsPositive() || !first.Total.LessThan(invoice.Total) {
+		t.Fatalf("partial credit total = %s, want between 0 and %s", first.Total, invoice.Total)
+	}
+
+	invoice.CreditedAmount = first.Total
+	credited := map[string]money.Decimal{items[0].ID: dec("1"), items[1].ID: dec("7")}
+	rest, _, err := priceCreditNote(invoice, items, credited, []models.CreditNote{*first}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := &models.CreditNote{
+		Subtotal:       first.Subtotal.Add(rest.Subtotal),
+		DiscountAmount: first.DiscountAmount.Add(rest.DiscountAmount),
+		TaxAmount:      first.TaxAmount.Add(rest.TaxAmount),
+		Total:          first.Total.Add(rest.Total),
+	}
+	for i := range rest.TaxBreakdown {
+		line := rest.TaxBreakdown[i]
+		line.TaxableAmount = line.TaxableAmount.Add(first.TaxBreakdown[i].TaxableAmount)
+		line.Amount = line.Amount.Add(first.TaxBreakdown[i].Amount)
+		sum.TaxBreakdown = append(sum.TaxBreakdown, line)
+	}
+	assertReverses(t, "partial + rest", sum, invoice)
+
+	invoice.CreditedAmount = invoice.Total
+	credited[items[0].ID] = dec("3")
+	credited[items[2].ID] = dec("1")
+	if _, _, err := priceCreditNote(invoice, items, credited, []models.CreditNote{*first, *rest}, nil); err == nil {
+		t.Error("crediting a fully credited invoice succeeded")
+	}
+}
+
+func TestPriceCreditNoteRejectsInvalidLines(t *testing.T) {
+	invoice, items := creditTestInvoice(t, models.DiscountTimingPreTax)
+	tests := []struct {
+		name     string
+		requests []CreditNoteItemInput
+	}{
+		{"unknown item", []CreditNoteItemInput{{InvoiceItemID: "missing", Quantity: dec("1")}}},
+		{"zero quantity", []CreditNoteItemInput{{InvoiceItemID: items[0].ID, Quantity: dec("0")}}},
+		{"over-credit", []CreditNoteItemInput{{InvoiceItemID: items[0].ID, Quantity: dec("4")}}},
+		{"duplicate", []CreditNoteItemInput{
+			{InvoiceItemID: items[0].ID, Quantity: dec("1")},
+			{InvoiceItemID: items[0].ID, Quantity: dec("1")},
+		}},
+	}
+	for _, tt := range tests {
+		_, _, err := priceCreditNote(invoice, items, nil, nil, tt.requests)
+		if _, ok := AsValidationError(err); !ok {
+			t.Errorf("%s: priceCreditNote error = %v, want validation error", tt.name, err)
+		}
+	}
+}
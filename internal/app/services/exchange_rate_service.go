@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/fxrates"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+const (
+	// DefaultBaseCurrency is the base currency of workspaces that have
+	// neither chosen one nor issued any invoices.
+	DefaultBaseCurrency = "USD"
+	// MaxExchangeRateFileSize is the largest rate file accepted for import.
+	MaxExchangeRateFileSize = 2 << 20
+	// defaultExchangeRateSyncDays is how far back a sync without a from_date
+	// reaches.
+	defaultExchangeRateSyncDays = 30
+	// maxExchangeRateSyncDays bounds the range fetched from the provider in
+	// one sync.
+	maxExchangeRateSyncDays = 366
+)
+
+var (
+	ErrExchangeRateNotFound     = errors.New("exchange rate not found")
+	ErrExchangeRateFileTooLarge = fmt.Errorf("rate file exceeds the %d MB limit", MaxExchangeRateFileSize>>20)
+
+	currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// MissingExchangeRateError reports an amount that could not be converted to
+// the base currency because no rate is stored for its currency on or before
+// its date.
+type MissingExchangeRateError struct {
+	Currency     string
+	BaseCurrency string
+	Date         time.Time
+}
+
+func (e MissingExchangeRateError) Error() string {
+	return fmt.Sprintf("no exchange rate from %s to %s on or before %s; import rates or sync them from the provider",
+		e.Currency, e.BaseCurrency, e.Date.Format(fxrates.DateLayout))
+}
+
+func AsMissingExchangeRateError(err error) (MissingExchangeRateError, bool) {
+	var rErr MissingExchangeRateError
+	if errors.As(err, &rErr) {
+		return rErr, true
+	}
+	return MissingExchangeRateError{}, false
+}
+
+// MissingExchangeRate lists the invoices and expenses in one currency that a
+// report left out of its totals because no rate to the base currency was
+// stored on or before their dates. FromDate and ToDate span the dates that
+// need a rate.
+type MissingExchangeRate struct {
+	Currency string    `json:"currency"`
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+	Invoices int       `json:"invoices"`
+	Expenses int       `json:"expenses"`
+}
+
+type ExchangeRateService struct {
+	rates      repositories.ExchangeRateRepository
+	workspaces repositories.WorkspaceRepository
+	provider   fxrates.Provider
+}
+
+type ExchangeRateFilters struct {
+	Currency *string
+	FromDate *time.Time
+	ToDate   *time.Time
+}
+
+// ImportExchangeRatesInput is a CSV file of rates against the workspace's
+// base currency, with date, currency and rate columns.
+type ImportExchangeRatesInput struct {
+	Content io.Reader
+}
+
+// SyncExchangeRatesInput selects the rates fetched from the provider. The
+// range defaults to the last 30 days and Currencies to every currency the
+// provider has.
+type SyncExchangeRatesInput struct {
+	FromDate   *time.Time `json:"from_date,omitempty"`
+	ToDate     *time.Time `json:"to_date,omitempty"`
+	Currencies []string   `json:"currencies,omitempty"`
+}
+
+// ExchangeRateImport summarises rates loaded by an import or sync.
+type ExchangeRateImport struct {
+	BaseCurrency string `json:"base_currency"`
+	Imported     int    `json:"imported"`
+}
+
+func NewExchangeRateService(rateRepo repositories.ExchangeRateRepository, workspaceRepo repositories.WorkspaceRepository, provider fxrates.Provider) *ExchangeRateService {
+	return &ExchangeRateService{
+		rates:      rateRepo,
+		workspaces: workspaceRepo,
+		provider:   provider,
+	}
+}
+
+// List returns the stored rates against the workspace's base currency,
+// newest first.
+func (s *ExchangeRateService) List(ctx context.Context, userID string, filters ExchangeRateFilters) ([]models.ExchangeRate, error) {
+	base, err := workspaceBaseCurrency(ctx, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+	repoFilters := repositories.ExchangeRateFilters{
+		BaseCurrency: base,
+		FromDate:     filters.FromDate,
+		ToDate:       filters.ToDate,
+	}
+	if filters.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*filters.Currency))
+		repoFilters.Currency = &currency
+	}
+	return s.rates.List(ctx, userID, repoFilters)
+}
+
+// Import stores the rates in a CSV file, replacing stored rates for the same
+// currency and date.
+func (s *ExchangeRateService) Import(ctx context.Context, userID string, input ImportExchangeRatesInput) (*ExchangeRateImport, error) {
+	base, err := workspaceBaseCurrency(ctx, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(input.Content, MaxExchangeRateFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read rate file: %w", err)
+	}
+	if len(content) > MaxExchangeRateFileSize {
+		return nil, ErrExchangeRateFileTooLarge
+	}
+	parsed, err := fxrates.ParseCSV(strings.NewReader(string(content)))
+	if err != nil {
+		return nil, newValidationError(err.Error())
+	}
+	if len(parsed) == 0 {
+		return nil, newValidationError("rate file has no rates")
+	}
+	for _, rate := range parsed {
+		if rate.Currency == base {
+			return nil, newValidationError(fmt.Sprintf("rate file lists %s, the base currency; rates are the value of one unit of each other currency in %s", base, base))
+		}
+	}
+
+	return s.save(ctx, userID, base, parsed, models.ExchangeRateSourceUpload)
+}
+
+// Sync fetches rates from the configured provider and stores them.
+func (s *ExchangeRateService) Sync(ctx context.Context, userID string, input SyncExchangeRatesInput) (*ExchangeRateImport, error) {
+	if s.provider == nil {
+		return nil, fmt.Errorf("exchange rate provider not configured")
+	}
+	base, err := workspaceBaseCurrency(ctx, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	to := dateOnly(time.Now().UTC())
+	if input.ToDate != nil {
+		to = dateOnly(*input.ToDate)
+	}
+	from := to.AddDate(0, 0, -defaultExchangeRateSyncDays)
+	if input.FromDate != nil {
+		from = dateOnly(*input.FromDate)
+	}
+	if from.After(to) {
+		return nil, newValidationError("from_date must not be after to_date")
+	}
+	if to.Sub(from) > maxExchangeRateSyncDays*24*time.Hour {
+		return nil, newValidationError(fmt.Sprintf("a sync can cover at most %d days", maxExchangeRateSyncDays))
+	}
+
+	wanted := make(map[string]bool, len(input.Currencies))
+	for _, currency := range input.Currencies {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if !currencyCodePattern.MatchString(currency) {
+			return nil, newValidationError(fmt.Sprintf("currency %q is not a three-letter code", currency))
+		}
+		wanted[currency] = true
+	}
+
+	fetched, err := s.provider.Rates(ctx, base, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("fetch exchange rates: %w", err)
+	}
+	rates := fetched[:0]
+	for _, rate := range fetched {
+		if len(wanted) == 0 || wanted[rate.Currency] {
+			rates = append(rates, rate)
+		}
+	}
+
+	return s.save(ctx, userID, base, rates, models.ExchangeRateSourceProvider)
+}
+
+func (s *ExchangeRateService) save(ctx context.Context, userID string, base string, parsed []fxrates.Rate, source models.ExchangeRateSource) (*ExchangeRateImport, error) {
+	rates := make([]models.ExchangeRate, len(parsed))
+	for i, rate := range parsed {
+		rates[i] = models.ExchangeRate{
+			UserID:       userID,
+			Currency:     rate.Currency,
+			BaseCurrency: base,
+			RateDate:     rate.Date,
+			Rate:         rate.Rate,
+			Source:       source,
+		}
+	}
+	if len(rates) > 0 {
+		// The rates are only usable against this base, so a workspace still
+		// on the invoice currency default keeps it from now on.
+		if err := s.workspaces.PinBaseCurrency(ctx, userID, base); err != nil {
+			return nil, fmt.Errorf("failed to save base currency: %w", err)
+		}
+		if err := s.rates.Save(ctx, rates); err != nil {
+			return nil, fmt.Errorf("failed to save exchange rates: %w", err)
+		}
+	}
+	return &ExchangeRateImport{BaseCurrency: base, Imported: len(rates)}, nil
+}
+
+func (s *ExchangeRateService) Delete(ctx context.Context, id string, userID string) error {
+	deleted, err := s.rates.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrExchangeRateNotFound
+	}
+	return nil
+}
+
+// normalizeBaseCurrency checks a workspace base currency. Blank means none
+// was given.
+func normalizeBaseCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return "", nil
+	}
+	if !currencyCodePattern.MatchString(currency) {
+		return "", newValidationError(fmt.Sprintf("base_currency %q is not a three-letter currency code", currency))
+	}
+	return currency, nil
+}
+
+// workspaceBaseCurrency returns the base currency the workspace chose, or
+// defaultBaseCurrency until it chooses one.
+func workspaceBaseCurrency(ctx context.Context, workspaces repositories.WorkspaceRepository, userID string) (string, error) {
+	profile, err := workspaces.GetProfile(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if profile == nil || profile.BaseCurrency == "" {
+		return defaultBaseCurrency(ctx, workspaces, userID)
+	}
+	return profile.BaseCurrency, nil
+}
+
+// defaultBaseCurrency is the currency most of the workspace's invoices are
+// in, or DefaultBaseCurrency before it has any.
+func defaultBaseCurrency(ctx context.Context, workspaces repositories.WorkspaceRepository, userID string) (string, error) {
+	currency, err := workspaces.InvoiceCurrency(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if currency == "" {
+		return DefaultBaseCurrency, nil
+	}
+	return currency, nil
+}
+
+// exchangeRates converts amounts into a workspace's base currency with the
+// latest rate on or before each amount's date.
+type exchangeRates struct {
+	base       string
+	byCurrency map[string][]models.ExchangeRate // oldest first
+	missing    map[string]*MissingExchangeRate
+}
+
+func loadExchangeRates(ctx context.Context, repo repositories.ExchangeRateRepository, workspaces repositories.WorkspaceRepository, userID string) (*exchangeRates, error) {
+	base, err := workspaceBaseCurrency(ctx, workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := repo.List(ctx, userID, repositories.ExchangeRateFilters{BaseCurrency: base})
+	if err != nil {
+		return nil, err
+	}
+
+	table := &exchangeRates{
+		base:       base,
+		byCurrency: make(map[string][]models.ExchangeRate),
+		missing:    make(map[string]*MissingExchangeRate),
+	}
+	for _, rate := range rates {
+		table.byCurrency[rate.Currency] = append(table.byCurrency[rate.Currency], rate)
+	}
+	for _, list := range table.byCurrency {
+		sort.Slice(list, func(i, j int) bool { return list[i].RateDate.Before(list[j].RateDate) })
+	}
+	return table, nil
+}
+
+// rate returns the value of one unit of currency in the base currency on
+// date.
+func (t *exchangeRates) rate(currency string, date time.Time) (money.Decimal, error) {
+	currency = strings.ToUpper(currency)
+	if currency == t.base {
+		return money.NewFromInt(1), nil
+	}
+	list := t.byCurrency[currency]
+	day := dateOnly(date)
+	i := sort.Search(len(list), func(i int) bool { return list[i].RateDate.After(day) })
+	if i == 0 {
+		return money.Zero, MissingExchangeRateError{Currency: currency, BaseCurrency: t.base, Date: day}
+	}
+	return list[i-1].Rate, nil
+}
+
+// convert returns amount in the base currency at date's rate, rounded to the
+// base currency's minor unit.
+func (t *exchangeRates) convert(amount money.Decimal, currency string, date time.Time) (money.Decimal, error) {
+	if amount.IsZero() {
+		return money.Zero, nil
+	}
+	rate, err := t.rate(currency, date)
+	if err != nil {
+		return money.Zero, err
+	}
+	return amount.Mul(rate).RoundCurrency(t.base, money.HalfUp), nil
+}
+
+// leaveOut records an invoice, or an expense, that a report leaves out of its
+// totals because err is a missing rate, and reports whether it was. Any other
+// error is the caller's to return.
+func (t *exchangeRates) leaveOut(err error, expense bool) bool {
+	rErr, ok := AsMissingExchangeRateError(err)
+	if !ok {
+		return false
+	}
+	missing := t.missing[rErr.Currency]
+	if missing == nil {
+		missing = &MissingExchangeRate{Currency: rErr.Currency, FromDate: rErr.Date, ToDate: rErr.Date}
+		t.missing[rErr.Currency] = missing
+	}
+	if rErr.Date.Before(missing.FromDate) {
+		missing.FromDate = rErr.Date
+	}
+	if rErr.Date.After(missing.ToDate) {
+		missing.ToDate = rErr.Date
+	}
+	if expense {
+		missing.Expenses++
+	} else {
+		missing.Invoices++
+	}
+	return true
+}
+
+// missingRates returns what leaveOut recorded, by currency.
+func (t *exchangeRates) missingRates() []MissingExchangeRate {
+	missing := make([]MissingExchangeRate, 0, len(t.missing))
+	for _, m := range t.missing {
+		missing = append(missing, *m)
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Currency < missing[j].Currency })
+	return missing
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/nava1525/bilio-backend/internal/app/models"
 	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
 )
 
 type ExpenseService struct {
@@ -15,25 +16,25 @@ type ExpenseService struct {
 }
 
 type CreateExpenseInput struct {
-	ClientID    *string   `json:"client_id,omitempty"`
-	Description string    `json:"description"`
-	Amount      float64   `json:"amount"`
-	Currency    string    `json:"currency"`
-	Category    *string   `json:"category,omitempty"`
-	ExpenseDate time.Time `json:"expense_date"`
-	ReceiptURL  *string   `json:"receipt_url,omitempty"`
-	Notes       *string   `json:"notes,omitempty"`
+	ClientID    *string       `json:"client_id,omitempty"`
+	Description string        `json:"description"`
+	Amount      money.Decimal `json:"amount"`
+	Currency    string        `json:"currency"`
+	Category    *string       `json:"category,omitempty"`
+	ExpenseDate time.Time     `json:"expense_date"`
+	ReceiptURL  *string       `json:"receipt_url,omitempty"`
+	Notes       *string       `json:"notes,omitempty"`
 }
 
 type UpdateExpenseInput struct {
-	ClientID    *string   `json:"client_id,omitempty"`
-	Description string    `json:"description"`
-	Amount      float64   `json:"amount"`
-	Currency    string    `json:"currency"`
-	Category    *string   `json:"category,omitempty"`
-	ExpenseDate time.Time `json:"expense_date"`
-	ReceiptURL  *string   `json:"receipt_url,omitempty"`
-	Notes       *string   `json:"notes,omitempty"`
+	ClientID    *string       `json:"client_id,omitempty"`
+	Description string        `json:"description"`
+	Amount      money.Decimal `json:"amount"`
+	Currency    string        `json:"currency"`
+	Category    *string       `json:"category,omitempty"`
+	ExpenseDate time.Time     `json:"expense_date"`
+	ReceiptURL  *string       `json:"receipt_url,omitempty"`
+	Notes       *string       `json:"notes,omitempty"`
 }
 
 type ExpenseFilters struct {
@@ -75,7 +76,7 @@ func (s *ExpenseService) Create(ctx context.Context, userID string, input Create
 	if input.Description == "" {
 		return nil, errors.New("description is required")
 	}
-	if input.Amount <= 0 {
+	if !input.Amount.IsPositive() {
 		return nil, errors.New("amount must be greater than 0")
 	}
 	if input.Currency == "" {
@@ -97,7 +98,7 @@ func (s *ExpenseService) Create(ctx context.Context, userID string, input Create
 		UserID:      userID,
 		ClientID:    input.ClientID,
 		Description: input.Description,
-		Amount:      input.Amount,
+		Amount:      roundMoney(input.Amount, input.Currency),
 		Currency:    input.Currency,
 		Category:    input.Category,
 		ExpenseDate: input.ExpenseDate,
@@ -129,7 +130,7 @@ func (s *ExpenseService) Update(ctx context.Context, id string, userID string, i
 	}
 
 	expense.Description = input.Description
-	expense.Amount = input.Amount
+	expense.Amount = roundMoney(input.Amount, input.Currency)
 	expense.Currency = input.Currency
 	expense.Category = input.Category
 	expense.ExpenseDate = input.ExpenseDate
@@ -139,4 +140,3 @@ func (s *ExpenseService) Update(ctx context.Context, id string, userID string, i
 
 	return s.expenses.Update(ctx, expense)
 }
-
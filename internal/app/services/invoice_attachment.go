@@ -0,0 +1,270 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/blobstore"
+	"github.com/nava1525/bilio-backend/pkg/mailer"
+)
+
+const (
+	// MaxAttachmentSize is the largest file accepted as an attachment.
+	MaxAttachmentSize = 10 << 20
+	// maxInvoiceAttachmentsSize caps the attachments of one invoice so the
+	// invoice email, once base64 encoded, stays within common 25 MB limits.
+	maxInvoiceAttachmentsSize = 15 << 20
+	maxAttachmentNameLength   = 255
+)
+
+var (
+	ErrAttachmentNotFound        = errors.New("attachment not found")
+	ErrAttachmentTooLarge        = fmt.Errorf("attachment exceeds the %d MB limit", MaxAttachmentSize>>20)
+	ErrUnsupportedAttachmentType = errors.New("unsupported attachment type; upload a PDF, image, text, CSV, Word or Excel file")
+)
+
+// attachmentTypes maps the file extensions accepted as attachments to their
+// content types: documents and images clients can reliably open.
+var attachmentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".txt":  "text/plain",
+	".csv":  "text/csv",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// UploadAttachmentInput is a file uploaded to an invoice. ContentType is the
+// type declared by the uploader; when it is missing or generic the type is
+// taken from the file extension instead.
+type UploadAttachmentInput struct {
+	FileName    string
+	ContentType string
+	Content     io.Reader
+}
+
+func (s *InvoiceService) ListAttachments(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceAttachment, error) {
+	if err := s.ensureInvoiceExists(ctx, invoiceID, userID); err != nil {
+		return nil, err
+	}
+	return s.attachments.ListByInvoice(ctx, invoiceID, userID)
+}
+
+// AddAttachment stores the uploaded file and records it on the invoice.
+func (s *InvoiceService) AddAttachment(ctx context.Context, invoiceID string, userID string, input UploadAttachmentInput) (*models.InvoiceAttachment, error) {
+	if s.blobs == nil {
+		return nil, fmt.Errorf("attachment storage not configured")
+	}
+	if err := s.ensureInvoiceExists(ctx, invoiceID, userID); err != nil {
+		return nil, err
+	}
+
+	fileName := sanitizeAttachmentName(input.FileName)
+	if fileName == "" {
+		return nil, newValidationError("file name is required")
+	}
+	contentType, ok := attachmentContentType(input.ContentType, fileName)
+	if !ok {
+		return nil, ErrUnsupportedAttachmentType
+	}
+
+	content, err := io.ReadAll(io.LimitReader(input.Content, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read attachment: %w", err)
+	}
+	if len(content) == 0 {
+		return nil, newValidationError("attachment is empty")
+	}
+	if len(content) > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	existing, err := s.attachments.ListByInvoice(ctx, invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	total := int64(len(content))
+	for _, attachment := range existing {
+		total += attachment.SizeBytes
+	}
+	if total > maxInvoiceAttachmentsSize {
+		return nil, newValidationError(fmt.Sprintf("attachments on one invoice may total at most %d MB", maxInvoiceAttachmentsSize>>20))
+	}
+
+	attachment := &models.InvoiceAttachment{
+		UserID:      userID,
+		InvoiceID:   invoiceID,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   int64(len(content)),
+		StorageKey:  fmt.Sprintf("invoices/%s/%s", invoiceID, uuid.NewString()),
+	}
+	if err := s.blobs.Put(ctx, attachment.StorageKey, bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("store attachment: %w", err)
+	}
+	if err := s.attachments.Create(ctx, attachment); err != nil {
+		_ = s.blobs.Delete(ctx, attachment.StorageKey)
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// OpenAttachment returns the attachment and its content. The caller must
+// close the reader.
+func (s *InvoiceService) OpenAttachment(ctx context.Context, invoiceID string, attachmentID string, userID string) (*models.InvoiceAttachment, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return nil, nil, fmt.Errorf("attachment storage not configured")
+	}
+	attachment, err := s.attachments.GetByID(ctx, attachmentID, invoiceID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment == nil {
+		return nil, nil, ErrAttachmentNotFound
+	}
+
+	content, err := s.blobs.Open(ctx, attachment.StorageKey)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("open attachment: %w", err)
+	}
+	return attachment, content, nil
+}
+
+func (s *InvoiceService) DeleteAttachment(ctx context.Context, invoiceID string, attachmentID string, userID string) error {
+	attachment, err := s.attachments.GetByID(ctx, attachmentID, invoiceID, userID)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return ErrAttachmentNotFound
+	}
+
+	deleted, err := s.attachments.Delete(ctx, attachmentID, invoiceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	if !deleted {
+		return ErrAttachmentNotFound
+	}
+	s.removeAttachmentFiles(ctx, []models.InvoiceAttachment{*attachment})
+	return nil
+}
+
+// emailAttachments loads the invoice's attachments for sending with the
+// invoice email.
+func (s *InvoiceService) emailAttachments(ctx context.Context, invoice *models.Invoice) ([]mailer.Attachment, error) {
+	if s.blobs == nil {
+		return nil, nil
+	}
+	attachments, err := s.attachments.ListByInvoice(ctx, invoice.ID, invoice.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("load attachments: %w", err)
+	}
+
+	files := make([]mailer.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		content, err := s.readBlob(ctx, attachment.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("load attachment %s: %w", attachment.FileName, err)
+		}
+		files = append(files, mailer.Attachment{
+			Filename:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Content:     content,
+		})
+	}
+	return files, nil
+}
+
+func (s *InvoiceService) readBlob(ctx context.Context, key string) ([]byte, error) {
+	reader, err := s.blobs.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// removeAttachmentFiles deletes stored files whose records are already gone.
+// Failures only leave an unreferenced file behind, so they are ignored.
+func (s *InvoiceService) removeAttachmentFiles(ctx context.Context, attachments []models.InvoiceAttachment) {
+	if s.blobs == nil {
+		return
+	}
+	for _, attachment := range attachments {
+		_ = s.blobs.Delete(ctx, attachment.StorageKey)
+	}
+}
+
+func (s *InvoiceService) ensureInvoiceExists(ctx context.Context, invoiceID string, userID string) error {
+	invoice, err := s.invoices.GetByID(ctx, invoiceID, userID)
+	if err != nil {
+		return err
+	}
+	if invoice == nil {
+		return ErrInvoiceNotFound
+	}
+	return nil
+}
+
+// sanitizeAttachmentName keeps the base name of an uploaded file without
+// control characters or quotes, so it is safe in headers.
+func sanitizeAttachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	if len(name) > maxAttachmentNameLength {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:maxAttachmentNameLength-len(ext)], "") + ext
+	}
+	return name
+}
+
+// attachmentContentType settles the type of an upload. A specific declared
+// type must be one of attachmentTypes; a missing or generic one is taken from
+// the file extension.
+func attachmentContentType(declared string, fileName string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(declared)
+	mediaType = strings.ToLower(mediaType)
+	if err != nil || mediaType == "" || mediaType == "application/octet-stream" {
+		contentType, ok := attachmentTypes[strings.ToLower(filepath.Ext(fileName))]
+		return contentType, ok
+	}
+	for _, contentType := range attachmentTypes {
+		if contentType == mediaType {
+			return mediaType, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"image"
+	_ "image/jpeg" // logo decoding
+	_ "image/png"  // logo decoding
+	"regexp"
+	"strings"
+	"text/template/parse"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	templates "github.com/nava1525/bilio-backend/internal/templates"
+	"github.com/nava1525/bilio-backend/pkg/blobstore"
+	"github.com/nava1525/bilio-backend/pkg/locale"
+	"github.com/nava1525/bilio-backend/pkg/pdf"
+)
+
+const (
+	defaultBrandColor  = "#1c1c21"
+	defaultAccentColor = "#6b6b76"
+	// logoContentID is the Content-ID of the logo sent inline with invoice
+	// emails.
+	logoContentID = "workspace-logo"
+)
+
+// InvoicePageCSP is the Content-Security-Policy sent with rendered invoice
+// pages. Workspace templates choose their own markup, so the page is
+// sandboxed into an opaque origin with scripts, forms and plugins disabled,
+// and may load nothing but inline styles and images.
+const InvoicePageCSP = "sandbox; default-src 'none'; script-src 'none'; style-src 'unsafe-inline'; " +
+	"img-src data: https:; form-action 'none'; base-uri 'none'; frame-ancestors 'none'"
+
+// unsafeTemplateMarkup matches markup a workspace template may not contain:
+// elements that run script, embed other documents, collect input or redirect
+// the page, event handler attributes, and script or HTML URLs.
+var unsafeTemplateMarkup = regexp.MustCompile(`(?i)<\s*/?\s*(script|iframe|frame|frameset|object|embed|applet|form|input|textarea|button|base|link)\b` +
+	`|<\s*meta\b[^>]*http-equiv|[\s/"']on[a-z]+\s*=|srcdoc\s*=|javascript\s*:|vbscript\s*:|data\s*:\s*text/html|@import|expression\s*\(`)
+
+// dynamicElementName matches an element name produced by a template action,
+// which is marked with actionMarker.
+var dynamicElementName = regexp.MustCompile(`<\s*/?\s*\x00`)
+
+const actionMarker = "\x00"
+
+// invoiceTemplateSources holds the built-in html/template source of each
+// layout, by the kind of rendering it is used for.
+var invoiceTemplateSources = map[models.TemplateKind]map[models.InvoiceLayout]string{
+	models.TemplateKindShare: {
+		models.InvoiceLayoutClassic: templates.InvoiceShareHTML,
+		models.InvoiceLayoutModern:  templates.InvoiceShareModernHTML,
+		models.InvoiceLayoutMinimal: templates.InvoiceShareMinimalHTML,
+	},
+	models.TemplateKindEmail: {
+		models.InvoiceLayoutClassic: templates.InvoiceEmailHTML,
+		models.InvoiceLayoutModern:  templates.InvoiceEmailModernHTML,
+		models.InvoiceLayoutMinimal: templates.InvoiceEmailMinimalHTML,
+	},
+}
+
+var builtinInvoiceTemplates = mustParseInvoiceTemplates()
+
+func mustParseInvoiceTemplates() map[models.TemplateKind]map[models.InvoiceLayout]*htmltemplate.Template {
+	parsed := make(map[models.TemplateKind]map[models.InvoiceLayout]*htmltemplate.Template, len(invoiceTemplateSources))
+	for kind, layouts := range invoiceTemplateSources {
+		parsed[kind] = make(map[models.InvoiceLayout]*htmltemplate.Template, len(layouts))
+		for layout, source := range layouts {
+			name := fmt.Sprintf("%s-%s.html", kind, layout)
+			parsed[kind][layout] = htmltemplate.Must(htmltemplate.New(name).Parse(source))
+		}
+	}
+	return parsed
+}
+
+// invoiceBranding is what a workspace contributes to the look of its
+// invoices: its profile, logo and any templates of its own.
+type invoiceBranding struct {
+	workspace *models.User
+	profile   models.WorkspaceProfile
+	logo      []byte
+	overrides map[models.TemplateKind]string
+}
+
+// invoiceBrandData is the branding passed to invoice templates as .Brand.
+type invoiceBrandData struct {
+	Name        string
+	LegalName   string
+	Address     string
+	TaxID       string
+	BankDetails string
+	Footer      string
+	// Details lists the legal name, address and tax ID lines shown under
+	// the workspace name.
+	Details     []string
+	Color       string
+	AccentColor string
+	LogoURL     htmltemplate.URL
+	Layout      string
+}
+
+// loadBranding gathers the workspace's branding for rendering an invoice.
+// A logo missing from the blob store is left out rather than failing the
+// rendering.
+func (s *InvoiceService) loadBranding(ctx context.Context, userID string) (*invoiceBranding, error) {
+	workspace, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := s.workspaces.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &models.WorkspaceProfile{UserID: userID, InvoiceLayout: models.InvoiceLayoutClassic, Locale: DefaultLocale}
+	}
+
+	brand := &invoiceBranding{
+		workspace: workspace,
+		profile:   *profile,
+		overrides: make(map[models.TemplateKind]string),
+	}
+	if profile.Logo != nil && s.blobs != nil {
+		logo, err := s.readBlob(ctx, profile.Logo.StorageKey)
+		if err != nil && !errors.Is(err, blobstore.ErrNotFound) {
+			return nil, fmt.Errorf("load logo: %w", err)
+		}
+		brand.logo = logo
+	}
+
+	overrides, err := s.workspaces.ListTemplates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		// A stored template that no longer passes the markup rules falls
+		// back to the built-in layout rather than being served.
+		if _, err := parseInvoiceTemplate(override.Kind, override.Content); err != nil {
+			continue
+		}
+		brand.overrides[override.Kind] = override.Content
+	}
+	return brand, nil
+}
+
+func (b *invoiceBranding) name() string {
+	return workspaceDisplayName(b.workspace)
+}
+
+func (b *invoiceBranding) layout() models.InvoiceLayout {
+	if _, ok := builtinInvoiceTemplates[models.TemplateKindShare][b.profile.InvoiceLayout]; ok {
+		return b.profile.InvoiceLayout
+	}
+	return models.InvoiceLayoutClassic
+}
+
+func (b *invoiceBranding) colors() (brand string, accent string) {
+	brand, accent = defaultBrandColor, defaultAccentColor
+	if b.profile.BrandColor != nil {
+		brand = *b.profile.BrandColor
+	}
+	if b.profile.AccentColor != nil {
+		accent = *b.profile.AccentColor
+	}
+	return brand, accent
+}
+
+// locale returns the locale to render invoice in.
+func (b *invoiceBranding) locale(invoice *models.Invoice) *locale.Locale {
+	return invoiceLocale(invoice, b.profile.Locale)
+}
+
+// data returns the template data for the branding, with the logo at logoURL.
+func (b *invoiceBranding) data(logoURL htmltemplate.URL, loc *locale.Locale) invoiceBrandData {
+	color, accent := b.colors()
+	data := invoiceBrandData{
+		Name:        b.name(),
+		LegalName:   stringValue(b.profile.LegalName),
+		Address:     stringValue(b.profile.Address),
+		TaxID:       stringValue(b.profile.TaxID),
+		BankDetails: stringValue(b.profile.BankDetails),
+		Footer:      stringValue(b.profile.FooterText),
+		Color:       color,
+		AccentColor: accent,
+		Layout:      string(b.layout()),
+	}
+	if len(b.logo) > 0 {
+		data.LogoURL = logoURL
+	}
+	if data.LegalName != "" {
+		data.Details = append(data.Details, data.LegalName)
+	}
+	if data.Address != "" {
+		data.Details = append(data.Details, strings.Split(data.Address, "\n")...)
+	}
+	if data.TaxID != "" {
+		data.Details = append(data.Details, loc.T("invoice.tax_id", data.TaxID))
+	}
+	return data
+}
+
+// logoDataURL embeds the logo in a page so shared invoices need no separate
+// request to show it.
+func (b *invoiceBranding) logoDataURL() htmltemplate.URL {
+	if len(b.logo) == 0 || b.profile.Logo == nil {
+		return ""
+	}
+	return htmltemplate.URL("data:" + b.profile.Logo.ContentType + ";base64," + base64.StdEncoding.EncodeToString(b.logo))
+}
+
+// template returns the workspace's own template for kind, or the built-in one
+// for its layout.
+func (b *invoiceBranding) template(kind models.TemplateKind) (*htmltemplate.Template, error) {
+	if source, ok := b.overrides[kind]; ok {
+		return parseInvoiceTemplate(kind, source)
+	}
+	return builtinInvoiceTemplates[kind][b.layout()], nil
+}
+
+// pdfLogo decodes the logo for drawing, or returns nil when there is none or
+// it cannot be decoded.
+func (b *invoiceBranding) pdfLogo() *pdf.Image {
+	if len(b.logo) == 0 {
+		return nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(b.logo))
+	if err != nil {
+		return nil
+	}
+	return pdf.NewImage(img)
+}
+
+// pdfColors returns the brand and accent colours for drawing.
+func (b *invoiceBranding) pdfColors() (pdf.Color, pdf.Color) {
+	brand, accent := b.colors()
+	brandColor, ok := pdf.ParseColor(brand)
+	if !ok {
+		brandColor = pdf.Black
+	}
+	accentColor, ok := pdf.ParseColor(accent)
+	if !ok {
+		accentColor = pdf.Gray
+	}
+	return brandColor, accentColor
+}
+
+// parseInvoiceTemplate parses a workspace's template source, reporting syntax
+// errors and unsafe markup as validation errors.
+func parseInvoiceTemplate(kind models.TemplateKind, source string) (*htmltemplate.Template, error) {
+	tmpl, err := htmltemplate.New(fmt.Sprintf("%s-custom.html", kind)).Parse(source)
+	if err != nil {
+		return nil, newValidationError(fmt.Sprintf("invalid template: %v", err))
+	}
+	if err := checkTemplateMarkup(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// checkTemplateMarkup rejects a workspace template whose own markup could run
+// script or act for the page. html/template escapes the data it is given but
+// trusts the template's text, so the text is checked with each action both
+// removed, which joins text an empty action would split, and marked, which
+// finds actions used as element names.
+func checkTemplateMarkup(tmpl *htmltemplate.Template) error {
+	if len(tmpl.Templates()) > 1 {
+		return newValidationError("templates cannot define other templates")
+	}
+	if tmpl.Tree == nil {
+		return nil
+	}
+	for _, marker := range []string{"", actionMarker} {
+		var text strings.Builder
+		if err := templateText(tmpl.Tree.Root, &text, marker); err != nil {
+			return err
+		}
+		if match := unsafeTemplateMarkup.FindString(text.String()); match != "" {
+			return newValidationError(fmt.Sprintf("templates cannot contain %q: scripts, forms, frames, event handlers and redirects are not allowed", strings.TrimSpace(match)))
+		}
+		if marker != "" && dynamicElementName.MatchString(text.String()) {
+			return newValidationError("template actions cannot produce element names")
+		}
+	}
+	return nil
+}
+
+// templateText writes the literal text of node to text, writing marker in
+// place of each action.
+func templateText(node parse.Node, text *strings.Builder, marker string) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := templateText(child, text, marker); err != nil {
+				return err
+			}
+		}
+	case *parse.TextNode:
+		text.Write(n.Text)
+	case *parse.IfNode:
+		return branchText(&n.BranchNode, text, marker)
+	case *parse.RangeNode:
+		return branchText(&n.BranchNode, text, marker)
+	case *parse.WithNode:
+		return branchText(&n.BranchNode, text, marker)
+	case *parse.TemplateNode:
+		return newValidationError("templates cannot include other templates")
+	case *parse.ActionNode:
+		text.WriteString(marker)
+	}
+	return nil
+}
+
+func branchText(n *parse.BranchNode, text *strings.Builder, marker string) error {
+	text.WriteString(marker)
+	if err := templateText(n.List, text, marker); err != nil {
+		return err
+	}
+	text.WriteString(marker)
+	if err := templateText(n.ElseList, text, marker); err != nil {
+		return err
+	}
+	text.WriteString(marker)
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s)
+}
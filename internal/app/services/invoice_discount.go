@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// validateDiscount checks a discount's type and value. field names it in
+// error messages, e.g. "discount" or "items[2].discount".
+func validateDiscount(d *models.Discount, field string) error {
+	if d == nil {
+		return nil
+	}
+	switch d.Type {
+	case models.DiscountTypePercentage:
+		if d.Value.IsNegative() || d.Value.GreaterThan(money.Hundred) {
+			return newValidationError(fmt.Sprintf("%s.value must be between 0 and 100", field))
+		}
+	case models.DiscountTypeFixed:
+		if d.Value.IsNegative() {
+			return newValidationError(fmt.Sprintf("%s.value must not be negative", field))
+		}
+	default:
+		return newValidationError(fmt.Sprintf("%s.type must be percentage or fixed", field))
+	}
+	return nil
+}
+
+// normalizeDiscountTiming defaults an empty timing to pre_tax.
+func normalizeDiscountTiming(timing models.DiscountTiming) (models.DiscountTiming, error) {
+	switch timing {
+	case "":
+		return models.DiscountTimingPreTax, nil
+	case models.DiscountTimingPreTax, models.DiscountTimingPostTax:
+		return timing, nil
+	default:
+		return "", newValidationError("discount_timing must be pre_tax or post_tax")
+	}
+}
+
+// discountAmount returns the amount d takes off base, rounded to the
+// currency. A fixed discount larger than base is rejected.
+func discountAmount(d *models.Discount, base money.Decimal, currency string, field string) (money.Decimal, error) {
+	if d == nil {
+		return money.Zero, nil
+	}
+	if d.Type == models.DiscountTypePercentage {
+		return roundMoney(percentOf(base, d.Value), currency), nil
+	}
+	amount := roundMoney(d.Value, currency)
+	if amount.GreaterThan(base) {
+		return money.Zero, newValidationError(fmt.Sprintf("%s of %s exceeds the amount it applies to (%s)",
+			field, formatMoney(amount, currency), formatMoney(base, currency)))
+	}
+	return amount, nil
+}
+
+// allocateDiscount spreads discount across amounts in proportion to each,
+// so a pre-tax invoice discount reduces every line's taxable amount. The
+// last line absorbs rounding so the shares always add up to discount.
+func allocateDiscount(discount money.Decimal, amounts []money.Decimal, currency string) []money.Decimal {
+	shares := make([]money.Decimal, len(amounts))
+	total := money.Sum(amounts...)
+	if discount.IsZero() || !total.IsPositive() {
+		return shares
+	}
+
+	allocated := money.Zero
+	for i, amount := range amounts {
+		if i == len(amounts)-1 {
+			shares[i] = discount.Sub(allocated)
+			break
+		}
+		shares[i] = discount.Mul(amount).Div(total, money.MinorUnits(currency), money.HalfUp)
+		allocated = allocated.Add(shares[i])
+	}
+	return shares
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+func TestAllocateDiscount(t *testing.T) {
+	tests := []struct {
+		discount string
+		amounts  []string
+		currency string
+		want     []string
+	}{
+		{"10", []string{"100", "200", "300"}, "USD", []string{"1.67", "3.33", "5.00"}},
+		{"0.01", []string{"1", "1", "1"}, "USD", []string{"0.00", "0.00", "0.01"}},
+		{"100", []string{"1", "1", "1"}, "JPY", []string{"33", "33", "34"}},
+		{"50", []string{"100"}, "USD", []string{"50"}},
+		{"0", []string{"100", "200"}, "USD", []string{"0", "0"}},
+		{"10", []string{"0", "0"}, "USD", []string{"0", "0"}},
+		{"10", []string{"0", "100"}, "USD", []string{"0", "10"}},
+		{"10", nil, "USD", nil},
+	}
+	for _, tt := range tests {
+		amounts := make([]money.Decimal, len(tt.amounts))
+		for i, a := range tt.amounts {
+			amounts[i] = dec(a)
+		}
+		got := allocateDiscount(dec(tt.discount), amounts, tt.currency)
+		if len(got) != len(tt.want) {
+			t.Fatalf("allocateDiscount(%s, %v) returned %d shares, want %d", tt.discount, tt.amounts, len(got), len(tt.want))
+		}
+		for i, w := range tt.want {
+			if !got[i].Equal(dec(w)) {
+				t.Errorf("allocateDiscount(%s, %v)[%d] = %s, want %s", tt.discount, tt.amounts, i, got[i], w)
+			}
+		}
+		if total := money.Sum(amounts...); total.IsPositive() && !money.Sum(got...).Equal(dec(tt.discount)) {
+			t.Errorf("allocateDiscount(%s, %v) shares sum to %s", tt.discount, tt.amounts, money.Sum(got...))
+		}
+	}
+}
+
+func TestPriceInvoiceDiscountTiming(t *testing.T) {
+	fixed := &models.Discount{Type: models.DiscountTypeFixed, Value: dec("100")}
+	tests := []struct {
+		name     string
+		timing   models.DiscountTiming
+		discount *models.Discount
+		lines    []*models.Discount
+		subtotal string
+		discAmt  string
+		tax      string
+		total    string
+	}{
+		{name: "none", subtotal: "1000", discAmt: "0", tax: "100", total: "1100"},
+		{name: "fixed pre-tax", timing: models.DiscountTimingPreTax, discount: fixed, subtotal: "1000", discAmt: "100", tax: "90", total: "990"},
+		{name: "empty timing is pre-tax", discount: fixed, subtotal: "1000", discAmt: "100", tax: "90", total: "990"},
+		{name: "fixed post-tax", timing: models.DiscountTimingPostTax, discount: fixed, subtotal: "1000", discAmt: "100", tax: "100", total: "1000"},
+		{
+			name:     "percentage post-tax",
+			timing:   models.DiscountTimingPostTax,
+			discount: &models.Discount{Type: models.DiscountTypePercentage, Value: dec("10")},
+			subtotal: "1000", discAmt: "110", tax: "100", total: "990",
+		},
+		{
+			name:     "line percentage",
+			lines:    []*models.Discount{{Type: models.DiscountTypePercentage, Value: dec("50")}, nil},
+			subtotal: "700", discAmt: "0", tax: "70", total: "770",
+		},
+	}
+	for _, tt := range tests {
+		invoice := &models.Invoice{Currency: "USD", TaxRate: dec("10"), Discount: tt.discount, DiscountTiming: tt.timing}
+		inputs := []CreateInvoiceItemInput{
+			{Description: "Design", Quantity: dec("1"), UnitPrice: dec("600")},
+			{Description: "Build", Quantity: dec("1"), UnitPrice: dec("400")},
+		}
+		for i, d := range tt.lines {
+			inputs[i].Discount = d
+		}
+		if _, err := priceInvoice(invoice, inputs, nil); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		assertAmount(t, tt.name+" subtotal", invoice.Subtotal, tt.subtotal)
+		assertAmount(t, tt.name+" discount", invoice.DiscountAmount, tt.discAmt)
+		assertAmount(t, tt.name+" tax", invoice.TaxAmount, tt.tax)
+		assertAmount(t, tt.name+" total", invoice.Total, tt.total)
+	}
+}
+
+func TestPriceInvoicePreTaxDiscountReducesLineTax(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency: "USD",
+		TaxRate:  dec("10"),
+		Discount: &models.Discount{Type: models.DiscountTypeFixed, Value: dec("100")},
+	}
+	items, err := priceInvoice(invoice, []CreateInvoiceItemInput{
+		{Description: "Design", Quantity: dec("1"), UnitPrice: dec("600")},
+		{Description: "Build", Quantity: dec("1"), UnitPrice: dec("400")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertAmount(t, "item 0 tax", items[0].Taxes[0].Amount, "54")
+	assertAmount(t, "item 1 tax", items[1].Taxes[0].Amount, "36")
+	assertAmount(t, "taxable", invoice.TaxBreakdown[0].TaxableAmount, "900")
+}
+
+func TestPriceInvoiceRejectsOversizedDiscount(t *testing.T) {
+	tests := []struct {
+		name    string
+		invoice *models.Discount
+		line    *models.Discount
+	}{
+		{name: "invoice", invoice: &models.Discount{Type: models.DiscountTypeFixed, Value: dec("1000.01")}},
+		{name: "line", line: &models.Discount{Type: models.DiscountTypeFixed, Value: dec("600.01")}},
+		{name: "percentage", line: &models.Discount{Type: models.DiscountTypePercentage, Value: dec("101")}},
+	}
+	for _, tt := range tests {
+		invoice := &models.Invoice{Currency: "USD", TaxRate: dec("10"), Discount: tt.invoice}
+		_, err := priceInvoice(invoice, []CreateInvoiceItemInput{
+			{Description: "Design", Quantity: dec("1"), UnitPrice: dec("600"), Discount: tt.line},
+			{Description: "Build", Quantity: dec("1"), UnitPrice: dec("400")},
+		}, nil)
+		if _, ok := AsValidationError(err); !ok {
+			t.Errorf("%s: priceInvoice error = %v, want validation error", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+// DuplicateInvoiceInput shifts the copy's issue and due dates. Months are
+// applied first and clamp to the end of shorter months, so an invoice issued
+// on Jan 31 duplicated with offset_months 1 is issued on Feb 28/29.
+type DuplicateInvoiceInput struct {
+	OffsetMonths int `json:"offset_months"`
+	OffsetDays   int `json:"offset_days"`
+}
+
+// Duplicate copies an invoice's client, items, taxes, discounts, currency and
+// notes into a new draft with the next number from the workspace sequence.
+// Any invoice can be duplicated, whatever its status; payments, credit notes,
+// late fees and delivery details are not copied.
+func (s *InvoiceService) Duplicate(ctx context.Context, id string, userID string, input DuplicateInvoiceInput) (*models.Invoice, error) {
+	source, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]CreateInvoiceItemInput, 0, len(source.Items))
+	for _, item := range source.Items {
+		if item.LateFee {
+			continue
+		}
+		items = append(items, CreateInvoiceItemInput{
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			Discount:       item.Discount,
+			TaxCodeID:      item.TaxCodeID,
+			CatalogItemID:  item.CatalogItemID,
+			Unit:           item.Unit,
+			SKU:            item.SKU,
+			CatalogApplied: true,
+		})
+	}
+
+	var dueDate *time.Time
+	if source.DueDate != nil {
+		d := shiftDate(*source.DueDate, input.OffsetMonths, input.OffsetDays)
+		dueDate = &d
+	}
+
+	return s.Create(ctx, userID, CreateInvoiceInput{
+		ClientID:       source.ClientID,
+		Status:         models.InvoiceStatusDraft,
+		IssueDate:      shiftDate(source.IssueDate, input.OffsetMonths, input.OffsetDays),
+		DueDate:        dueDate,
+		Currency:       source.Currency,
+		TaxRate:        &source.TaxRate,
+		Discount:       source.Discount,
+		DiscountTiming: source.DiscountTiming,
+		Notes:          source.Notes,
+		Language:       source.Language,
+		Items:          items,
+	})
+}
+
+func shiftDate(t time.Time, months, days int) time.Time {
+	return addMonthsClamped(t, months).AddDate(0, 0, days)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	templates "github.com/nava1525/bilio-backend/internal/templates"
+	"github.com/nava1525/bilio-backend/pkg/mailer"
+)
+
+var invoiceEmailText = texttemplate.Must(texttemplate.New("invoice.txt").Parse(templates.InvoiceEmailText))
+
+type SendInvoiceInput struct {
+	Message *string `json:"message,omitempty"`
+}
+
+type invoiceEmailData struct {
+	invoiceText
+	Brand         invoiceBrandData
+	WorkspaceName string
+	ClientName    string
+	InvoiceNumber string
+	IssueDate     string
+	DueDate       string
+	AmountDue     string
+	PaymentLink   string
+	Message       string
+}
+
+// Send emails the invoice, with its attachments, to the client's address and
+// CC addresses. A draft invoice moves to pending once the email has been
+// handed to the mail server.
+func (s *InvoiceService) Send(ctx context.Context, id string, userID string, input SendInvoiceInput) (*models.Invoice, error) {
+	if s.mailer == nil {
+		return nil, fmt.Errorf("email sender not configured")
+	}
+
+	invoice, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceStatusCancelled || invoice.Status == models.InvoiceStatusVoid {
+		return nil, InvalidTransitionError{From: invoice.Status, To: models.InvoiceStatusPending}
+	}
+
+	client, err := s.clients.GetByID(ctx, invoice.ClientID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("client not found")
+	}
+	if client.Email == nil || strings.TrimSpace(*client.Email) == "" {
+		return nil, newValidationError("client has no email address; add one before sending the invoice")
+	}
+	invoice.Client = client
+	recipient := strings.TrimSpace(*client.Email)
+
+	brand, err := s.loadBranding(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := buildInvoiceEmail(invoice, brand, input.Message)
+	if err != nil {
+		return nil, err
+	}
+	msg.To = recipient
+	msg.Cc = client.CCEmails
+	attachments, err := s.emailAttachments(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+	msg.Attachments = append(msg.Attachments, attachments...)
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return nil, fmt.Errorf("send invoice email: %w", err)
+	}
+
+	// The invoice may have been paid, voided or cancelled while the email
+	// was on its way, so the status is decided on a fresh, locked copy.
+	err = s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		locked, err := tx.GetByIDForUpdate(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+		if locked == nil {
+			return ErrInvoiceNotFound
+		}
+		if err := tx.MarkSent(ctx, locked, recipient, time.Now().UTC()); err != nil {
+			return fmt.Errorf("failed to record invoice delivery: %w", err)
+		}
+		if locked.Status == models.InvoiceStatusDraft {
+			if err := transitionInvoice(locked, models.InvoiceStatusPending); err != nil {
+				return err
+			}
+			if _, err := tx.Update(ctx, locked); err != nil {
+				return fmt.Errorf("failed to update invoice status: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	sent.Client = client
+	return sent, nil
+}
+
+// buildInvoiceEmail renders the invoice email with the workspace's email
+// template. The logo, when the template shows it, goes with the message as an
+// inline attachment.
+func buildInvoiceEmail(invoice *models.Invoice, brand *invoiceBranding, message *string) (mailer.Message, error) {
+	loc := brand.locale(invoice)
+	data := invoiceEmailData{
+		invoiceText:   invoiceText{locale: loc},
+		Brand:         brand.data(htmltemplate.URL("cid:"+logoContentID), loc),
+		WorkspaceName: brand.name(),
+		InvoiceNumber: invoice.InvoiceNumber,
+		IssueDate:     loc.FormatDate(invoice.IssueDate),
+		AmountDue:     loc.FormatMoney(invoice.BalanceDue, invoice.Currency),
+	}
+	if invoice.Client != nil {
+		data.ClientName = invoice.Client.Name
+	}
+	if invoice.DueDate != nil {
+		data.DueDate = loc.FormatDate(*invoice.DueDate)
+	}
+	if invoice.PaymentLink != nil {
+		data.PaymentLink = *invoice.PaymentLink
+	}
+	if message != nil {
+		data.Message = strings.TrimSpace(*message)
+	}
+
+	htmlTemplate, err := brand.template(models.TemplateKindEmail)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+
+	var htmlBody, textBody bytes.Buffer
+	if err := htmlTemplate.Execute(&htmlBody, data); err != nil {
+		return mailer.Message{}, fmt.Errorf("render invoice email: %w", err)
+	}
+	if err := invoiceEmailText.Execute(&textBody, data); err != nil {
+		return mailer.Message{}, fmt.Errorf("render invoice email: %w", err)
+	}
+
+	msg := mailer.Message{
+		Subject:  loc.T("invoice.title_from", invoice.InvoiceNumber, data.WorkspaceName),
+		TextBody: textBody.String(),
+		HTMLBody: htmlBody.String(),
+	}
+	if data.Brand.LogoURL != "" && strings.Contains(msg.HTMLBody, string(data.Brand.LogoURL)) {
+		msg.Attachments = append(msg.Attachments, logoAttachment(brand))
+	}
+	return msg, nil
+}
+
+func workspaceDisplayName(workspace *models.User) string {
+	if workspace == nil {
+		return "Bilio"
+	}
+	if workspace.WorkspaceName != nil && *workspace.WorkspaceName != "" {
+		return *workspace.WorkspaceName
+	}
+	return workspace.Name
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+var errLateFeeAlreadyCharged = errors.New("late fee period already charged")
+
+// ChargeLateFees charges every late fee period of an overdue invoice that is
+// due by today under policy and has not been charged yet. Period 1 falls due
+// when the grace period after the due date ends and, for percentage
+// policies, each further period a month later; a flat fee is charged once.
+//
+// Each fee is added to the invoice as a late fee line and recorded per
+// period, so repeated runs never charge a period twice. Periods with nothing
+// to charge, because the cap is reached or nothing is outstanding, are still
+// recorded so the schedule moves on.
+func (s *InvoiceService) ChargeLateFees(ctx context.Context, invoiceID string, userID string, policy *models.LateFeePolicy, today time.Time) ([]models.InvoiceLateFee, error) {
+	var charged []models.InvoiceLateFee
+	err := s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		charged = nil
+
+		invoice, err := tx.GetByIDForUpdate(ctx, invoiceID, userID)
+		if err != nil {
+			return err
+		}
+		if invoice == nil {
+			return ErrInvoiceNotFound
+		}
+		if invoice.Status != models.InvoiceStatusOverdue || invoice.DueDate == nil {
+			return nil
+		}
+
+		fees, err := tx.ListLateFees(ctx, invoiceID)
+		if err != nil {
+			return err
+		}
+		chargedTotal := money.Zero
+		period := 0
+		for _, fee := range fees {
+			chargedTotal = chargedTotal.Add(fee.Amount)
+			period = fee.Period
+		}
+
+		graceEnds := dateOnly(*invoice.DueDate).AddDate(0, 0, policy.GraceDays)
+		for {
+			next := period + 1
+			if policy.FeeType == models.LateFeeFlat && next > 1 {
+				break
+			}
+			if addMonthsClamped(graceEnds, next-1).After(today) {
+				break
+			}
+
+			fee := models.InvoiceLateFee{
+				UserID:    userID,
+				InvoiceID: invoiceID,
+				Period:    next,
+				Amount:    lateFeeAmount(policy, invoice, chargedTotal),
+			}
+			if fee.Amount.IsPositive() {
+				item, err := addLateFeeLine(ctx, tx, invoice, lateFeeDescription(policy, next), fee.Amount)
+				if err != nil {
+					return err
+				}
+				fee.InvoiceItemID = &item.ID
+			}
+
+			recorded, err := tx.CreateLateFee(ctx, &fee)
+			if err != nil {
+				return fmt.Errorf("failed to record late fee: %w", err)
+			}
+			if !recorded {
+				return errLateFeeAlreadyCharged
+			}
+
+			charged = append(charged, fee)
+			chargedTotal = chargedTotal.Add(fee.Amount)
+			period = next
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return charged, nil
+}
+
+// lateFeeAmount prices the next late fee on invoice. Interest is charged on
+// the balance still owed, not counting late fees already added to it. The
+// result is held within the policy's cap.
+func lateFeeAmount(policy *models.LateFeePolicy, invoice *models.Invoice, chargedTotal money.Decimal) money.Decimal {
+	var amount money.Decimal
+	switch policy.FeeType {
+	case models.LateFeePercentage:
+		base := invoice.BalanceDue.Sub(chargedTotal).Max(money.Zero)
+		amount = roundMoney(percentOf(base, policy.Amount), invoice.Currency)
+	default:
+		amount = roundMoney(policy.Amount, invoice.Currency)
+	}
+
+	if policy.MaxAmount != nil {
+		remaining := policy.MaxAmount.Sub(chargedTotal).Max(money.Zero)
+		amount = amount.Min(remaining)
+	}
+	return amount
+}
+
+func lateFeeDescription(policy *models.LateFeePolicy, period int) string {
+	if policy.FeeType == models.LateFeePercentage {
+		return fmt.Sprintf("Late payment interest (%s%% per month), month %d", policy.Amount, period)
+	}
+	return "Late fee"
+}
+
+// addLateFeeLine adds a late fee line to invoice and updates its totals.
+// Late fees are neither taxed nor discounted, so the other lines and the tax
+// breakdown stay as they are.
+func addLateFeeLine(ctx context.Context, tx repositories.InvoiceRepository, invoice *models.Invoice, description string, amount money.Decimal) (*models.InvoiceItem, error) {
+	item := &models.InvoiceItem{
+		InvoiceID:   invoice.ID,
+		Description: description,
+		Quantity:    money.NewFromInt(1),
+		UnitPrice:   amount,
+		Amount:      amount,
+		LateFee:     true,
+	}
+	if err := tx.CreateItem(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to create late fee item: %w", err)
+	}
+
+	invoice.Subtotal = invoice.Subtotal.Add(amount)
+	applyInvoiceTotals(invoice)
+	if _, err := tx.Update(ctx, invoice); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+// pricedInvoice prices inputs onto invoice and numbers the items the way the
+// repository would once they are stored.
+func pricedInvoice(t *testing.T, invoice *models.Invoice, inputs []CreateInvoiceItemInput) []models.InvoiceItem {
+	t.Helper()
+	items, err := priceInvoice(invoice, inputs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range items {
+		items[i].ID = fmt.Sprintf("item-%d", i+1)
+	}
+	return items
+}
+
+func lateFeeInvoice(t *testing.T) (*models.Invoice, []models.InvoiceItem) {
+	t.Helper()
+	invoice := &models.Invoice{
+		Currency: "USD",
+		TaxRate:  dec("10"),
+		Discount: &models.Discount{Type: models.DiscountTypeFixed, Value: dec("100")},
+	}
+	items := pricedInvoice(t, invoice, []CreateInvoiceItemInput{
+		{Description: "Consulting", Quantity: dec("1"), UnitPrice: dec("1000")},
+		{Description: "Late fee", Quantity: dec("1"), UnitPrice: dec("25"), LateFee: true},
+	})
+	return invoice, items
+}
+
+func TestPriceInvoiceLateFeeLines(t *testing.T) {
+	invoice, items := lateFeeInvoice(t)
+
+	if len(items[1].Taxes) != 0 {
+		t.Errorf("late fee line taxes = %+v, want none", items[1].Taxes)
+	}
+	assertAmount(t, "late fee amount", items[1].Amount, "25")
+	assertAmount(t, "billed line tax", items[0].Taxes[0].Amount, "90")
+	assertAmount(t, "subtotal", invoice.Subtotal, "1025")
+	assertAmount(t, "discount", invoice.DiscountAmount, "100")
+	assertAmount(t, "taxable", invoice.TaxBreakdown[0].TaxableAmount, "900")
+	assertAmount(t, "tax", invoice.TaxAmount, "90")
+	assertAmount(t, "total", invoice.Total, "1015")
+}
+
+func TestPriceInvoiceDiscountExcludesLateFees(t *testing.T) {
+	invoice := &models.Invoice{
+		Currency: "USD",
+		Discount: &models.Discount{Type: models.DiscountTypeFixed, Value: dec("1000.01")},
+	}
+	_, err := priceInvoice(invoice, []CreateInvoiceItemInput{
+		{Description: "Consulting", Quantity: dec("1"), UnitPrice: dec("1000")},
+		{Description: "Late fee", Quantity: dec("1"), UnitPrice: dec("25"), LateFee: true},
+	}, nil)
+	if _, ok := AsValidationError(err); !ok {
+		t.Errorf("priceInvoice error = %v, want the discount to exceed the billed lines", err)
+	}
+}
+
+func TestPriceCreditNoteLateFeeInvoice(t *testing.T) {
+	invoice, items := lateFeeInvoice(t)
+
+	creditNote, lines, err := priceCreditNote(invoice, items, nil, nil, []CreditNoteItemInput{
+		{InvoiceItemID: items[0].ID, Quantity: dec("1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertAmount(t, "subtotal", creditNote.Subtotal, "1000")
+	assertAmount(t, "discount", creditNote.DiscountAmount, "100")
+	assertAmount(t, "tax", creditNote.TaxAmount, "90")
+	assertAmount(t, "total", creditNote.Total, "990")
+	assertAmount(t, "line tax", lines[0].Taxes[0].Amount, "90")
+
+	creditNote, lines, err = priceCreditNote(invoice, items, nil, nil, []CreditNoteItemInput{
+		{InvoiceItemID: items[1].ID, Quantity: dec("1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines[0].Taxes) != 0 {
+		t.Errorf("late fee credit taxes = %+v, want none", lines[0].Taxes)
+	}
+	assertAmount(t, "late fee discount", creditNote.DiscountAmount, "0")
+	assertAmount(t, "late fee tax", creditNote.TaxAmount, "0")
+	assertAmount(t, "late fee total", creditNote.Total, "25")
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/templates"
+	"github.com/nava1525/bilio-backend/pkg/locale"
+)
+
+// DefaultLocale is the locale of workspaces that have not chosen one.
+const DefaultLocale = "en-US"
+
+// invoiceLocales holds the locales invoices can be rendered in, loaded from
+// the message catalogs in internal/templates/locales.
+var invoiceLocales = mustLoadLocales()
+
+func mustLoadLocales() *locale.Registry {
+	registry, err := locale.Load(templates.Locales, "locales/*.json", DefaultLocale)
+	if err != nil {
+		panic(fmt.Sprintf("load locales: %v", err))
+	}
+	return registry
+}
+
+// SupportedLocales lists the locales a workspace can choose.
+func SupportedLocales() []string {
+	return invoiceLocales.Tags()
+}
+
+// normalizeLocale checks that tag is a supported locale and returns its
+// canonical tag. A bare language such as "de" selects its default region.
+func normalizeLocale(tag string) (string, error) {
+	if strings.TrimSpace(tag) == "" {
+		return DefaultLocale, nil
+	}
+	normalized, err := normalizeLanguage(&tag)
+	if err == nil {
+		if loc, ok := invoiceLocales.Match(*normalized); ok {
+			return loc.Tag(), nil
+		}
+	}
+	return "", newValidationError(fmt.Sprintf("locale %q is not supported; use one of %s", tag, strings.Join(SupportedLocales(), ", ")))
+}
+
+// invoiceLocale picks the locale an invoice is rendered in: the invoice's
+// language, else its client's, else the workspace's locale. A bare language
+// that is the workspace's own keeps the workspace's region, so "en" stays
+// "en-IN" for an Indian workspace. Unsupported languages are skipped.
+func invoiceLocale(invoice *models.Invoice, workspaceTag string) *locale.Locale {
+	workspace, ok := invoiceLocales.Match(workspaceTag)
+	if !ok {
+		workspace = invoiceLocales.Default()
+	}
+
+	candidates := []*string{invoice.Language}
+	if invoice.Client != nil {
+		candidates = append(candidates, invoice.Client.Language)
+	}
+	for _, tag := range candidates {
+		if tag == nil {
+			continue
+		}
+		if *tag == workspace.Language() {
+			return workspace
+		}
+		if loc, ok := invoiceLocales.Match(*tag); ok {
+			return loc
+		}
+	}
+	return workspace
+}
+
+// invoiceText gives invoice templates translated labels as {{.T "key"}} and
+// the page language as {{.Lang}}.
+type invoiceText struct {
+	locale *locale.Locale
+}
+
+func (t invoiceText) T(key string, args ...any) string {
+	return t.locale.T(key, args...)
+}
+
+func (t invoiceText) Lang() string {
+	return t.locale.Tag()
+}
+
+// invoiceStatusLabel names an invoice status for display, e.g. "Partially
+// paid".
+func invoiceStatusLabel(loc *locale.Locale, status models.InvoiceStatus) string {
+	return loc.T("status." + string(status))
+}
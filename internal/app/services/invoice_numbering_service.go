@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/numbering"
+)
+
+type InvoiceNumberingService struct {
+	numbering repositories.InvoiceNumberingRepository
+}
+
+type UpdateInvoiceNumberingInput struct {
+	Format       string `json:"format"`
+	YearlyReset  bool   `json:"yearly_reset"`
+	NextSequence *int64 `json:"next_sequence,omitempty"`
+
+	// CreditNoteFormat and QuoteFormat are optional; empty keeps the current
+	// format.
+	CreditNoteFormat string `json:"credit_note_format,omitempty"`
+	QuoteFormat      string `json:"quote_format,omitempty"`
+}
+
+func NewInvoiceNumberingService(numberingRepo repositories.InvoiceNumberingRepository) *InvoiceNumberingService {
+	return &InvoiceNumberingService{numbering: numberingRepo}
+}
+
+// Get returns the workspace's numbering settings along with a preview of the
+// numbers the next invoice, credit note and quote issued today would receive.
+func (s *InvoiceNumberingService) Get(ctx context.Context, userID string) (*models.InvoiceNumbering, error) {
+	now := time.Now().UTC()
+	settings, err := s.numbering.Get(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	previewNumbers(settings, now)
+	return settings, nil
+}
+
+// Update replaces the numbering settings. NextSequence, when given, restarts
+// the current period's sequence, e.g. to continue from a previous system.
+func (s *InvoiceNumberingService) Update(ctx context.Context, userID string, input UpdateInvoiceNumberingInput) (*models.InvoiceNumbering, error) {
+	format := strings.TrimSpace(input.Format)
+	if err := numbering.Validate(format); err != nil {
+		return nil, newValidationError(fmt.Sprintf("invalid format: %v", err))
+	}
+	if input.YearlyReset && !numbering.HasYear(format) {
+		return nil, newValidationError("format must include {YYYY} or {YY} when yearly_reset is enabled")
+	}
+	if input.NextSequence != nil && *input.NextSequence < 1 {
+		return nil, newValidationError("next_sequence must be at least 1")
+	}
+
+	creditNoteFormat := strings.TrimSpace(input.CreditNoteFormat)
+	if creditNoteFormat != "" {
+		if err := numbering.Validate(creditNoteFormat); err != nil {
+			return nil, newValidationError(fmt.Sprintf("invalid credit_note_format: %v", err))
+		}
+	}
+	quoteFormat := strings.TrimSpace(input.QuoteFormat)
+	if quoteFormat != "" {
+		if err := numbering.Validate(quoteFormat); err != nil {
+			return nil, newValidationError(fmt.Sprintf("invalid quote_format: %v", err))
+		}
+	}
+
+	now := time.Now().UTC()
+	settings, err := s.numbering.Get(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if creditNoteFormat != "" {
+		settings.CreditNoteFormat = creditNoteFormat
+	}
+	if quoteFormat != "" {
+		settings.QuoteFormat = quoteFormat
+	}
+	if input.YearlyReset && !numbering.HasYear(settings.CreditNoteFormat) {
+		return nil, newValidationError("credit_note_format must include {YYYY} or {YY} when yearly_reset is enabled")
+	}
+	if input.YearlyReset && !numbering.HasYear(settings.QuoteFormat) {
+		return nil, newValidationError("quote_format must include {YYYY} or {YY} when yearly_reset is enabled")
+	}
+
+	// Without an explicit next_sequence the current counter is carried over,
+	// so switching between a single and a yearly sequence continues where
+	// numbering left off rather than restarting at 1.
+	settings.Format = format
+	settings.YearlyReset = input.YearlyReset
+	if input.NextSequence != nil {
+		settings.NextSequence = *input.NextSequence
+	}
+
+	if err := s.numbering.Save(ctx, settings, now); err != nil {
+		return nil, err
+	}
+
+	previewNumbers(settings, now)
+	return settings, nil
+}
+
+// previewNumbers fills in the numbers the next invoice, credit note and quote
+// issued on date would receive.
+func previewNumbers(settings *models.InvoiceNumbering, date time.Time) {
+	settings.NextNumber = numbering.Format(settings.Format, settings.NextSequence, date)
+	settings.NextCreditNoteNumber = numbering.Format(settings.CreditNoteFormat, settings.NextCreditNoteSequence, date)
+	settings.NextQuoteNumber = numbering.Format(settings.QuoteFormat, settings.NextQuoteSequence, date)
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+// MarkOverdueInvoices flags every unpaid invoice whose due date is before the
+// calendar day of now (UTC) as overdue. It is safe to run repeatedly and from
+// several processes at once.
+func (s *InvoiceService) MarkOverdueInvoices(ctx context.Context, now time.Time) ([]repositories.StatusTransition, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	transitions, err := s.invoices.MarkOverdue(ctx, statusesAllowing(models.InvoiceStatusOverdue), today)
+	if err != nil {
+		return nil, fmt.Errorf("mark overdue invoices: %w", err)
+	}
+	return transitions, nil
+}
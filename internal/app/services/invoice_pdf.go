@@ -0,0 +1,545 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/locale"
+	"github.com/nava1525/bilio-backend/pkg/money"
+	"github.com/nava1525/bilio-backend/pkg/pdf"
+)
+
+const (
+	pdfMargin       = 50.0
+	pdfFooterHeight = 40.0
+	pdfRowPadding   = 6.0
+	pdfLineHeight   = 13.0
+	pdfLogoWidth    = 140.0
+	pdfLogoHeight   = 40.0
+	// pdfFooterTextLines bounds the workspace footer text on each page.
+	pdfFooterTextLines = 2
+)
+
+type InvoicePDF struct {
+	Filename string
+	Content  []byte
+}
+
+// ParsePageSize maps a query value such as "a4" or "letter" to a page size,
+// defaulting to A4.
+func ParsePageSize(value string) (pdf.PageSize, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "a4":
+		return pdf.PageA4, nil
+	case "letter":
+		return pdf.PageLetter, nil
+	default:
+		return pdf.PageSize{}, fmt.Errorf("unsupported page size %q (use a4 or letter)", value)
+	}
+}
+
+func (s *InvoiceService) GeneratePDF(ctx context.Context, id string, userID string, size pdf.PageSize) (*InvoicePDF, error) {
+	invoice, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clients.GetByID(ctx, invoice.ClientID, userID)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Client = client
+
+	brand, err := s.loadBranding(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	doc := renderInvoicePDF(invoice, brand, size)
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render invoice pdf: %w", err)
+	}
+
+	return &InvoicePDF{
+		Filename: invoiceFilename(invoice.InvoiceNumber),
+		Content:  buf.Bytes(),
+	}, nil
+}
+
+func invoiceFilename(number string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, number)
+	if cleaned == "" {
+		cleaned = "invoice"
+	}
+	return cleaned + ".pdf"
+}
+
+// invoiceLayout tracks the cursor while an invoice flows across pages.
+type invoiceLayout struct {
+	doc     *pdf.Document
+	invoice *models.Invoice
+	brand   *invoiceBranding
+	locale  *locale.Locale
+	style   models.InvoiceLayout
+	color   pdf.Color
+	accent  pdf.Color
+	y       float64
+	left    float64
+	right   float64
+	bottom  float64
+}
+
+func renderInvoicePDF(invoice *models.Invoice, brand *invoiceBranding, size pdf.PageSize) *pdf.Document {
+	doc := pdf.NewDocument(size)
+	color, accent := brand.pdfColors()
+	l := &invoiceLayout{
+		doc:     doc,
+		invoice: invoice,
+		brand:   brand,
+		locale:  brand.locale(invoice),
+		style:   brand.layout(),
+		color:   color,
+		accent:  accent,
+		left:    pdfMargin,
+		right:   size.Width - pdfMargin,
+		bottom:  size.Height - pdfMargin - pdfFooterHeight,
+	}
+
+	doc.AddPage()
+	l.y = pdfMargin
+	l.drawHeader()
+	l.drawParties()
+	l.drawItems()
+	l.drawTotals()
+	l.drawNotes()
+	l.drawBankDetails()
+	l.drawFooters()
+
+	return doc
+}
+
+func (l *invoiceLayout) newPage() {
+	l.doc.AddPage()
+	l.y = pdfMargin
+
+	l.doc.SetFont(pdf.HelveticaBold, 10)
+	l.doc.Text(l.left, l.y+10, l.locale.T("invoice.continued", l.invoice.InvoiceNumber), l.accent)
+	l.y += 30
+}
+
+// ensureSpace starts a new page when the next block of height h would run into
+// the footer area. It reports whether a page break happened.
+func (l *invoiceLayout) ensureSpace(h float64) bool {
+	if l.y+h <= l.bottom {
+		return false
+	}
+	l.newPage()
+	return true
+}
+
+// drawHeader draws the workspace's logo, name and details on the left and
+// the invoice title and dates on the right. The modern layout sets the logo,
+// name and title on a full-width band in the brand colour.
+func (l *invoiceLayout) drawHeader() {
+	doc := l.doc
+	top := l.y
+
+	logo := l.brand.pdfLogo()
+	logoHeight := 0.0
+	if logo != nil {
+		logoHeight = pdfLogoHeight + 8
+	}
+
+	nameColor, titleColor := pdf.Black, l.color
+	title := strings.ToUpper(l.locale.T("invoice.title"))
+	nameSize, titleSize, titleText := 18.0, 22.0, title
+	metaTop := top + logoHeight + 40
+	switch l.style {
+	case models.InvoiceLayoutModern:
+		bandBottom := top + logoHeight + 34
+		doc.FillRect(0, 0, doc.Size().Width, bandBottom, l.color)
+		nameColor, titleColor = pdf.White, pdf.White
+		metaTop = bandBottom + 20
+	case models.InvoiceLayoutMinimal:
+		nameSize, titleSize, titleText = 14, 12, strings.Join(strings.Split(title, ""), " ")
+	}
+
+	if logo != nil {
+		w, h := fitImage(logo, pdfLogoWidth, pdfLogoHeight)
+		if l.style == models.InvoiceLayoutModern {
+			doc.FillRect(l.left-4, top-4, w+8, h+8, pdf.White)
+		}
+		doc.DrawImage(logo, l.left, top, w, h)
+	}
+
+	doc.SetFont(pdf.HelveticaBold, nameSize)
+	doc.Text(l.left, top+logoHeight+nameSize, l.brand.name(), nameColor)
+	doc.SetFont(pdf.HelveticaBold, titleSize)
+	doc.TextRight(l.right, top+logoHeight+20, titleText, titleColor)
+
+	// The workspace's own details run down the left, below the band in the
+	// modern layout.
+	var details []string
+	doc.SetFont(pdf.Helvetica, 9)
+	for _, line := range l.brand.data("", l.locale).Details {
+		details = append(details, doc.WrapText(line, 250)...)
+	}
+	if l.brand.workspace != nil && l.brand.workspace.Email != "" {
+		details = append(details, l.brand.workspace.Email)
+	}
+	y := top + logoHeight + 36
+	if l.style == models.InvoiceLayoutModern {
+		y = metaTop
+	}
+	for _, line := range details {
+		doc.Text(l.left, y, line, pdf.Gray)
+		y += pdfLineHeight - 2
+	}
+	leftBottom := y
+
+	meta := [][2]string{
+		{l.locale.T("invoice.number"), l.invoice.InvoiceNumber},
+		{l.locale.T("invoice.issue_date"), l.locale.FormatDate(l.invoice.IssueDate)},
+	}
+	if l.invoice.DueDate != nil {
+		meta = append(meta, [2]string{l.locale.T("invoice.due_date"), l.locale.FormatDate(*l.invoice.DueDate)})
+	}
+	meta = append(meta, [2]string{l.locale.T("invoice.status"), strings.ToUpper(invoiceStatusLabel(l.locale, l.invoice.Status))})
+
+	y = metaTop
+	for _, row := range meta {
+		doc.SetFont(pdf.Helvetica, 10)
+		doc.TextRight(l.right-110, y, row[0], pdf.Gray)
+		doc.SetFont(pdf.HelveticaBold, 10)
+		doc.TextRight(l.right, y, row[1], pdf.Black)
+		y += pdfLineHeight
+	}
+
+	l.y = math.Max(y, leftBottom) + 20
+}
+
+// fitImage scales img to fit within maxWidth×maxHeight, keeping its aspect
+// ratio.
+func fitImage(img *pdf.Image, maxWidth, maxHeight float64) (float64, float64) {
+	w, h := float64(img.Width()), float64(img.Height())
+	scale := math.Min(maxWidth/w, maxHeight/h)
+	return w * scale, h * scale
+}
+
+func (l *invoiceLayout) drawParties() {
+	doc := l.doc
+	client := l.invoice.Client
+
+	doc.SetFont(pdf.HelveticaBold, 9)
+	doc.Text(l.left, l.y, strings.ToUpper(l.locale.T("invoice.bill_to")), l.accent)
+	l.y += pdfLineHeight + 2
+
+	if client == nil {
+		l.y += 10
+		return
+	}
+
+	doc.SetFont(pdf.HelveticaBold, 11)
+	doc.Text(l.left, l.y, client.Name, pdf.Black)
+	l.y += pdfLineHeight + 1
+
+	doc.SetFont(pdf.Helvetica, 10)
+	var lines []string
+	if client.Company != nil && *client.Company != "" {
+		lines = append(lines, *client.Company)
+	}
+	if client.Address != nil && *client.Address != "" {
+		lines = append(lines, doc.WrapText(*client.Address, 250)...)
+	}
+	if client.Email != nil && *client.Email != "" {
+		lines = append(lines, *client.Email)
+	}
+	if client.TaxID != nil && *client.TaxID != "" {
+		lines = append(lines, l.locale.T("invoice.tax_id", *client.TaxID))
+	}
+	for _, line := range lines {
+		doc.Text(l.left, l.y, line, pdf.Black)
+		l.y += pdfLineHeight
+	}
+
+	l.y += 20
+}
+
+// Column right edges (or left edge for the description) relative to the page.
+func (l *invoiceLayout) columns() (desc, qty, price, amount float64) {
+	return l.left + 6, l.right - 200, l.right - 100, l.right - 6
+}
+
+func (l *invoiceLayout) drawItemsHeader() {
+	doc := l.doc
+	desc, qty, price, amount := l.columns()
+
+	text := pdf.Black
+	switch l.style {
+	case models.InvoiceLayoutModern:
+		doc.FillRect(l.left, l.y, l.right-l.left, 20, l.color)
+		text = pdf.White
+	case models.InvoiceLayoutMinimal:
+		doc.Line(l.left, l.y+20, l.right, l.y+20, 0.75, pdf.Black)
+		text = pdf.Gray
+	default:
+		doc.FillRect(l.left, l.y, l.right-l.left, 20, l.color.Mix(pdf.White, 0.9))
+	}
+	doc.SetFont(pdf.HelveticaBold, 9)
+	doc.Text(desc, l.y+13, strings.ToUpper(l.locale.T("invoice.description")), text)
+	doc.TextRight(qty, l.y+13, strings.ToUpper(l.locale.T("invoice.quantity")), text)
+	doc.TextRight(price, l.y+13, strings.ToUpper(l.locale.T("invoice.unit_price")), text)
+	doc.TextRight(amount, l.y+13, strings.ToUpper(l.locale.T("invoice.amount")), text)
+	l.y += 20
+}
+
+func (l *invoiceLayout) drawItems() {
+	doc := l.doc
+	desc, qty, price, amount := l.columns()
+	descWidth := qty - 50 - desc
+
+	l.ensureSpace(20 + pdfLineHeight + 2*pdfRowPadding)
+	l.drawItemsHeader()
+
+	for _, item := range l.invoice.Items {
+		doc.SetFont(pdf.Helvetica, 10)
+		lines := doc.WrapText(item.Description, descWidth)
+		rowHeight := float64(len(lines))*pdfLineHeight + 2*pdfRowPadding
+		discount := ""
+		if item.DiscountAmount.IsPositive() {
+			discount = fmt.Sprintf("%s: -%s", discountLabel(l.locale, item.Discount), l.locale.FormatMoney(item.DiscountAmount, l.invoice.Currency))
+			rowHeight += pdfLineHeight
+		}
+
+		if l.ensureSpace(rowHeight) {
+			l.drawItemsHeader()
+		}
+
+		doc.SetFont(pdf.Helvetica, 10)
+		baseline := l.y + pdfRowPadding + 9
+		for i, line := range lines {
+			doc.Text(desc, baseline+float64(i)*pdfLineHeight, line, pdf.Black)
+		}
+		if discount != "" {
+			doc.Text(desc, baseline+float64(len(lines))*pdfLineHeight, discount, pdf.Gray)
+		}
+		doc.TextRight(qty, baseline, l.locale.FormatQuantity(item.Quantity), pdf.Black)
+		doc.TextRight(price, baseline, l.locale.FormatMoney(item.UnitPrice, l.invoice.Currency), pdf.Black)
+		doc.TextRight(amount, baseline, l.locale.FormatMoney(item.Amount, l.invoice.Currency), pdf.Black)
+
+		l.y += rowHeight
+		doc.Line(l.left, l.y, l.right, l.y, 0.5, pdf.LightGray)
+	}
+
+	l.y += 15
+}
+
+func (l *invoiceLayout) drawTotals() {
+	doc := l.doc
+	currency := l.invoice.Currency
+
+	rows := invoiceTotalRows(l.invoice, l.locale)
+	settled := invoiceSettledRows(l.invoice, l.locale)
+
+	height := float64(len(rows)+1)*(pdfLineHeight+4) + 10
+	if len(settled) > 0 {
+		height += float64(len(settled)+1) * (pdfLineHeight + 4)
+	}
+	l.ensureSpace(height)
+
+	labelX := l.right - 110
+	for _, row := range rows {
+		doc.SetFont(pdf.Helvetica, 10)
+		doc.TextRight(labelX, l.y+10, row[0], pdf.Gray)
+		doc.TextRight(l.right-6, l.y+10, row[1], pdf.Black)
+		l.y += pdfLineHeight + 4
+	}
+
+	doc.Line(labelX-80, l.y+2, l.right, l.y+2, 0.75, l.color)
+	l.y += 6
+	doc.SetFont(pdf.HelveticaBold, 12)
+	doc.TextRight(labelX, l.y+12, l.locale.T("invoice.total"), pdf.Black)
+	doc.TextRight(l.right-6, l.y+12, l.locale.FormatMoney(l.invoice.Total, currency), pdf.Black)
+	l.y += pdfLineHeight + 8
+
+	if len(settled) > 0 {
+		for _, row := range settled {
+			doc.SetFont(pdf.Helvetica, 10)
+			doc.TextRight(labelX, l.y+10, row[0], pdf.Gray)
+			doc.TextRight(l.right-6, l.y+10, row[1], pdf.Black)
+			l.y += pdfLineHeight + 4
+		}
+
+		doc.SetFont(pdf.HelveticaBold, 11)
+		doc.TextRight(labelX, l.y+10, l.locale.T("invoice.balance_due"), pdf.Black)
+		doc.TextRight(l.right-6, l.y+10, l.locale.FormatMoney(l.invoice.BalanceDue, currency), pdf.Black)
+		l.y += pdfLineHeight + 4
+	}
+
+	l.y += 20
+}
+
+// invoiceTotalRows lists the label/amount rows shown above an invoice's
+// total: subtotal, discount and one row per tax component.
+func invoiceTotalRows(invoice *models.Invoice, loc *locale.Locale) [][2]string {
+	currency := invoice.Currency
+	rows := [][2]string{
+		{loc.T("invoice.subtotal"), loc.FormatMoney(invoice.Subtotal, currency)},
+	}
+	discount := [2]string{discountLabel(loc, invoice.Discount), "-" + loc.FormatMoney(invoice.DiscountAmount, currency)}
+	hasDiscount := invoice.DiscountAmount.IsPositive()
+	if hasDiscount && invoice.DiscountTiming != models.DiscountTimingPostTax {
+		rows = append(rows, discount)
+	}
+	for _, line := range invoice.TaxBreakdown {
+		rows = append(rows, [2]string{loc.T("invoice.tax_line", line.Name, loc.FormatQuantity(line.Rate)), loc.FormatMoney(line.Amount, currency)})
+	}
+	if hasDiscount && invoice.DiscountTiming == models.DiscountTimingPostTax {
+		rows = append(rows, discount)
+	}
+	return rows
+}
+
+// invoiceSettledRows lists what has been credited and paid against the total.
+func invoiceSettledRows(invoice *models.Invoice, loc *locale.Locale) [][2]string {
+	var rows [][2]string
+	if invoice.CreditedAmount.IsPositive() {
+		rows = append(rows, [2]string{loc.T("invoice.credited"), "-" + loc.FormatMoney(invoice.CreditedAmount, invoice.Currency)})
+	}
+	if invoice.AmountPaid.IsPositive() {
+		rows = append(rows, [2]string{loc.T("invoice.amount_paid"), loc.FormatMoney(invoice.AmountPaid, invoice.Currency)})
+	}
+	return rows
+}
+
+func (l *invoiceLayout) drawNotes() {
+	if l.invoice.Notes == nil || strings.TrimSpace(*l.invoice.Notes) == "" {
+		return
+	}
+	doc := l.doc
+
+	doc.SetFont(pdf.Helvetica, 10)
+	lines := doc.WrapText(*l.invoice.Notes, l.right-l.left)
+
+	l.drawTextBlock(strings.ToUpper(l.locale.T("invoice.notes")), lines)
+}
+
+// drawBankDetails prints the workspace's bank details so clients know where
+// to pay.
+func (l *invoiceLayout) drawBankDetails() {
+	details := stringValue(l.brand.profile.BankDetails)
+	if details == "" {
+		return
+	}
+
+	l.doc.SetFont(pdf.Helvetica, 10)
+	lines := l.doc.WrapText(details, l.right-l.left)
+	if l.invoice.Notes != nil && strings.TrimSpace(*l.invoice.Notes) != "" {
+		l.y += 12
+	}
+	l.drawTextBlock(strings.ToUpper(l.locale.T("invoice.payment_details")), lines)
+}
+
+// drawTextBlock prints a captioned block of pre-wrapped lines, continuing on
+// a new page when needed.
+func (l *invoiceLayout) drawTextBlock(caption string, lines []string) {
+	doc := l.doc
+
+	l.ensureSpace(2 * pdfLineHeight)
+	doc.SetFont(pdf.HelveticaBold, 9)
+	doc.Text(l.left, l.y, caption, l.accent)
+	l.y += pdfLineHeight + 2
+
+	doc.SetFont(pdf.Helvetica, 10)
+	for _, line := range lines {
+		if l.ensureSpace(pdfLineHeight) {
+			doc.SetFont(pdf.Helvetica, 10)
+		}
+		doc.Text(l.left, l.y, line, pdf.Black)
+		l.y += pdfLineHeight
+	}
+}
+
+// drawFooters stamps every page with the workspace's footer text, the
+// invoice number and the page count.
+func (l *invoiceLayout) drawFooters() {
+	doc := l.doc
+	total := doc.PageCount()
+	footerY := doc.Size().Height - pdfMargin
+
+	var footerText []string
+	if footer := stringValue(l.brand.profile.FooterText); footer != "" {
+		doc.SetFont(pdf.Helvetica, 8)
+		footerText = doc.WrapText(footer, l.right-l.left)
+		if len(footerText) > pdfFooterTextLines {
+			footerText = footerText[:pdfFooterTextLines]
+		}
+	}
+
+	for i := 0; i < total; i++ {
+		doc.SetPage(i)
+		doc.SetFont(pdf.Helvetica, 8)
+		for n, line := range footerText {
+			doc.Text(l.left, footerY-18-float64(len(footerText)-1-n)*10, line, pdf.Gray)
+		}
+		doc.Line(l.left, footerY-14, l.right, footerY-14, 0.5, pdf.LightGray)
+		doc.SetFont(pdf.Helvetica, 8)
+		doc.Text(l.left, footerY, l.invoice.InvoiceNumber, pdf.Gray)
+		doc.TextRight(l.right, footerY, l.locale.T("invoice.page", i+1, total), pdf.Gray)
+	}
+}
+
+func formatQuantity(q money.Decimal) string {
+	s := q.String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// formatMoney renders an amount in the currency's minor unit with thousands
+// separators and the ISO currency code, e.g. "USD 1,234.50".
+func formatMoney(amount money.Decimal, currency string) string {
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+		amount = amount.Neg()
+	}
+
+	raw := amount.RoundCurrency(currency, money.HalfUp).String()
+	whole, frac := raw, ""
+	if i := strings.IndexByte(raw, '.'); i >= 0 {
+		whole, frac = raw[:i], raw[i:]
+	}
+
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(r)
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s %s%s%s", currency, sign, grouped.String(), frac))
+}
+
+// discountLabel names a discount for display, e.g. "Discount (10%)".
+func discountLabel(loc *locale.Locale, d *models.Discount) string {
+	if d != nil && d.Type == models.DiscountTypePercentage {
+		return loc.T("invoice.discount_percent", loc.FormatQuantity(d.Value))
+	}
+	return loc.T("invoice.discount")
+}
@@ -4,43 +4,94 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/nava1525/bilio-backend/internal/app/models"
 	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/blobstore"
+	"github.com/nava1525/bilio-backend/pkg/mailer"
+	"github.com/nava1525/bilio-backend/pkg/money"
 )
 
 type InvoiceService struct {
-	invoices repositories.InvoiceRepository
-	clients  repositories.ClientRepository
+	invoices    repositories.InvoiceRepository
+	clients     repositories.ClientRepository
+	users       repositories.UserRepository
+	workspaces  repositories.WorkspaceRepository
+	taxCodes    repositories.TaxCodeRepository
+	catalog     repositories.CatalogItemRepository
+	attachments repositories.InvoiceAttachmentRepository
+	blobs       blobstore.Store
+	mailer      mailer.Sender
 }
 
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
 type CreateInvoiceInput struct {
-	ClientID      string                 `json:"client_id"`
-	InvoiceNumber string                 `json:"invoice_number"`
-	Status        models.InvoiceStatus   `json:"status"`
-	IssueDate     time.Time              `json:"issue_date"`
-	DueDate       *time.Time             `json:"due_date,omitempty"`
-	Currency      string                 `json:"currency"`
-	TaxRate       float64                `json:"tax_rate"`
-	Notes         *string                `json:"notes,omitempty"`
-	Items         []CreateInvoiceItemInput `json:"items"`
+	ClientID       string                   `json:"client_id"`
+	InvoiceNumber  string                   `json:"invoice_number"`
+	Status         models.InvoiceStatus     `json:"status"`
+	IssueDate      time.Time                `json:"issue_date"`
+	DueDate        *time.Time               `json:"due_date,omitempty"`
+	Currency       string                   `json:"currency"`
+	TaxRate        *money.Decimal           `json:"tax_rate,omitempty"`
+	Discount       *models.Discount         `json:"discount,omitempty"`
+	DiscountTiming models.DiscountTiming    `json:"discount_timing,omitempty"`
+	Notes          *string                  `json:"notes,omitempty"`
+	Language       *string                  `json:"language,omitempty"`
+	Items          []CreateInvoiceItemInput `json:"items"`
+
+	// Set by the recurring invoice generator; not accepted from API callers.
+	RecurringInvoiceID *string    `json:"-"`
+	RecurringPeriod    *time.Time `json:"-"`
+	// Set when converting a quote; not accepted from API callers.
+	QuoteID *string `json:"-"`
 }
 
 type CreateInvoiceItemInput struct {
-	Description string  `json:"description"`
-	Quantity    float64 `json:"quantity"`
-	UnitPrice   float64 `json:"unit_price"`
+	Description string           `json:"description"`
+	Quantity    money.Decimal    `json:"quantity"`
+	UnitPrice   money.Decimal    `json:"unit_price"`
+	Discount    *models.Discount `json:"discount,omitempty"`
+	TaxCodeID   *string          `json:"tax_code_id,omitempty"`
+	// CatalogItemID bills the line from a catalog item. Fields left empty
+	// take the item's values: its name and description, its unit price in
+	// the invoice's currency (when unit_price is 0), unit and tax code.
+	CatalogItemID *string             `json:"catalog_item_id,omitempty"`
+	Unit          *models.CatalogUnit `json:"unit,omitempty"`
+
+	// Copied from the catalog item; not accepted from API callers.
+	SKU *string `json:"-"`
+	// Set on lines copied from a quote, recurring invoice or invoice that
+	// already took the catalog's values, so they keep them; not accepted
+	// from API callers.
+	CatalogApplied bool `json:"-"`
+	// Set when a late fee is charged; not accepted from API callers.
+	LateFee bool `json:"-"`
 }
 
+// UpdateInvoiceInput leaves TaxRate, Discount and DiscountTiming as they are
+// when omitted; ClearDiscount removes the invoice discount.
 type UpdateInvoiceInput struct {
-	Status    *models.InvoiceStatus `json:"status,omitempty"`
-	IssueDate *time.Time            `json:"issue_date,omitempty"`
-	DueDate   *time.Time            `json:"due_date,omitempty"`
-	Currency  string                `json:"currency"`
-	TaxRate   float64               `json:"tax_rate"`
-	Notes     *string               `json:"notes,omitempty"`
-	Items     []CreateInvoiceItemInput `json:"items,omitempty"`
+	Status         *models.InvoiceStatus    `json:"status,omitempty"`
+	IssueDate      *time.Time               `json:"issue_date,omitempty"`
+	DueDate        *time.Time               `json:"due_date,omitempty"`
+	Currency       string                   `json:"currency"`
+	TaxRate        *money.Decimal           `json:"tax_rate,omitempty"`
+	Discount       *models.Discount         `json:"discount,omitempty"`
+	DiscountTiming models.DiscountTiming    `json:"discount_timing,omitempty"`
+	ClearDiscount  bool                     `json:"clear_discount,omitempty"`
+	Notes          *string                  `json:"notes,omitempty"`
+	Language       *string                  `json:"language,omitempty"`
+	Items          []CreateInvoiceItemInput `json:"items,omitempty"`
+}
+
+// reprices reports whether the update changes anything the invoice's items
+// and totals are priced from.
+func (input UpdateInvoiceInput) reprices() bool {
+	return len(input.Items) > 0 || input.TaxRate != nil || input.Currency != "" ||
+		input.Discount != nil || input.ClearDiscount || input.DiscountTiming != ""
 }
 
 type InvoiceFilters struct {
@@ -50,10 +101,17 @@ type InvoiceFilters struct {
 	ToDate   *time.Time
 }
 
-func NewInvoiceService(invoiceRepo repositories.InvoiceRepository, clientRepo repositories.ClientRepository) *InvoiceService {
+func NewInvoiceService(invoiceRepo repositories.InvoiceRepository, clientRepo repositories.ClientRepository, userRepo repositories.UserRepository, workspaceRepo repositories.WorkspaceRepository, taxCodeRepo repositories.TaxCodeRepository, catalogRepo repositories.CatalogItemRepository, attachmentRepo repositories.InvoiceAttachmentRepository, blobs blobstore.Store, sender mailer.Sender) *InvoiceService {
 	return &InvoiceService{
-		invoices: invoiceRepo,
-		clients:  clientRepo,
+		invoices:    invoiceRepo,
+		clients:     clientRepo,
+		users:       userRepo,
+		workspaces:  workspaceRepo,
+		taxCodes:    taxCodeRepo,
+		catalog:     catalogRepo,
+		attachments: attachmentRepo,
+		blobs:       blobs,
+		mailer:      sender,
 	}
 }
 
@@ -73,7 +131,7 @@ func (s *InvoiceService) GetByID(ctx context.Context, id string, userID string)
 		return nil, err
 	}
 	if invoice == nil {
-		return nil, errors.New("invoice not found")
+		return nil, ErrInvoiceNotFound
 	}
 
 	// Load items
@@ -94,6 +152,12 @@ func (s *InvoiceService) GetByID(ctx context.Context, id string, userID string)
 }
 
 func (s *InvoiceService) Create(ctx context.Context, userID string, input CreateInvoiceInput) (*models.Invoice, error) {
+	return s.create(ctx, s.invoices, userID, input)
+}
+
+// create writes the invoice through invoices, joining its transaction when
+// it is bound to one.
+func (s *InvoiceService) create(ctx context.Context, invoices repositories.InvoiceRepository, userID string, input CreateInvoiceInput) (*models.Invoice, error) {
 	// Verify client exists and belongs to user
 	client, err := s.clients.GetByID(ctx, input.ClientID, userID)
 	if err != nil {
@@ -102,85 +166,152 @@ func (s *InvoiceService) Create(ctx context.Context, userID string, input Create
 	if client == nil {
 		return nil, errors.New("client not found")
 	}
-
-	if input.InvoiceNumber == "" {
-		return nil, errors.New("invoice_number is required")
+	// Fields the invoice leaves out come from the client's billing profile.
+	if err := applyClientDefaults(&input, client); err != nil {
+		return nil, err
 	}
+
+	// An empty number is allocated from the workspace's numbering sequence.
+	input.InvoiceNumber = strings.TrimSpace(input.InvoiceNumber)
 	if len(input.Items) == 0 {
 		return nil, errors.New("at least one item is required")
 	}
+	if err := validateInvoiceItems(input.Items); err != nil {
+		return nil, err
+	}
 	if input.Currency == "" {
 		input.Currency = "USD"
 	}
+	if err := s.applyCatalogItems(ctx, userID, input.Currency, input.Items); err != nil {
+		return nil, err
+	}
+	applyClientTax(&input, client)
 	if input.Status == "" {
 		input.Status = models.InvoiceStatusDraft
 	}
-
-	// Calculate totals
-	subtotal := 0.0
-	for _, item := range input.Items {
-		amount := item.Quantity * item.UnitPrice
-		subtotal += amount
+	if input.Status != models.InvoiceStatusDraft && input.Status != models.InvoiceStatusPending {
+		return nil, newValidationError("new invoices must be draft or pending")
+	}
+	language, err := normalizeLanguage(input.Language)
+	if err != nil {
+		return nil, err
 	}
 
-	taxAmount := subtotal * (input.TaxRate / 100)
-	total := subtotal + taxAmount
+	taxCodes, err := s.loadTaxCodes(ctx, userID, input.Items)
+	if err != nil {
+		return nil, err
+	}
 
 	invoice := &models.Invoice{
-		UserID:        userID,
-		ClientID:      input.ClientID,
-		InvoiceNumber: input.InvoiceNumber,
-		Status:        input.Status,
-		IssueDate:     input.IssueDate,
-		DueDate:       input.DueDate,
-		Currency:      input.Currency,
-		Subtotal:      subtotal,
-		TaxRate:       input.TaxRate,
-		TaxAmount:     taxAmount,
-		Total:         total,
-		Notes:         input.Notes,
-	}
-
-	created, err := s.invoices.Create(ctx, invoice)
+		UserID:             userID,
+		ClientID:           input.ClientID,
+		InvoiceNumber:      input.InvoiceNumber,
+		Status:             input.Status,
+		IssueDate:          input.IssueDate,
+		DueDate:            input.DueDate,
+		Currency:           input.Currency,
+		Discount:           input.Discount,
+		DiscountTiming:     input.DiscountTiming,
+		Notes:              input.Notes,
+		Language:           language,
+		RecurringInvoiceID: input.RecurringInvoiceID,
+		RecurringPeriod:    input.RecurringPeriod,
+		QuoteID:            input.QuoteID,
+	}
+	if input.TaxRate != nil {
+		invoice.TaxRate = *input.TaxRate
+	}
+	items, err := priceInvoice(invoice, input.Items, taxCodes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create invoice: %w", err)
-	}
-
-	// Create items
-	for _, itemInput := range input.Items {
-		amount := itemInput.Quantity * itemInput.UnitPrice
-		item := &models.InvoiceItem{
-			InvoiceID:   created.ID,
-			Description: itemInput.Description,
-			Quantity:    itemInput.Quantity,
-			UnitPrice:   itemInput.UnitPrice,
-			Amount:      amount,
+		return nil, err
+	}
+
+	// The header and its items are written in one transaction so a failure
+	// part-way never leaves an invoice whose totals disagree with its items.
+	var created *models.Invoice
+	err = invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		var err error
+		created, err = tx.Create(ctx, invoice)
+		if err != nil {
+			return fmt.Errorf("failed to create invoice: %w", err)
 		}
-		if err := s.invoices.CreateItem(ctx, item); err != nil {
-			return nil, fmt.Errorf("failed to create invoice item: %w", err)
+
+		for i := range items {
+			items[i].InvoiceID = created.ID
+			if err := tx.CreateItem(ctx, &items[i]); err != nil {
+				return fmt.Errorf("failed to create invoice item: %w", err)
+			}
 		}
-		created.Items = append(created.Items, *item)
+		created.Items = items
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return created, nil
 }
 
 func (s *InvoiceService) Update(ctx context.Context, id string, userID string, input UpdateInvoiceInput) (*models.Invoice, error) {
-	invoice, err := s.invoices.GetByID(ctx, id, userID)
+	var updated *models.Invoice
+	err := s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		var err error
+		updated, err = s.update(ctx, tx, id, userID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// update applies input to the invoice inside tx. The invoice row is locked
+// so concurrent edits and payments are applied one after another.
+func (s *InvoiceService) update(ctx context.Context, tx repositories.InvoiceRepository, id string, userID string, input UpdateInvoiceInput) (*models.Invoice, error) {
+	invoice, err := tx.GetByIDForUpdate(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 	if invoice == nil {
-		return nil, errors.New("invoice not found")
+		return nil, ErrInvoiceNotFound
 	}
 
-	// Only allow updates to draft or pending invoices
-	if invoice.Status != models.InvoiceStatusDraft && invoice.Status != models.InvoiceStatusPending {
-		return nil, errors.New("can only update draft or pending invoices")
+	if invoice.Status == models.InvoiceStatusVoid {
+		return nil, newValidationError("voided invoices cannot be changed")
+	}
+	if input.Status != nil && *input.Status == models.InvoiceStatusVoid {
+		return nil, newValidationError("use the void action to void an invoice so the reason is recorded")
+	}
+	// Paid and partially paid follow from recorded payments and credit notes,
+	// so an update cannot set them without the money to back them.
+	if input.Status != nil && *input.Status != invoice.Status &&
+		(*input.Status == models.InvoiceStatusPaid || *input.Status == models.InvoiceStatusPartiallyPaid) {
+		return nil, newValidationError("record a payment to mark an invoice paid or partially paid")
+	}
+
+	// Only draft or pending invoices can be edited. Issued invoices in any
+	// other state accept a status change alone (e.g. cancelling an overdue
+	// invoice); the remaining fields are ignored.
+	// Credited invoices are frozen too, since their credit notes refer to
+	// the invoice's lines.
+	editable := invoice.Status == models.InvoiceStatusDraft || invoice.Status == models.InvoiceStatusPending
+	if !editable && input.Status == nil {
+		return nil, newValidationError("can only update draft or pending invoices")
+	}
+	if invoice.CreditedAmount.IsPositive() {
+		if input.Status == nil {
+			return nil, newValidationError("invoices with credit notes can only change status")
+		}
+		editable = false
 	}
 
 	if input.Status != nil {
-		invoice.Status = *input.Status
+		if err := transitionInvoice(invoice, *input.Status); err != nil {
+			return nil, err
+		}
+	}
+	if !editable {
+		return tx.Update(ctx, invoice)
 	}
 	if input.IssueDate != nil {
 		invoice.IssueDate = *input.IssueDate
@@ -191,79 +322,250 @@ func (s *InvoiceService) Update(ctx context.Context, id string, userID string, i
 	if input.Currency != "" {
 		invoice.Currency = input.Currency
 	}
-	invoice.TaxRate = input.TaxRate
+	if input.TaxRate != nil {
+		invoice.TaxRate = *input.TaxRate
+	}
+	if input.ClearDiscount && input.Discount != nil {
+		return nil, newValidationError("discount and clear_discount cannot be used together")
+	}
+	if input.ClearDiscount {
+		invoice.Discount = nil
+	}
+	if input.Discount != nil {
+		invoice.Discount = input.Discount
+	}
+	if input.DiscountTiming != "" {
+		invoice.DiscountTiming = input.DiscountTiming
+	}
 	if input.Notes != nil {
 		invoice.Notes = input.Notes
 	}
-
-	// Update items if provided
-	if len(input.Items) > 0 {
-		// Delete existing items
-		existingItems, _ := s.invoices.GetItems(ctx, id)
-		for _, item := range existingItems {
-			_ = s.invoices.DeleteItem(ctx, item.ID)
+	if input.Language != nil {
+		invoice.Language, err = normalizeLanguage(input.Language)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		// Recalculate totals
-		subtotal := 0.0
-		for _, itemInput := range input.Items {
-			amount := itemInput.Quantity * itemInput.UnitPrice
-			subtotal += amount
-
-			item := &models.InvoiceItem{
-				InvoiceID:   id,
-				Description: itemInput.Description,
-				Quantity:    itemInput.Quantity,
-				UnitPrice:   itemInput.UnitPrice,
-				Amount:      amount,
-			}
-			if err := s.invoices.CreateItem(ctx, item); err != nil {
-				return nil, fmt.Errorf("failed to create invoice item: %w", err)
-			}
+	// Items, discounts, taxes and totals are re-derived whenever a field that
+	// prices them is present, so a tax or discount change without new items
+	// still keeps them consistent. Existing items keep what they copied from
+	// the catalog. Other edits leave the stored items and totals alone.
+	if !input.reprices() {
+		return tx.Update(ctx, invoice)
+	}
+	itemInputs := input.Items
+	if len(itemInputs) > 0 {
+		if err := s.applyCatalogItems(ctx, userID, invoice.Currency, itemInputs); err != nil {
+			return nil, err
 		}
+	} else {
+		existing, err := tx.GetItems(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range existing {
+			itemInputs = append(itemInputs, CreateInvoiceItemInput{
+				Description:   item.Description,
+				Quantity:      item.Quantity,
+				UnitPrice:     item.UnitPrice,
+				Discount:      item.Discount,
+				TaxCodeID:     item.TaxCodeID,
+				CatalogItemID: item.CatalogItemID,
+				Unit:          item.Unit,
+				SKU:           item.SKU,
+				LateFee:       item.LateFee,
+			})
+		}
+	}
+	if err := validateInvoiceItems(itemInputs); err != nil {
+		return nil, err
+	}
+	taxCodes, err := s.loadTaxCodes(ctx, userID, itemInputs)
+	if err != nil {
+		return nil, err
+	}
+	items, err := priceInvoice(invoice, itemInputs, taxCodes)
+	if err != nil {
+		return nil, err
+	}
 
-		invoice.Subtotal = subtotal
-		invoice.TaxAmount = subtotal * (input.TaxRate / 100)
-		invoice.Total = invoice.Subtotal + invoice.TaxAmount
+	if err := tx.DeleteItems(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete invoice items: %w", err)
 	}
+	for i := range items {
+		if err := tx.CreateItem(ctx, &items[i]); err != nil {
+			return nil, fmt.Errorf("failed to create invoice item: %w", err)
+		}
+	}
+	invoice.Items = items
 
-	return s.invoices.Update(ctx, invoice)
+	return tx.Update(ctx, invoice)
 }
 
+// MarkPaid records a payment against the invoice. The invoice only becomes
+// paid once the payments cover the full total; anything less leaves it
+// partially paid. Payments above the balance due are rejected unless the
+// excess is kept as client credit. A payment method of "client_credit" pays
+// from the client's credit balance.
 func (s *InvoiceService) MarkPaid(ctx context.Context, id string, userID string, paymentInput CreatePaymentInput) (*models.Invoice, error) {
-	invoice, err := s.invoices.GetByID(ctx, id, userID)
+	var updated *models.Invoice
+	err := s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		var err error
+		updated, err = s.markPaid(ctx, tx, id, userID, paymentInput)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// markPaid records the payment and the resulting status change inside tx.
+// The invoice row is locked first so two concurrent payments cannot both
+// pass the balance check.
+func (s *InvoiceService) markPaid(ctx context.Context, tx repositories.InvoiceRepository, id string, userID string, paymentInput CreatePaymentInput) (*models.Invoice, error) {
+	invoice, err := tx.GetByIDForUpdate(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
 	if invoice == nil {
-		return nil, errors.New("invoice not found")
+		return nil, ErrInvoiceNotFound
+	}
+
+	if invoice.Status == models.InvoiceStatusPaid {
+		return nil, newValidationError("invoice is already fully paid")
+	}
+
+	amount := roundMoney(paymentInput.Amount, invoice.Currency)
+	if !amount.IsPositive() {
+		return nil, newValidationError("amount must be greater than 0")
+	}
+	if paymentInput.Currency == "" {
+		paymentInput.Currency = invoice.Currency
+	}
+	if !strings.EqualFold(paymentInput.Currency, invoice.Currency) {
+		return nil, newValidationError(fmt.Sprintf("payment currency %s does not match invoice currency %s", paymentInput.Currency, invoice.Currency))
+	}
+	fromCredit := paymentInput.PaymentMethod != nil && *paymentInput.PaymentMethod == models.PaymentMethodClientCredit
+	var excess money.Decimal
+	if amount.GreaterThan(invoice.BalanceDue) {
+		if !paymentInput.CreditOverpayment || fromCredit {
+			return nil, newValidationError(fmt.Sprintf("payment of %s exceeds balance due of %s; set credit_overpayment to keep the difference as client credit",
+				formatMoney(amount, invoice.Currency), formatMoney(invoice.BalanceDue, invoice.Currency)))
+		}
+		excess = amount.Sub(invoice.BalanceDue)
+		amount = invoice.BalanceDue
+	}
+	if fromCredit || excess.IsPositive() {
+		if _, err := tx.LockClient(ctx, invoice.ClientID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if fromCredit {
+		balance, err := tx.ClientCreditBalance(ctx, invoice.ClientID, invoice.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if amount.GreaterThan(balance) {
+			return nil, newValidationError(fmt.Sprintf("payment of %s exceeds the client's available credit of %s",
+				formatMoney(amount, invoice.Currency), formatMoney(balance, invoice.Currency)))
+		}
+	}
+
+	// An overdue invoice stays overdue until it is settled in full.
+	nextStatus := models.InvoiceStatusPaid
+	if amount.LessThan(invoice.BalanceDue) {
+		nextStatus = models.InvoiceStatusPartiallyPaid
+		if invoice.Status == models.InvoiceStatusOverdue {
+			nextStatus = models.InvoiceStatusOverdue
+		}
+	}
+	if err := transitionInvoice(invoice, nextStatus); err != nil {
+		return nil, err
+	}
+	if paymentInput.PaymentDate.IsZero() {
+		paymentInput.PaymentDate = time.Now().UTC()
 	}
 
 	payment := &models.Payment{
 		InvoiceID:     id,
-		Amount:        paymentInput.Amount,
-		Currency:      paymentInput.Currency,
+		Amount:        amount,
+		Currency:      invoice.Currency,
 		PaymentMethod: paymentInput.PaymentMethod,
 		PaymentDate:   paymentInput.PaymentDate,
 		TransactionID: paymentInput.TransactionID,
 		Notes:         paymentInput.Notes,
 	}
 
-	if err := s.invoices.CreatePayment(ctx, payment); err != nil {
+	if err := tx.CreatePayment(ctx, payment); err != nil {
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
-	// Update invoice status
-	invoice.Status = models.InvoiceStatusPaid
-	return s.invoices.Update(ctx, invoice)
+	switch {
+	case fromCredit:
+		err = recordClientCredit(ctx, tx, invoice, payment, models.ClientCreditApplied, amount.Neg(), nil)
+	case excess.IsPositive():
+		err = recordClientCredit(ctx, tx, invoice, payment, models.ClientCreditOverpayment, excess, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.AmountPaid = invoice.AmountPaid.Add(amount)
+	invoice.BalanceDue = invoice.Total.Sub(invoice.AmountPaid).Sub(invoice.CreditedAmount)
+
+	updated, err := tx.Update(ctx, invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := tx.GetPayments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated.Payments = payments
+
+	return updated, nil
 }
 
-type CreatePaymentInput struct {
-	Amount        float64  `json:"amount"`
-	Currency      string   `json:"currency"`
-	PaymentMethod *string  `json:"payment_method,omitempty"`
-	PaymentDate   time.Time `json:"payment_date"`
-	TransactionID *string  `json:"transaction_id,omitempty"`
-	Notes         *string  `json:"notes,omitempty"`
+// roundMoney rounds amount to currency's minor unit. Half-up is used
+// throughout so stored amounts match what a client computes by hand.
+func roundMoney(amount money.Decimal, currency string) money.Decimal {
+	return amount.RoundCurrency(currency, money.HalfUp)
+}
+
+// Item precision matches the invoice_items columns.
+const (
+	maxQuantityScale  = 3
+	maxUnitPriceScale = 4
+)
+
+func validateInvoiceItems(items []CreateInvoiceItemInput) error {
+	for _, item := range items {
+		if !item.Quantity.Round(maxQuantityScale, money.Down).Equal(item.Quantity) {
+			return newValidationError(fmt.Sprintf("quantity %s has more than %d decimal places", item.Quantity, maxQuantityScale))
+		}
+		if !item.UnitPrice.Round(maxUnitPriceScale, money.Down).Equal(item.UnitPrice) {
+			return newValidationError(fmt.Sprintf("unit_price %s has more than %d decimal places", item.UnitPrice, maxUnitPriceScale))
+		}
+	}
+	return nil
 }
 
+// lineAmount prices one item: quantity × unit price, rounded per line.
+func lineAmount(item CreateInvoiceItemInput, currency string) money.Decimal {
+	return roundMoney(item.Quantity.Mul(item.UnitPrice), currency)
+}
+
+type CreatePaymentInput struct {
+	Amount        money.Decimal `json:"amount"`
+	Currency      string        `json:"currency"`
+	PaymentMethod *string       `json:"payment_method,omitempty"`
+	PaymentDate   time.Time     `json:"payment_date"`
+	TransactionID *string       `json:"transaction_id,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+	// CreditOverpayment keeps any amount above the balance due as client
+	// credit instead of rejecting the payment.
+	CreditOverpayment bool `json:"credit_overpayment,omitempty"`
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+// fakeInvoiceRepository keeps invoices and their items in memory. Methods the
+// tests do not reach fall through to the nil embedded interface and panic.
+type fakeInvoiceRepository struct {
+	repositories.InvoiceRepository
+	invoices   map[string]*models.Invoice
+	items      map[string][]models.InvoiceItem
+	payments   map[string][]models.Payment
+	events     []models.InvoiceEvent
+	itemWrites int
+	nextID     int
+	// lastNumber is the last value allocated from the INV-0001 sequence.
+	lastNumber int
+}
+
+func newFakeInvoiceRepository() *fakeInvoiceRepository {
+	return &fakeInvoiceRepository{
+		invoices: make(map[string]*models.Invoice),
+		items:    make(map[string][]models.InvoiceItem),
+	}
+}
+
+func (r *fakeInvoiceRepository) GetByID(ctx context.Context, id string, userID string) (*models.Invoice, error) {
+	invoice, ok := r.invoices[id]
+	if !ok || invoice.UserID != userID {
+		return nil, nil
+	}
+	copied := *invoice
+	return &copied, nil
+}
+
+func (r *fakeInvoiceRepository) List(ctx context.Context, userID string, filters repositories.InvoiceFilters) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	for i := 1; i <= r.nextID; i++ {
+		if invoice, ok := r.invoices[fmt.Sprintf("invoice-%d", i)]; ok && invoice.UserID == userID {
+			invoices = append(invoices, *invoice)
+		}
+	}
+	return invoices, nil
+}
+
+func (r *fakeInvoiceRepository) GetByIDForUpdate(ctx context.Context, id string, userID string) (*models.Invoice, error) {
+	return r.GetByID(ctx, id, userID)
+}
+
+func (r *fakeInvoiceRepository) Create(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
+	r.nextID++
+	created := *invoice
+	created.ID = fmt.Sprintf("invoice-%d", r.nextID)
+	if created.InvoiceNumber == "" {
+		r.lastNumber++
+		created.InvoiceNumber = fmt.Sprintf("INV-%04d", r.lastNumber)
+	}
+	r.invoices[created.ID] = &created
+	copied := created
+	return &copied, nil
+}
+
+func (r *fakeInvoiceRepository) Update(ctx context.Context, invoice *models.Invoice) (*models.Invoice, error) {
+	stored := *invoice
+	r.invoices[invoice.ID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+func (r *fakeInvoiceRepository) Delete(ctx context.Context, id string, userID string) error {
+	delete(r.invoices, id)
+	delete(r.items, id)
+	return nil
+}
+
+func (r *fakeInvoiceRepository) ReleaseNumber(ctx context.Context, invoice *models.Invoice) (bool, error) {
+	if r.lastNumber == 0 || invoice.InvoiceNumber != fmt.Sprintf("INV-%04d", r.lastNumber) {
+		return false, nil
+	}
+	r.lastNumber--
+	return true, nil
+}
+
+func (r *fakeInvoiceRepository) Void(ctx context.Context, invoice *models.Invoice) error {
+	stored := *invoice
+	r.invoices[invoice.ID] = &stored
+	return nil
+}
+
+func (r *fakeInvoiceRepository) CreateEvent(ctx context.Context, event *models.InvoiceEvent) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeInvoiceRepository) GetItems(ctx context.Context, invoiceID string) ([]models.InvoiceItem, error) {
+	return append([]models.InvoiceItem(nil), r.items[invoiceID]...), nil
+}
+
+func (r *fakeInvoiceRepository) GetPayments(ctx context.Context, invoiceID string) ([]models.Payment, error) {
+	return r.payments[invoiceID], nil
+}
+
+func (r *fakeInvoiceRepository) CreateItem(ctx context.Context, item *models.InvoiceItem) error {
+	r.itemWrites++
+	item.ID = fmt.Sprintf("%s-item-%d", item.InvoiceID, len(r.items[item.InvoiceID])+1)
+	r.items[item.InvoiceID] = append(r.items[item.InvoiceID], *item)
+	return nil
+}
+
+func (r *fakeInvoiceRepository) DeleteItems(ctx context.Context, invoiceID string) error {
+	delete(r.items, invoiceID)
+	return nil
+}
+
+func (r *fakeInvoiceRepository) WithinTx(ctx context.Context, fn func(tx repositories.InvoiceRepository) error) error {
+	return fn(r)
+}
+
+// seedInvoice stores a priced invoice with one 1000.00 line taxed at 10%.
+func seedInvoice(t *testing.T, repo *fakeInvoiceRepository, status models.InvoiceStatus) *models.Invoice {
+	t.Helper()
+	invoice := &models.Invoice{UserID: "user-1", ClientID: "client-1", Status: status, Currency: "USD", TaxRate: dec("10")}
+	items, err := priceInvoice(invoice, []CreateInvoiceItemInput{
+		{Description: "Consulting", Quantity: dec("1"), UnitPrice: dec("1000")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := repo.Create(context.Background(), invoice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range items {
+		items[i].InvoiceID = created.ID
+		if err := repo.CreateItem(context.Background(), &items[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo.itemWrites = 0
+	return created
+}
+
+func TestUpdateInvoiceKeepsPricingWhenOmitted(t *testing.T) {
+	pending := models.InvoiceStatusPending
+	notes := "Thanks for your business"
+	tests := []struct {
+		name  string
+		input UpdateInvoiceInput
+	}{
+		{"status only", UpdateInvoiceInput{Status: &pending}},
+		{"notes only", UpdateInvoiceInput{Notes: &notes}},
+	}
+	for _, tt := range tests {
+		repo := newFakeInvoiceRepository()
+		svc := &InvoiceService{invoices: repo}
+		seeded := seedInvoice(t, repo, models.InvoiceStatusDraft)
+
+		updated, err := svc.Update(context.Background(), seeded.ID, "user-1", tt.input)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		assertAmount(t, tt.name+" tax rate", updated.TaxRate, "10")
+		assertAmount(t, tt.name+" tax", updated.TaxAmount, "100")
+		assertAmount(t, tt.name+" total", updated.Total, "1100")
+		if repo.itemWrites != 0 {
+			t.Errorf("%s: rewrote %d items, want none", tt.name, repo.itemWrites)
+		}
+	}
+}
+
+func TestUpdateInvoiceTaxRateReprices(t *testing.T) {
+	repo := newFakeInvoiceRepository()
+	svc := &InvoiceService{invoices: repo}
+	seeded := seedInvoice(t, repo, models.InvoiceStatusDraft)
+
+	rate := dec("20")
+	updated, err := svc.Update(context.Background(), seeded.ID, "user-1", UpdateInvoiceInput{TaxRate: &rate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertAmount(t, "tax", updated.TaxAmount, "200")
+	assertAmount(t, "total", updated.Total, "1200")
+	if repo.itemWrites != 1 {
+		t.Errorf("rewrote %d items, want 1", repo.itemWrites)
+	}
+}
+
+func TestUpdateInvoiceRejectsPaymentStatuses(t *testing.T) {
+	for _, from := range []models.InvoiceStatus{models.InvoiceStatusPending, models.InvoiceStatusOverdue} {
+		for _, to := range []models.InvoiceStatus{models.InvoiceStatusPaid, models.InvoiceStatusPartiallyPaid} {
+			repo := newFakeInvoiceRepository()
+			svc := &InvoiceService{invoices: repo}
+			seeded := seedInvoice(t, repo, from)
+
+			_, err := svc.Update(context.Background(), seeded.ID, "user-1", UpdateInvoiceInput{Status: &to})
+			if _, ok := AsValidationError(err); !ok {
+				t.Errorf("update %s to %s: error = %v, want validation error", from, to, err)
+			}
+			if stored := repo.invoices[seeded.ID]; stored.Status != from {
+				t.Errorf("update %s to %s stored status %s", from, to, stored.Status)
+			}
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+	"github.com/nava1525/bilio-backend/pkg/pdf"
+)
+
+const (
+	defaultShareLinkDays = 30
+	maxShareLinkDays     = 365
+)
+
+// ErrShareLinkNotFound is returned for unknown, tampered, expired and revoked
+// links alike so the public endpoint does not reveal which links exist.
+var ErrShareLinkNotFound = errors.New("share link not found or expired")
+
+type InvoiceShareService struct {
+	shares   repositories.InvoiceShareRepository
+	invoices *InvoiceService
+}
+
+type CreateShareLinkInput struct {
+	// ExpiresInDays defaults to 30 and may be at most 365.
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// ShareViewer describes who opened a share link, for the view log.
+type ShareViewer struct {
+	IPAddress string
+	UserAgent string
+}
+
+// PublicInvoice is the client-facing view of an invoice served through a
+// share link. It leaves out internal identifiers, payments and audit data.
+type PublicInvoice struct {
+	WorkspaceName      string                  `json:"workspace_name"`
+	ClientName         string                  `json:"client_name"`
+	InvoiceNumber      string                  `json:"invoice_number"`
+	Status             models.InvoiceStatus    `json:"status"`
+	IssueDate          time.Time               `json:"issue_date"`
+	DueDate            *time.Time              `json:"due_date,omitempty"`
+	Currency           string                  `json:"currency"`
+	Items              []PublicInvoiceItem     `json:"items"`
+	Subtotal           money.Decimal           `json:"subtotal"`
+	LineDiscountAmount money.Decimal           `json:"line_discount_amount"`
+	DiscountAmount     money.Decimal           `json:"discount_amount"`
+	TaxAmount          money.Decimal           `json:"tax_amount"`
+	TaxBreakdown       []models.InvoiceTaxLine `json:"tax_breakdown,omitempty"`
+	Total              money.Decimal           `json:"total"`
+	AmountPaid         money.Decimal           `json:"amount_paid"`
+	CreditedAmount     money.Decimal           `json:"credited_amount"`
+	BalanceDue         money.Decimal           `json:"balance_due"`
+	Notes              *string                 `json:"notes,omitempty"`
+	PaymentLink        *string                 `json:"payment_link,omitempty"`
+}
+
+type PublicInvoiceItem struct {
+	Description    string        `json:"description"`
+	Quantity       money.Decimal `json:"quantity"`
+	UnitPrice      money.Decimal `json:"unit_price"`
+	DiscountAmount money.Decimal `json:"discount_amount"`
+	Amount         money.Decimal `json:"amount"`
+}
+
+type invoiceShareItemData struct {
+	Description string
+	Discount    string
+	Quantity    string
+	UnitPrice   string
+	Amount      string
+}
+
+type invoiceShareData struct {
+	invoiceText
+	Brand         invoiceBrandData
+	WorkspaceName string
+	ClientName    string
+	InvoiceNumber string
+	Status        string
+	IssueDate     string
+	DueDate       string
+	Items         []invoiceShareItemData
+	Totals        [][2]string
+	AmountDue     string
+	Notes         string
+	PaymentLink   string
+}
+
+func NewInvoiceShareService(shareRepo repositories.InvoiceShareRepository, invoiceService *InvoiceService) *InvoiceShareService {
+	return &InvoiceShareService{
+		shares:   shareRepo,
+		invoices: invoiceService,
+	}
+}
+
+func (s *InvoiceShareService) List(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceShareLink, error) {
+	if _, err := s.loadInvoice(ctx, invoiceID, userID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shares.ListByInvoice(ctx, invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		links[i].Token = shareToken(&links[i])
+	}
+	return links, nil
+}
+
+// Create issues a new share link for an invoice. Drafts cannot be shared
+// since they have not been issued to the client yet.
+func (s *InvoiceShareService) Create(ctx context.Context, invoiceID string, userID string, input CreateShareLinkInput) (*models.InvoiceShareLink, error) {
+	invoice, err := s.loadInvoice(ctx, invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == models.InvoiceStatusDraft {
+		return nil, newValidationError("draft invoices cannot be shared; send the invoice or mark it pending first")
+	}
+
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = defaultShareLinkDays
+	}
+	if days < 1 || days > maxShareLinkDays {
+		return nil, newValidationError(fmt.Sprintf("expires_in_days must be between 1 and %d", maxShareLinkDays))
+	}
+
+	link := &models.InvoiceShareLink{
+		UserID:    userID,
+		InvoiceID: invoiceID,
+		// Truncated so the signed expiry matches what the database stores.
+		ExpiresAt: time.Now().UTC().AddDate(0, 0, days).Truncate(time.Second),
+	}
+	if err := s.shares.Create(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	link.Token = shareToken(link)
+	return link, nil
+}
+
+// Revoke disables a share link immediately.
+func (s *InvoiceShareService) Revoke(ctx context.Context, invoiceID string, linkID string, userID string) error {
+	found, err := s.shares.Revoke(ctx, linkID, invoiceID, userID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// View returns the client-facing JSON view of the shared invoice.
+func (s *InvoiceShareService) View(ctx context.Context, token string, viewer ShareViewer) (*PublicInvoice, error) {
+	invoice, brand, err := s.open(ctx, token, models.InvoiceShareFormatJSON, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return publicInvoice(invoice, brand.workspace), nil
+}
+
+// ViewHTML renders the shared invoice as a standalone web page.
+func (s *InvoiceShareService) ViewHTML(ctx context.Context, token string, viewer ShareViewer) ([]byte, error) {
+	invoice, brand, err := s.open(ctx, token, models.InvoiceShareFormatHTML, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return renderInvoiceShareHTML(invoice, brand)
+}
+
+// renderInvoiceShareHTML renders the invoice page with the workspace's share
+// template.
+func renderInvoiceShareHTML(invoice *models.Invoice, brand *invoiceBranding) ([]byte, error) {
+	tmpl, err := brand.template(models.TemplateKindShare)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, invoiceShareViewData(invoice, brand)); err != nil {
+		return nil, fmt.Errorf("render shared invoice: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ViewPDF renders the shared invoice as a PDF.
+func (s *InvoiceShareService) ViewPDF(ctx context.Context, token string, viewer ShareViewer, size pdf.PageSize) (*InvoicePDF, error) {
+	invoice, brand, err := s.open(ctx, token, models.InvoiceShareFormatPDF, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	doc := renderInvoicePDF(invoice, brand, size)
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render invoice pdf: %w", err)
+	}
+	return &InvoicePDF{
+		Filename: invoiceFilename(invoice.InvoiceNumber),
+		Content:  buf.Bytes(),
+	}, nil
+}
+
+// open resolves a token to its invoice, client and workspace branding and
+// records the view.
+func (s *InvoiceShareService) open(ctx context.Context, token string, format models.InvoiceShareFormat, viewer ShareViewer) (*models.Invoice, *invoiceBranding, error) {
+	link, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invoice, err := s.invoices.GetByID(ctx, link.InvoiceID, link.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := s.invoices.clients.GetByID(ctx, invoice.ClientID, link.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	invoice.Client = client
+	brand, err := s.invoices.loadBranding(ctx, link.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	view := &models.InvoiceShareView{
+		ShareLinkID: link.ID,
+		InvoiceID:   link.InvoiceID,
+		Format:      format,
+	}
+	if viewer.IPAddress != "" {
+		view.IPAddress = &viewer.IPAddress
+	}
+	if viewer.UserAgent != "" {
+		view.UserAgent = &viewer.UserAgent
+	}
+	if err := s.shares.RecordView(ctx, view); err != nil {
+		return nil, nil, fmt.Errorf("failed to record share view: %w", err)
+	}
+
+	return invoice, brand, nil
+}
+
+// resolve verifies a token's signature and returns its link if the link is
+// still active.
+func (s *InvoiceShareService) resolve(ctx context.Context, token string) (*models.InvoiceShareLink, error) {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return nil, ErrShareLinkNotFound
+	}
+
+	link, err := s.shares.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil || !hmac.Equal([]byte(shareToken(link)), []byte(token)) {
+		return nil, ErrShareLinkNotFound
+	}
+	if link.RevokedAt != nil || !time.Now().UTC().Before(link.ExpiresAt) {
+		return nil, ErrShareLinkNotFound
+	}
+	return link, nil
+}
+
+func (s *InvoiceShareService) loadInvoice(ctx context.Context, invoiceID string, userID string) (*models.Invoice, error) {
+	invoice, err := s.invoices.invoices.GetByID(ctx, invoiceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+// shareToken is the link ID followed by an HMAC over the ID and expiry. The
+// ID alone is a random UUID; the signature stops anyone from building a
+// token for a link without the server's secret.
+func shareToken(link *models.InvoiceShareLink) string {
+	mac := hmac.New(sha256.New, shareLinkSecret())
+	mac.Write([]byte("invoice-share|" + link.ID + "|" + strconv.FormatInt(link.ExpiresAt.Unix(), 10)))
+	return link.ID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func shareLinkSecret() []byte {
+	secret := os.Getenv("SHARE_LINK_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	if secret == "" {
+		secret = "default-secret-change-in-production"
+	}
+	return []byte(secret)
+}
+
+func publicInvoice(invoice *models.Invoice, workspace *models.User) *PublicInvoice {
+	view := &PublicInvoice{
+		WorkspaceName:      workspaceDisplayName(workspace),
+		InvoiceNumber:      invoice.InvoiceNumber,
+		Status:             invoice.Status,
+		IssueDate:          invoice.IssueDate,
+		DueDate:            invoice.DueDate,
+		Currency:           invoice.Currency,
+		Items:              make([]PublicInvoiceItem, len(invoice.Items)),
+		Subtotal:           invoice.Subtotal,
+		LineDiscountAmount: invoice.LineDiscountAmount,
+		DiscountAmount:     invoice.DiscountAmount,
+		TaxAmount:          invoice.TaxAmount,
+		TaxBreakdown:       invoice.TaxBreakdown,
+		Total:              invoice.Total,
+		AmountPaid:         invoice.AmountPaid,
+		CreditedAmount:     invoice.CreditedAmount,
+		BalanceDue:         invoice.BalanceDue,
+		Notes:              invoice.Notes,
+		PaymentLink:        invoice.PaymentLink,
+	}
+	if invoice.Client != nil {
+		view.ClientName = invoice.Client.Name
+	}
+	for i, item := range invoice.Items {
+		view.Items[i] = PublicInvoiceItem{
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			DiscountAmount: item.DiscountAmount,
+			Amount:         item.Amount,
+		}
+	}
+	return view
+}
+
+func invoiceShareViewData(invoice *models.Invoice, brand *invoiceBranding) invoiceShareData {
+	currency := invoice.Currency
+	loc := brand.locale(invoice)
+	data := invoiceShareData{
+		invoiceText:   invoiceText{locale: loc},
+		Brand:         brand.data(brand.logoDataURL(), loc),
+		WorkspaceName: brand.name(),
+		InvoiceNumber: invoice.InvoiceNumber,
+		Status:        invoiceStatusLabel(loc, invoice.Status),
+		IssueDate:     loc.FormatDate(invoice.IssueDate),
+		AmountDue:     loc.FormatMoney(invoice.BalanceDue, currency),
+	}
+	if invoice.Client != nil {
+		data.ClientName = invoice.Client.Name
+	}
+	if invoice.DueDate != nil {
+		data.DueDate = loc.FormatDate(*invoice.DueDate)
+	}
+	if invoice.Notes != nil {
+		data.Notes = strings.TrimSpace(*invoice.Notes)
+	}
+	if invoice.PaymentLink != nil {
+		data.PaymentLink = *invoice.PaymentLink
+	}
+
+	for _, item := range invoice.Items {
+		row := invoiceShareItemData{
+			Description: item.Description,
+			Quantity:    loc.FormatQuantity(item.Quantity),
+			UnitPrice:   loc.FormatMoney(item.UnitPrice, currency),
+			Amount:      loc.FormatMoney(item.Amount, currency),
+		}
+		if item.DiscountAmount.IsPositive() {
+			row.Discount = fmt.Sprintf("%s: -%s", discountLabel(loc, item.Discount), loc.FormatMoney(item.DiscountAmount, currency))
+		}
+		data.Items = append(data.Items, row)
+	}
+
+	data.Totals = invoiceTotalRows(invoice, loc)
+	data.Totals = append(data.Totals, [2]string{loc.T("invoice.total"), loc.FormatMoney(invoice.Total, currency)})
+	data.Totals = append(data.Totals, invoiceSettledRows(invoice, loc)...)
+	return data
+}
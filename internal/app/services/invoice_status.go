@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+)
+
+// invoiceTransitions is the single source of truth for the invoice lifecycle.
+// A status may always "move" to itself; anything not listed here is illegal.
+var invoiceTransitions = map[models.InvoiceStatus][]models.InvoiceStatus{
+	models.InvoiceStatusDraft: {
+		models.InvoiceStatusPending,
+		models.InvoiceStatusCancelled,
+		models.InvoiceStatusVoid,
+	},
+	models.InvoiceStatusPending: {
+		models.InvoiceStatusPartiallyPaid,
+		models.InvoiceStatusPaid,
+		models.InvoiceStatusOverdue,
+		models.InvoiceStatusCancelled,
+		models.InvoiceStatusVoid,
+	},
+	models.InvoiceStatusOverdue: {
+		models.InvoiceStatusPending,
+		models.InvoiceStatusPartiallyPaid,
+		models.InvoiceStatusPaid,
+		models.InvoiceStatusCancelled,
+		models.InvoiceStatusVoid,
+	},
+	models.InvoiceStatusPartiallyPaid: {
+		models.InvoiceStatusPaid,
+		models.InvoiceStatusOverdue,
+	},
+	models.InvoiceStatusPaid:      {},
+	models.InvoiceStatusCancelled: {},
+	models.InvoiceStatusVoid:      {},
+}
+
+// InvalidTransitionError is returned when a caller asks for an invoice status
+// change that the lifecycle does not allow.
+type InvalidTransitionError struct {
+	From models.InvoiceStatus
+	To   models.InvoiceStatus
+}
+
+func (e InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot change invoice status from %s to %s", e.From, e.To)
+}
+
+func AsInvalidTransitionError(err error) (InvalidTransitionError, bool) {
+	var tErr InvalidTransitionError
+	if errors.As(err, &tErr) {
+		return tErr, true
+	}
+	return InvalidTransitionError{}, false
+}
+
+func isKnownInvoiceStatus(status models.InvoiceStatus) bool {
+	_, ok := invoiceTransitions[status]
+	return ok
+}
+
+func canTransitionInvoice(from, to models.InvoiceStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, allowed := range invoiceTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionInvoice validates and applies a status change to invoice.
+func transitionInvoice(invoice *models.Invoice, to models.InvoiceStatus) error {
+	if !isKnownInvoiceStatus(to) {
+		return newValidationError(fmt.Sprintf("unknown invoice status %q", to))
+	}
+	if !canTransitionInvoice(invoice.Status, to) {
+		return InvalidTransitionError{From: invoice.Status, To: to}
+	}
+	invoice.Status = to
+	return nil
+}
+
+// statusesAllowing returns every status that may legally move to target.
+func statusesAllowing(target models.InvoiceStatus) []models.InvoiceStatus {
+	var statuses []models.InvoiceStatus
+	for from := range invoiceTransitions {
+		if from != target && canTransitionInvoice(from, target) {
+			statuses = append(statuses, from)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// defaultTaxComponentName labels the invoice-wide TaxRate when it applies to
+// items without a tax code.
+const defaultTaxComponentName = "Tax"
+
+// loadTaxCodes fetches every tax code referenced by items, keyed by ID.
+func (s *InvoiceService) loadTaxCodes(ctx context.Context, userID string, items []CreateInvoiceItemInput) (map[string]*models.TaxCode, error) {
+	codes := make(map[string]*models.TaxCode)
+	for _, item := range items {
+		if item.TaxCodeID == nil {
+			continue
+		}
+		if _, ok := codes[*item.TaxCodeID]; ok {
+			continue
+		}
+		code, err := s.taxCodes.GetByID(ctx, *item.TaxCodeID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if code == nil {
+			return nil, newValidationError(fmt.Sprintf("tax code %s not found", *item.TaxCodeID))
+		}
+		codes[code.ID] = code
+	}
+	return codes, nil
+}
+
+// priceInvoice computes each item's discount, amount and tax components,
+// then rolls them up into the invoice's subtotal, discount, tax breakdown and
+// totals. Items with a tax code use its components; the rest are taxed at the
+// invoice-wide TaxRate.
+//
+// Item discounts always come off the line before tax. The invoice discount
+// is taken off the subtotal and either shared across the lines' taxable
+// amounts (pre_tax) or deducted from the taxed total (post_tax).
+//
+// Item tax amounts are rounded per line for display. The breakdown rounds
+// once per component over the summed taxable amounts, and it is the
+// breakdown that makes up the invoice's tax total.
+//
+// Late fee lines are added on top of what was billed: they are neither
+// taxed nor reduced by the invoice discount.
+func priceInvoice(invoice *models.Invoice, inputs []CreateInvoiceItemInput, codes map[string]*models.TaxCode) ([]models.InvoiceItem, error) {
+	timing, err := normalizeDiscountTiming(invoice.DiscountTiming)
+	if err != nil {
+		return nil, err
+	}
+	invoice.DiscountTiming = timing
+	if err := validateDiscount(invoice.Discount, "discount"); err != nil {
+		return nil, err
+	}
+
+	items := make([]models.InvoiceItem, len(inputs))
+	amounts := make([]money.Decimal, len(inputs))
+	lineDiscounts := money.Zero
+
+	for i, input := range inputs {
+		field := fmt.Sprintf("items[%d].discount", i)
+		if err := validateDiscount(input.Discount, field); err != nil {
+			return nil, err
+		}
+		gross := lineAmount(input, invoice.Currency)
+		discount, err := discountAmount(input.Discount, gross, invoice.Currency, field)
+		if err != nil {
+			return nil, err
+		}
+
+		amounts[i] = gross.Sub(discount)
+		lineDiscounts = lineDiscounts.Add(discount)
+		items[i] = models.InvoiceItem{
+			InvoiceID:      invoice.ID,
+			Description:    input.Description,
+			Quantity:       input.Quantity,
+			UnitPrice:      input.UnitPrice,
+			Discount:       input.Discount,
+			DiscountAmount: discount,
+			Amount:         amounts[i],
+			TaxCodeID:      input.TaxCodeID,
+			CatalogItemID:  input.CatalogItemID,
+			Unit:           input.Unit,
+			SKU:            input.SKU,
+			LateFee:        input.LateFee,
+		}
+	}
+
+	invoice.Subtotal = money.Sum(amounts...)
+	invoice.LineDiscountAmount = lineDiscounts
+
+	var billed []int
+	var billedAmounts []money.Decimal
+	for i, input := range inputs {
+		if !input.LateFee {
+			billed = append(billed, i)
+			billedAmounts = append(billedAmounts, amounts[i])
+		}
+	}
+	billedSubtotal := money.Sum(billedAmounts...)
+
+	shares := make([]money.Decimal, len(inputs))
+	if invoice.DiscountTiming == models.DiscountTimingPreTax {
+		invoice.DiscountAmount, err = discountAmount(invoice.Discount, billedSubtotal, invoice.Currency, "discount")
+		if err != nil {
+			return nil, err
+		}
+		for j, share := range allocateDiscount(invoice.DiscountAmount, billedAmounts, invoice.Currency) {
+			shares[billed[j]] = share
+		}
+	}
+
+	breakdown := newTaxBreakdown()
+	for i, input := range inputs {
+		if input.LateFee {
+			continue
+		}
+		taxable := amounts[i].Sub(shares[i])
+		for _, component := range itemTaxComponents(invoice, input, codes) {
+			items[i].Taxes = append(items[i].Taxes, models.InvoiceItemTax{
+				Name:   component.Name,
+				Rate:   component.Rate,
+				Amount: roundMoney(percentOf(taxable, component.Rate), invoice.Currency),
+			})
+			breakdown.add(component, taxable)
+		}
+	}
+	invoice.TaxBreakdown = breakdown.lines(invoice.Currency)
+
+	if invoice.DiscountTiming == models.DiscountTimingPostTax {
+		taxed := billedSubtotal.Add(sumTaxLines(invoice.TaxBreakdown))
+		invoice.DiscountAmount, err = discountAmount(invoice.Discount, taxed, invoice.Currency, "discount")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	applyInvoiceTotals(invoice)
+	return items, nil
+}
+
+func itemTaxComponents(invoice *models.Invoice, input CreateInvoiceItemInput, codes map[string]*models.TaxCode) []models.TaxComponent {
+	if input.TaxCodeID != nil {
+		if code, ok := codes[*input.TaxCodeID]; ok {
+			return code.Components
+		}
+	}
+	if invoice.TaxRate.IsZero() {
+		return nil
+	}
+	return []models.TaxComponent{{Name: defaultTaxComponentName, Rate: invoice.TaxRate}}
+}
+
+// taxBreakdown accumulates taxable amounts per component, keeping the order
+// in which components first appear.
+type taxBreakdown struct {
+	order   []string
+	entries map[string]*models.InvoiceTaxLine
+}
+
+func newTaxBreakdown() *taxBreakdown {
+	return &taxBreakdown{entries: make(map[string]*models.InvoiceTaxLine)}
+}
+
+func (b *taxBreakdown) add(component models.TaxComponent, taxable money.Decimal) {
+	key := component.Name + "@" + component.Rate.String()
+	entry, ok := b.entries[key]
+	if !ok {
+		entry = &models.InvoiceTaxLine{Name: component.Name, Rate: component.Rate}
+		b.entries[key] = entry
+		b.order = append(b.order, key)
+	}
+	entry.TaxableAmount = entry.TaxableAmount.Add(taxable)
+}
+
+func (b *taxBreakdown) lines(currency string) []models.InvoiceTaxLine {
+	lines := make([]models.InvoiceTaxLine, 0, len(b.order))
+	for _, key := range b.order {
+		entry := *b.entries[key]
+		entry.Amount = roundMoney(percentOf(entry.TaxableAmount, entry.Rate), currency)
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// percentOf returns rate percent of amount, unrounded.
+func percentOf(amount, rate money.Decimal) money.Decimal {
+	return amount.Mul(rate).Mul(money.New(1, 2))
+}
+
+// applyInvoiceTotals derives tax, total and balance due from the subtotal,
+// invoice discount, tax breakdown, payments and credits already recorded on
+// invoice.
+func applyInvoiceTotals(invoice *models.Invoice) {
+	invoice.TaxAmount = sumTaxLines(invoice.TaxBreakdown)
+	invoice.Total = invoice.Subtotal.Sub(invoice.DiscountAmount).Add(invoice.TaxAmount)
+	invoice.BalanceDue = invoice.Total.Sub(invoice.AmountPaid).Sub(invoice.CreditedAmount)
+}
+
+func sumTaxLines(lines []models.InvoiceTaxLine) money.Decimal {
+	total := money.Zero
+	for _, line := range lines {
+		total = total.Add(line.Amount)
+	}
+	return total
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+func dec(s string) money.Decimal {
+	return money.MustParse(s)
+}
+
+func assertAmount(t *testing.T, name string, got money.Decimal, want string) {
+	t.Helper()
+	if !got.Equal(dec(want)) {
+		t.Errorf("%s = %s, want %s", name, got, want)
+	}
+}
+
+func TestPriceInvoiceTaxComponents(t *testing.T) {
+	gstID := "gst18"
+	codes := map[string]*models.TaxCode{
+		gstID: {ID: gstID, Code: "GST18", Components: []models.TaxComponent{
+			{Name: "CGST", Rate: dec("9")},
+			{Name: "SGST", Rate: dec("9")},
+		}},
+	}
+	invoice := &models.Invoice{Currency: "USD", TaxRate: dec("10")}
+	items, err := priceInvoice(invoice, []CreateInvoiceItemInput{
+		{Description: "Consulting", Quantity: dec("2"), UnitPrice: dec("500"), TaxCodeID: &gstID},
+		{Description: "Hosting", Quantity: dec("1"), UnitPrice: dec("333.33")},
+	}, codes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items[0].Taxes) != 2 || items[0].Taxes[0].Name != "CGST" || items[0].Taxes[1].Name != "SGST" {
+		t.Fatalf("item 0 taxes = %+v, want CGST and SGST", items[0].Taxes)
+	}
+	assertAmount(t, "item 0 CGST", items[0].Taxes[0].Amount, "90.00")
+	assertAmount(t, "item 0 SGST", items[0].Taxes[1].Amount, "90.00")
+	if len(items[1].Taxes) != 1 || items[1].Taxes[0].Name != defaultTaxComponentName {
+		t.Fatalf("item 1 taxes = %+v, want the invoice tax rate", items[1].Taxes)
+	}
+	assertAmount(t, "item 1 tax", items[1].Taxes[0].Amount, "33.33")
+
+	want := []struct{ name, taxable, amount string }{
+		{"CGST", "1000.00", "90.00"},
+		{"SGST", "1000.00", "90.00"},
+		{defaultTaxComponentName, "333.33", "33.33"},
+	}
+	if len(invoice.TaxBreakdown) != len(want) {
+		t.Fatalf("tax breakdown = %+v, want %d lines", invoice.TaxBreakdown, len(want))
+	}
+	for i, w := range want {
+		line := invoice.TaxBreakdown[i]
+		if line.Name != w.name {
+			t.Errorf("breakdown[%d].name = %s, want %s", i, line.Name, w.name)
+		}
+		assertAmount(t, "breakdown "+w.name+" taxable", line.TaxableAmount, w.taxable)
+		assertAmount(t, "breakdown "+w.name+" amount", line.Amount, w.amount)
+	}
+	assertAmount(t, "subtotal", invoice.Subtotal, "1333.33")
+	assertAmount(t, "tax", invoice.TaxAmount, "213.33")
+	assertAmount(t, "total", invoice.Total, "1546.66")
+}
+
+func TestPriceInvoiceRoundsTaxOncePerComponent(t *testing.T) {
+	invoice := &models.Invoice{Currency: "USD", TaxRate: dec("10")}
+	item := CreateInvoiceItemInput{Description: "Widget", Quantity: dec("1"), UnitPrice: dec("0.05")}
+	items, err := priceInvoice(invoice, []CreateInvoiceItemInput{item, item, item}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each line shows 0.005 rounded to 0.01, but the invoice taxes the
+	// summed 0.15 once: 0.015 rounds to 0.02.
+	for _, it := range items {
+		assertAmount(t, "line tax", it.Taxes[0].Amount, "0.01")
+	}
+	assertAmount(t, "tax", invoice.TaxAmount, "0.02")
+	assertAmount(t, "total", invoice.Total, "0.17")
+}
+
+func TestPriceInvoiceUntaxed(t *testing.T) {
+	invoice := &models.Invoice{Currency: "JPY"}
+	items, err := priceInvoice(invoice, []CreateInvoiceItemInput{
+		{Description: "Service", Quantity: dec("3"), UnitPrice: dec("333.5")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items[0].Taxes) != 0 || len(invoice.TaxBreakdown) != 0 {
+		t.Errorf("untaxed invoice has taxes %+v and breakdown %+v", items[0].Taxes, invoice.TaxBreakdown)
+	}
+	assertAmount(t, "amount", items[0].Amount, "1001")
+	assertAmount(t, "total", invoice.Total, "1001")
+}
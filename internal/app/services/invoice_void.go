@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+var (
+	ErrInvoiceNotDeletable = errors.New("only draft invoices can be deleted; void issued invoices instead")
+	ErrDraftNumberInUse    = errors.New("only the most recently numbered draft can be deleted; void this draft instead so its number stays accounted for")
+)
+
+type VoidInvoiceInput struct {
+	Reason string `json:"reason"`
+}
+
+// Delete permanently removes a draft invoice. Issued invoices have been seen
+// by the client and must be voided instead so their number stays reserved.
+// A draft's number is handed back to the sequence for the next invoice, which
+// is only possible while no later number has been allocated; older drafts are
+// voided instead so the sequence keeps no gap.
+func (s *InvoiceService) Delete(ctx context.Context, id string, userID string) error {
+	// Attachment records go with the invoice; their files are removed once
+	// the delete has committed.
+	attachments, err := s.attachments.ListByInvoice(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		invoice, err := tx.GetByIDForUpdate(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+		if invoice == nil {
+			return ErrInvoiceNotFound
+		}
+		if invoice.Status != models.InvoiceStatusDraft {
+			return ErrInvoiceNotDeletable
+		}
+		released, err := tx.ReleaseNumber(ctx, invoice)
+		if err != nil {
+			return fmt.Errorf("failed to release invoice number: %w", err)
+		}
+		if !released {
+			return ErrDraftNumberInUse
+		}
+
+		if err := tx.Delete(ctx, id, userID); err != nil {
+			return fmt.Errorf("failed to delete invoice: %w", err)
+		}
+		return tx.CreateEvent(ctx, &models.InvoiceEvent{
+			UserID:        userID,
+			InvoiceID:     id,
+			InvoiceNumber: invoice.InvoiceNumber,
+			Action:        models.InvoiceEventDeleted,
+			FromStatus:    invoice.Status,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.removeAttachmentFiles(ctx, attachments)
+	return nil
+}
+
+// Void withdraws an issued invoice, or a draft that can no longer be deleted.
+// The invoice keeps its number and contents but can no longer be changed,
+// paid, sent or credited. Invoices that have received payments or credit
+// notes must be settled with a credit note instead, since voiding would leave
+// that money unaccounted for.
+func (s *InvoiceService) Void(ctx context.Context, id string, userID string, input VoidInvoiceInput) (*models.Invoice, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, newValidationError("reason is required")
+	}
+
+	var voided *models.Invoice
+	err := s.invoices.WithinTx(ctx, func(tx repositories.InvoiceRepository) error {
+		invoice, err := tx.GetByIDForUpdate(ctx, id, userID)
+		if err != nil {
+			return err
+		}
+		if invoice == nil {
+			return ErrInvoiceNotFound
+		}
+		if invoice.Status == models.InvoiceStatusVoid {
+			return newValidationError("invoice is already void")
+		}
+		if !invoice.AmountPaid.IsZero() || invoice.CreditedAmount.IsPositive() {
+			return newValidationError("invoices with payments or credit notes cannot be voided; issue a credit note instead")
+		}
+
+		from := invoice.Status
+		if err := transitionInvoice(invoice, models.InvoiceStatusVoid); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		invoice.VoidedAt = &now
+		invoice.VoidReason = &reason
+
+		if err := tx.Void(ctx, invoice); err != nil {
+			return fmt.Errorf("failed to void invoice: %w", err)
+		}
+		to := invoice.Status
+		if err := tx.CreateEvent(ctx, &models.InvoiceEvent{
+			UserID:        userID,
+			InvoiceID:     id,
+			InvoiceNumber: invoice.InvoiceNumber,
+			Action:        models.InvoiceEventVoided,
+			FromStatus:    from,
+			ToStatus:      &to,
+			Reason:        &reason,
+		}); err != nil {
+			return fmt.Errorf("failed to record invoice event: %w", err)
+		}
+
+		voided = invoice
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return voided, nil
+}
+
+// History returns the audit events recorded for an invoice, oldest first.
+// Events of deleted drafts remain available.
+func (s *InvoiceService) History(ctx context.Context, id string, userID string) ([]models.InvoiceEvent, error) {
+	return s.invoices.ListEvents(ctx, id, userID)
+}
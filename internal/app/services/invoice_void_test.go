@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+type fakeAttachmentRepository struct {
+	repositories.InvoiceAttachmentRepository
+}
+
+func (fakeAttachmentRepository) ListByInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceAttachment, error) {
+	return nil, nil
+}
+
+func TestDeleteDraftKeepsNumberingGapFree(t *testing.T) {
+	repo := newFakeInvoiceRepository()
+	svc := &InvoiceService{invoices: repo, attachments: fakeAttachmentRepository{}}
+	first := seedInvoice(t, repo, models.InvoiceStatusDraft)
+	second := seedInvoice(t, repo, models.InvoiceStatusDraft)
+
+	// The first draft's number has been followed, so deleting it would leave
+	// a hole; it has to be voided.
+	if err := svc.Delete(context.Background(), first.ID, "user-1"); !errors.Is(err, ErrDraftNumberInUse) {
+		t.Fatalf("delete %s: error = %v, want ErrDraftNumberInUse", first.InvoiceNumber, err)
+	}
+	if _, ok := repo.invoices[first.ID]; !ok {
+		t.Fatalf("%s was deleted", first.InvoiceNumber)
+	}
+	voided, err := svc.Void(context.Background(), first.ID, "user-1", VoidInvoiceInput{Reason: "Raised by mistake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voided.Status != models.InvoiceStatusVoid || voided.InvoiceNumber != "INV-0001" {
+		t.Errorf("voided draft = %s %s, want void INV-0001", voided.Status, voided.InvoiceNumber)
+	}
+
+	// The latest draft hands its number to the next invoice.
+	if err := svc.Delete(context.Background(), second.ID, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.invoices[second.ID]; ok {
+		t.Fatalf("%s was not deleted", second.InvoiceNumber)
+	}
+	next := seedInvoice(t, repo, models.InvoiceStatusDraft)
+	if next.InvoiceNumber != "INV-0002" {
+		t.Errorf("invoice after delete numbered %s, want INV-0002", next.InvoiceNumber)
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+const (
+	// lateFeeBatchSize bounds how many invoices one late fee pass loads.
+	lateFeeBatchSize = 100
+
+	maxLateFeeGraceDays   = 365
+	maxLateFeeRatePercent = 100
+	// maxLateFeeCapScale matches the invoice amount columns.
+	maxLateFeeCapScale = 3
+)
+
+var ErrLateFeePolicyNotFound = errors.New("late fee policy not found")
+
+type LateFeeService struct {
+	lateFees repositories.LateFeeRepository
+	invoices *InvoiceService
+}
+
+// UpdateLateFeePolicyInput sets a late fee policy. Amount is the flat fee, or
+// the monthly interest rate in percent for percentage policies.
+type UpdateLateFeePolicyInput struct {
+	Enabled   bool               `json:"enabled"`
+	FeeType   models.LateFeeType `json:"fee_type"`
+	Amount    money.Decimal      `json:"amount"`
+	GraceDays int                `json:"grace_days"`
+	MaxAmount *money.Decimal     `json:"max_amount,omitempty"`
+}
+
+// LateFeeRunResult describes one invoice handled by the late fee scheduler.
+type LateFeeRunResult struct {
+	InvoiceID string
+	UserID    string
+	Fees      []models.InvoiceLateFee
+	Err       error
+}
+
+func NewLateFeeService(lateFeeRepo repositories.LateFeeRepository, invoiceService *InvoiceService) *LateFeeService {
+	return &LateFeeService{
+		lateFees: lateFeeRepo,
+		invoices: invoiceService,
+	}
+}
+
+// GetPolicy returns the workspace's late fee policy, or a disabled flat fee
+// policy if none has been saved.
+func (s *LateFeeService) GetPolicy(ctx context.Context, userID string) (*models.LateFeePolicy, error) {
+	policy, err := s.lateFees.GetPolicy(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &models.LateFeePolicy{UserID: userID, FeeType: models.LateFeeFlat}
+	}
+	return policy, nil
+}
+
+func (s *LateFeeService) UpdatePolicy(ctx context.Context, userID string, input UpdateLateFeePolicyInput) (*models.LateFeePolicy, error) {
+	return s.savePolicy(ctx, userID, nil, input)
+}
+
+// GetClientPolicy returns the client's override of the workspace policy.
+func (s *LateFeeService) GetClientPolicy(ctx context.Context, clientID string, userID string) (*models.LateFeePolicy, error) {
+	if err := s.ensureClientExists(ctx, clientID, userID); err != nil {
+		return nil, err
+	}
+	policy, err := s.lateFees.GetPolicy(ctx, userID, &clientID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, ErrLateFeePolicyNotFound
+	}
+	return policy, nil
+}
+
+// UpdateClientPolicy saves a policy that replaces the workspace policy for
+// the client's invoices. A disabled client policy exempts the client.
+func (s *LateFeeService) UpdateClientPolicy(ctx context.Context, clientID string, userID string, input UpdateLateFeePolicyInput) (*models.LateFeePolicy, error) {
+	if err := s.ensureClientExists(ctx, clientID, userID); err != nil {
+		return nil, err
+	}
+	return s.savePolicy(ctx, userID, &clientID, input)
+}
+
+// DeleteClientPolicy removes the client's override so the workspace policy
+// applies again.
+func (s *LateFeeService) DeleteClientPolicy(ctx context.Context, clientID string, userID string) error {
+	deleted, err := s.lateFees.DeleteClientPolicy(ctx, userID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete late fee policy: %w", err)
+	}
+	if !deleted {
+		return ErrLateFeePolicyNotFound
+	}
+	return nil
+}
+
+// ListForInvoice returns the late fees charged on an invoice, by period.
+func (s *LateFeeService) ListForInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceLateFee, error) {
+	if err := s.invoices.ensureInvoiceExists(ctx, invoiceID, userID); err != nil {
+		return nil, err
+	}
+	return s.invoices.invoices.ListLateFees(ctx, invoiceID)
+}
+
+// ChargeDue charges the late fees due on overdue invoices under each
+// invoice's effective policy. Fees that fell due while the worker was down
+// are caught up, one per period. It is safe to run repeatedly and from
+// several processes at once.
+func (s *LateFeeService) ChargeDue(ctx context.Context, now time.Time) ([]LateFeeRunResult, error) {
+	today := dateOnly(now)
+
+	due, err := s.lateFees.ListDue(ctx, models.InvoiceStatusOverdue, today, lateFeeBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("list due late fees: %w", err)
+	}
+
+	results := make([]LateFeeRunResult, 0, len(due))
+	for i := range due {
+		fees, err := s.invoices.ChargeLateFees(ctx, due[i].InvoiceID, due[i].UserID, &due[i].Policy, today)
+		results = append(results, LateFeeRunResult{
+			InvoiceID: due[i].InvoiceID,
+			UserID:    due[i].UserID,
+			Fees:      fees,
+			Err:       err,
+		})
+	}
+	return results, nil
+}
+
+func (s *LateFeeService) savePolicy(ctx context.Context, userID string, clientID *string, input UpdateLateFeePolicyInput) (*models.LateFeePolicy, error) {
+	if err := validateLateFeePolicy(input); err != nil {
+		return nil, err
+	}
+
+	policy := &models.LateFeePolicy{
+		UserID:    userID,
+		ClientID:  clientID,
+		Enabled:   input.Enabled,
+		FeeType:   input.FeeType,
+		Amount:    input.Amount,
+		GraceDays: input.GraceDays,
+		MaxAmount: input.MaxAmount,
+	}
+	if err := s.lateFees.SavePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save late fee policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (s *LateFeeService) ensureClientExists(ctx context.Context, clientID string, userID string) error {
+	client, err := s.invoices.clients.GetByID(ctx, clientID, userID)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return ErrClientNotFound
+	}
+	return nil
+}
+
+func validateLateFeePolicy(input UpdateLateFeePolicyInput) error {
+	if input.FeeType != models.LateFeeFlat && input.FeeType != models.LateFeePercentage {
+		return newValidationError(`fee_type must be "flat" or "percentage"`)
+	}
+	if !input.Amount.IsPositive() {
+		return newValidationError("amount must be greater than 0")
+	}
+	if !input.Amount.Round(maxUnitPriceScale, money.Down).Equal(input.Amount) {
+		return newValidationError(fmt.Sprintf("amount %s has more than %d decimal places", input.Amount, maxUnitPriceScale))
+	}
+	if input.FeeType == models.LateFeePercentage && input.Amount.GreaterThan(money.NewFromInt(maxLateFeeRatePercent)) {
+		return newValidationError(fmt.Sprintf("amount must be at most %d percent per month", maxLateFeeRatePercent))
+	}
+	if input.GraceDays < 0 || input.GraceDays > maxLateFeeGraceDays {
+		return newValidationError(fmt.Sprintf("grace_days must be between 0 and %d", maxLateFeeGraceDays))
+	}
+	if input.MaxAmount != nil {
+		if !input.MaxAmount.IsPositive() {
+			return newValidationError("max_amount must be greater than 0")
+		}
+		if !input.MaxAmount.Round(maxLateFeeCapScale, money.Down).Equal(*input.MaxAmount) {
+			return newValidationError(fmt.Sprintf("max_amount %s has more than %d decimal places", *input.MaxAmount, maxLateFeeCapScale))
+		}
+	}
+	return nil
+}
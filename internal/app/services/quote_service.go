@@ -0,0 +1,707 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+var (
+	ErrQuoteNotFound         = errors.New("quote not found")
+	ErrQuoteNotDeletable     = errors.New("only draft quotes can be deleted")
+	ErrQuoteAlreadyConverted = errors.New("quote has already been converted into an invoice")
+	// ErrQuoteChanged is returned when the quote's status changed while it
+	// was being updated, e.g. the client declined it during a conversion.
+	ErrQuoteChanged = errors.New("quote was changed at the same time; reload it and try again")
+	// ErrQuoteLinkNotFound is returned for unknown and tampered links, and
+	// for drafts, so the public endpoint does not reveal which quotes exist.
+	ErrQuoteLinkNotFound = errors.New("quote link not found")
+)
+
+// quoteTransitions is the quote lifecycle. Accepted and declined are final;
+// an expired quote can be sent again once its expiry date is moved out.
+var quoteTransitions = map[models.QuoteStatus][]models.QuoteStatus{
+	models.QuoteStatusDraft: {
+		models.QuoteStatusSent,
+	},
+	models.QuoteStatusSent: {
+		models.QuoteStatusAccepted,
+		models.QuoteStatusDeclined,
+		models.QuoteStatusExpired,
+	},
+	models.QuoteStatusExpired: {
+		models.QuoteStatusSent,
+	},
+	models.QuoteStatusAccepted: {},
+	models.QuoteStatusDeclined: {},
+}
+
+// InvalidQuoteTransitionError is returned when a caller asks for a quote
+// status change that the lifecycle does not allow.
+type InvalidQuoteTransitionError struct {
+	From models.QuoteStatus
+	To   models.QuoteStatus
+}
+
+func (e InvalidQuoteTransitionError) Error() string {
+	return fmt.Sprintf("cannot change quote status from %s to %s", e.From, e.To)
+}
+
+func AsInvalidQuoteTransitionError(err error) (InvalidQuoteTransitionError, bool) {
+	var tErr InvalidQuoteTransitionError
+	if errors.As(err, &tErr) {
+		return tErr, true
+	}
+	return InvalidQuoteTransitionError{}, false
+}
+
+type QuoteService struct {
+	quotes   repositories.QuoteRepository
+	invoices *InvoiceService
+}
+
+type CreateQuoteInput struct {
+	ClientID       string                   `json:"client_id"`
+	QuoteNumber    string                   `json:"quote_number"`
+	IssueDate      time.Time                `json:"issue_date"`
+	ExpiryDate     *time.Time               `json:"expiry_date,omitempty"`
+	Currency       string                   `json:"currency"`
+	TaxRate        money.Decimal            `json:"tax_rate"`
+	Discount       *models.Discount         `json:"discount,omitempty"`
+	DiscountTiming models.DiscountTiming    `json:"discount_timing,omitempty"`
+	Notes          *string                  `json:"notes,omitempty"`
+	Items          []CreateInvoiceItemInput `json:"items"`
+}
+
+// UpdateQuoteInput keeps the quote's tax rate and discount when they are
+// omitted, as with invoices; ClearDiscount removes the discount.
+type UpdateQuoteInput struct {
+	Status         *models.QuoteStatus      `json:"status,omitempty"`
+	IssueDate      *time.Time               `json:"issue_date,omitempty"`
+	ExpiryDate     *time.Time               `json:"expiry_date,omitempty"`
+	Currency       string                   `json:"currency"`
+	TaxRate        *money.Decimal           `json:"tax_rate,omitempty"`
+	Discount       *models.Discount         `json:"discount,omitempty"`
+	DiscountTiming models.DiscountTiming    `json:"discount_timing,omitempty"`
+	ClearDiscount  bool                     `json:"clear_discount,omitempty"`
+	Notes          *string                  `json:"notes,omitempty"`
+	Items          []CreateInvoiceItemInput `json:"items,omitempty"`
+}
+
+// reprices reports whether the update changes anything the quote's items and
+// totals are priced from.
+func (input UpdateQuoteInput) reprices() bool {
+	return len(input.Items) > 0 || input.TaxRate != nil || input.Currency != "" ||
+		input.Discount != nil || input.ClearDiscount || input.DiscountTiming != ""
+}
+
+type QuoteFilters struct {
+	Status   *models.QuoteStatus
+	ClientID *string
+}
+
+// ConvertQuoteInput sets the dates of the invoice created from a quote. The
+// issue date defaults to today.
+type ConvertQuoteInput struct {
+	IssueDate *time.Time `json:"issue_date,omitempty"`
+	DueDate   *time.Time `json:"due_date,omitempty"`
+}
+
+// AcceptQuoteInput is sent by the client accepting a quote through its link.
+type AcceptQuoteInput struct {
+	Name string `json:"name"`
+}
+
+// DeclineQuoteInput is sent by the client declining a quote through its link.
+type DeclineQuoteInput struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+// PublicQuote is the client-facing view of a quote served through its link.
+type PublicQuote struct {
+	WorkspaceName      string                  `json:"workspace_name"`
+	ClientName         string                  `json:"client_name"`
+	QuoteNumber        string                  `json:"quote_number"`
+	Status             models.QuoteStatus      `json:"status"`
+	IssueDate          time.Time               `json:"issue_date"`
+	ExpiryDate         *time.Time              `json:"expiry_date,omitempty"`
+	Currency           string                  `json:"currency"`
+	Items              []PublicInvoiceItem     `json:"items"`
+	Subtotal           money.Decimal           `json:"subtotal"`
+	LineDiscountAmount money.Decimal           `json:"line_discount_amount"`
+	DiscountAmount     money.Decimal           `json:"discount_amount"`
+	TaxAmount          money.Decimal           `json:"tax_amount"`
+	TaxBreakdown       []models.InvoiceTaxLine `json:"tax_breakdown,omitempty"`
+	Total              money.Decimal           `json:"total"`
+	Notes              *string                 `json:"notes,omitempty"`
+	AcceptedAt         *time.Time              `json:"accepted_at,omitempty"`
+	DeclinedAt         *time.Time              `json:"declined_at,omitempty"`
+}
+
+func NewQuoteService(quoteRepo repositories.QuoteRepository, invoiceService *InvoiceService) *QuoteService {
+	return &QuoteService{
+		quotes:   quoteRepo,
+		invoices: invoiceService,
+	}
+}
+
+func (s *QuoteService) List(ctx context.Context, userID string, filters QuoteFilters) ([]models.Quote, error) {
+	quotes, err := s.quotes.List(ctx, userID, repositories.QuoteFilters{
+		Status:   filters.Status,
+		ClientID: filters.ClientID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range quotes {
+		quotes[i].Token = quoteToken(&quotes[i])
+	}
+	return quotes, nil
+}
+
+func (s *QuoteService) GetByID(ctx context.Context, id string, userID string) (*models.Quote, error) {
+	quote, err := s.quotes.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil {
+		return nil, ErrQuoteNotFound
+	}
+
+	items, err := s.quotes.GetItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	quote.Items = items
+	quote.Token = quoteToken(quote)
+
+	return quote, nil
+}
+
+// Create adds a draft quote. An empty number is allocated from the
+// workspace's quote sequence.
+func (s *QuoteService) Create(ctx context.Context, userID string, input CreateQuoteInput) (*models.Quote, error) {
+	client, err := s.invoices.clients.GetByID(ctx, input.ClientID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("client not found")
+	}
+
+	if len(input.Items) == 0 {
+		return nil, newValidationError("at least one item is required")
+	}
+	if err := validateInvoiceItems(input.Items); err != nil {
+		return nil, err
+	}
+	if input.Currency == "" {
+		input.Currency = "USD"
+	}
+	if err := s.invoices.applyCatalogItems(ctx, userID, input.Currency, input.Items); err != nil {
+		return nil, err
+	}
+	if input.IssueDate.IsZero() {
+		input.IssueDate = dateOnly(time.Now())
+	}
+	if err := validateQuoteDates(input.IssueDate, input.ExpiryDate); err != nil {
+		return nil, err
+	}
+
+	taxCodes, err := s.invoices.loadTaxCodes(ctx, userID, input.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &models.Quote{
+		UserID:         userID,
+		ClientID:       input.ClientID,
+		QuoteNumber:    strings.TrimSpace(input.QuoteNumber),
+		Status:         models.QuoteStatusDraft,
+		IssueDate:      input.IssueDate,
+		ExpiryDate:     input.ExpiryDate,
+		Currency:       input.Currency,
+		TaxRate:        input.TaxRate,
+		Discount:       input.Discount,
+		DiscountTiming: input.DiscountTiming,
+		Notes:          input.Notes,
+	}
+	if quote.Items, err = priceQuote(quote, input.Items, taxCodes); err != nil {
+		return nil, err
+	}
+
+	if err := s.quotes.Create(ctx, quote); err != nil {
+		if errors.Is(err, repositories.ErrQuoteNumberExists) {
+			return nil, newValidationError(fmt.Sprintf("quote number %s is already in use", quote.QuoteNumber))
+		}
+		return nil, fmt.Errorf("failed to create quote: %w", err)
+	}
+	return quote, nil
+}
+
+// Update edits a draft, sent or expired quote and applies any status change.
+// Accepted and declined quotes are final.
+func (s *QuoteService) Update(ctx context.Context, id string, userID string, input UpdateQuoteInput) (*models.Quote, error) {
+	quote, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status == models.QuoteStatusAccepted || quote.Status == models.QuoteStatusDeclined {
+		return nil, newValidationError(fmt.Sprintf("%s quotes cannot be changed", quote.Status))
+	}
+	readStatus := quote.Status
+
+	if input.IssueDate != nil {
+		quote.IssueDate = *input.IssueDate
+	}
+	if input.ExpiryDate != nil {
+		quote.ExpiryDate = input.ExpiryDate
+	}
+	if err := validateQuoteDates(quote.IssueDate, quote.ExpiryDate); err != nil {
+		return nil, err
+	}
+	if input.Currency != "" {
+		quote.Currency = input.Currency
+	}
+	if input.TaxRate != nil {
+		quote.TaxRate = *input.TaxRate
+	}
+	if input.ClearDiscount && input.Discount != nil {
+		return nil, newValidationError("discount and clear_discount cannot be used together")
+	}
+	if input.ClearDiscount {
+		quote.Discount = nil
+	}
+	if input.Discount != nil {
+		quote.Discount = input.Discount
+	}
+	if input.DiscountTiming != "" {
+		quote.DiscountTiming = input.DiscountTiming
+	}
+	if input.Notes != nil {
+		quote.Notes = input.Notes
+	}
+
+	now := time.Now().UTC()
+	if input.Status != nil && *input.Status != quote.Status {
+		if err := transitionQuote(quote, *input.Status, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// As with invoices, prices and totals are re-derived from the items when
+	// a field they are priced from is present, so a tax or discount change
+	// alone keeps them consistent and a status change leaves them alone.
+	if input.reprices() {
+		if err := s.reprice(ctx, userID, quote, input.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.quotes.Update(ctx, quote, readStatus); err != nil {
+		return nil, quoteWriteError(err, "update quote")
+	}
+	quote.Token = quoteToken(quote)
+	return quote, nil
+}
+
+// reprice prices quote from itemInputs, or from its current items when none
+// are given.
+func (s *QuoteService) reprice(ctx context.Context, userID string, quote *models.Quote, itemInputs []CreateInvoiceItemInput) error {
+	if len(itemInputs) > 0 {
+		if err := s.invoices.applyCatalogItems(ctx, userID, quote.Currency, itemInputs); err != nil {
+			return err
+		}
+	} else {
+		for _, item := range quote.Items {
+			itemInputs = append(itemInputs, quoteItemInput(item))
+		}
+	}
+	if err := validateInvoiceItems(itemInputs); err != nil {
+		return err
+	}
+	taxCodes, err := s.invoices.loadTaxCodes(ctx, userID, itemInputs)
+	if err != nil {
+		return err
+	}
+	quote.Items, err = priceQuote(quote, itemInputs, taxCodes)
+	return err
+}
+
+// Delete removes a draft quote. Quotes that have been sent stay on record.
+func (s *QuoteService) Delete(ctx context.Context, id string, userID string) error {
+	quote, err := s.quotes.GetByID(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if quote == nil {
+		return ErrQuoteNotFound
+	}
+	if quote.Status != models.QuoteStatusDraft {
+		return ErrQuoteNotDeletable
+	}
+	return s.quotes.Delete(ctx, id, userID)
+}
+
+// Convert creates a draft invoice from a sent or accepted quote, copying its
+// client, items, taxes, discounts, currency and notes. The invoice records
+// the quote it came from and a quote converts at most once. Converting a
+// sent quote marks it accepted.
+func (s *QuoteService) Convert(ctx context.Context, id string, userID string, input ConvertQuoteInput) (*models.Invoice, error) {
+	quote, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.InvoiceID != nil {
+		return nil, ErrQuoteAlreadyConverted
+	}
+	if quote.Status != models.QuoteStatusSent && quote.Status != models.QuoteStatusAccepted {
+		return nil, newValidationError(fmt.Sprintf("%s quotes cannot be converted; only sent or accepted quotes can", quote.Status))
+	}
+	now := time.Now().UTC()
+	readStatus := quote.Status
+	if quote.Status == models.QuoteStatusSent {
+		if err := transitionQuote(quote, models.QuoteStatusAccepted, now); err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]CreateInvoiceItemInput, len(quote.Items))
+	for i, item := range quote.Items {
+		items[i] = quoteItemInput(item)
+	}
+	issueDate := dateOnly(time.Now())
+	if input.IssueDate != nil {
+		issueDate = *input.IssueDate
+	}
+
+	// Accepting the quote, creating the invoice and linking the two commit
+	// together. The status write goes first so it holds the quote's row lock,
+	// and fails if the client declined it in the meantime; the invoice's
+	// unique quote_id guarantees a single conversion.
+	var invoice *models.Invoice
+	err = s.quotes.WithinTx(ctx, func(quotes repositories.QuoteRepository, invoices repositories.InvoiceRepository) error {
+		if err := quotes.UpdateStatus(ctx, quote, readStatus); err != nil {
+			return quoteWriteError(err, "mark quote accepted")
+		}
+
+		var err error
+		invoice, err = s.invoices.create(ctx, invoices, userID, CreateInvoiceInput{
+			ClientID:       quote.ClientID,
+			Status:         models.InvoiceStatusDraft,
+			IssueDate:      issueDate,
+			DueDate:        input.DueDate,
+			Currency:       quote.Currency,
+			TaxRate:        &quote.TaxRate,
+			Discount:       quote.Discount,
+			DiscountTiming: quote.DiscountTiming,
+			Notes:          quote.Notes,
+			Items:          items,
+			QuoteID:        &quote.ID,
+		})
+		if err != nil {
+			if errors.Is(err, repositories.ErrQuoteAlreadyInvoiced) {
+				return ErrQuoteAlreadyConverted
+			}
+			return err
+		}
+
+		linked, err := quotes.MarkConverted(ctx, quote.ID, userID, invoice.ID, now)
+		if err != nil {
+			return fmt.Errorf("failed to link quote to invoice: %w", err)
+		}
+		if !linked {
+			return ErrQuoteAlreadyConverted
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// quoteWriteError reports a status-guarded quote write that lost a race as
+// ErrQuoteChanged.
+func quoteWriteError(err error, action string) error {
+	if errors.Is(err, repositories.ErrQuoteStatusChanged) {
+		return ErrQuoteChanged
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// ExpireQuotes marks every sent quote whose expiry date is before the
+// calendar day of now (UTC) as expired. It is safe to run repeatedly and from
+// several processes at once.
+func (s *QuoteService) ExpireQuotes(ctx context.Context, now time.Time) (int64, error) {
+	count, err := s.quotes.ExpireSent(ctx, dateOnly(now))
+	if err != nil {
+		return 0, fmt.Errorf("expire quotes: %w", err)
+	}
+	return count, nil
+}
+
+// View returns the client-facing view of the quote behind token.
+func (s *QuoteService) View(ctx context.Context, token string) (*PublicQuote, error) {
+	quote, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.publicQuote(ctx, quote)
+}
+
+// Accept records the client's acceptance of a sent quote through its link.
+func (s *QuoteService) Accept(ctx context.Context, token string, input AcceptQuoteInput) (*PublicQuote, error) {
+	quote, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, newValidationError("name is required to accept a quote")
+	}
+
+	now := time.Now().UTC()
+	readStatus := quote.Status
+	if err := transitionQuote(quote, models.QuoteStatusAccepted, now); err != nil {
+		return nil, err
+	}
+	quote.AcceptedBy = &name
+	if err := s.quotes.UpdateStatus(ctx, quote, readStatus); err != nil {
+		return nil, quoteWriteError(err, "accept quote")
+	}
+	return s.publicQuote(ctx, quote)
+}
+
+// Decline records the client's rejection of a sent quote through its link.
+func (s *QuoteService) Decline(ctx context.Context, token string, input DeclineQuoteInput) (*PublicQuote, error) {
+	quote, err := s.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	readStatus := quote.Status
+	if err := transitionQuote(quote, models.QuoteStatusDeclined, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if input.Reason != nil {
+		if reason := strings.TrimSpace(*input.Reason); reason != "" {
+			quote.DeclineReason = &reason
+		}
+	}
+	if err := s.quotes.UpdateStatus(ctx, quote, readStatus); err != nil {
+		return nil, quoteWriteError(err, "decline quote")
+	}
+	return s.publicQuote(ctx, quote)
+}
+
+// resolve verifies a token's signature and returns its quote. Drafts have
+// not been issued to the client and are treated as unknown.
+func (s *QuoteService) resolve(ctx context.Context, token string) (*models.Quote, error) {
+	id, _, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return nil, ErrQuoteLinkNotFound
+	}
+
+	quote, err := s.quotes.GetForLink(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil || quote.Status == models.QuoteStatusDraft {
+		return nil, ErrQuoteLinkNotFound
+	}
+	if !hmac.Equal([]byte(signQuoteToken(quote.ID)), []byte(token)) {
+		return nil, ErrQuoteLinkNotFound
+	}
+
+	// The expiry sweep runs periodically; a quote past its date is treated
+	// as expired even if the sweep has not reached it yet.
+	if quote.Status == models.QuoteStatusSent && quoteExpired(quote, time.Now()) {
+		quote.Status = models.QuoteStatusExpired
+	}
+
+	items, err := s.quotes.GetItems(ctx, quote.ID)
+	if err != nil {
+		return nil, err
+	}
+	quote.Items = items
+	return quote, nil
+}
+
+func (s *QuoteService) publicQuote(ctx context.Context, quote *models.Quote) (*PublicQuote, error) {
+	client, err := s.invoices.clients.GetByID(ctx, quote.ClientID, quote.UserID)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.invoices.users.GetByID(ctx, quote.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &PublicQuote{
+		WorkspaceName:      workspaceDisplayName(workspace),
+		QuoteNumber:        quote.QuoteNumber,
+		Status:             quote.Status,
+		IssueDate:          quote.IssueDate,
+		ExpiryDate:         quote.ExpiryDate,
+		Currency:           quote.Currency,
+		Items:              make([]PublicInvoiceItem, len(quote.Items)),
+		Subtotal:           quote.Subtotal,
+		LineDiscountAmount: quote.LineDiscountAmount,
+		DiscountAmount:     quote.DiscountAmount,
+		TaxAmount:          quote.TaxAmount,
+		TaxBreakdown:       quote.TaxBreakdown,
+		Total:              quote.Total,
+		Notes:              quote.Notes,
+		AcceptedAt:         quote.AcceptedAt,
+		DeclinedAt:         quote.DeclinedAt,
+	}
+	if client != nil {
+		view.ClientName = client.Name
+	}
+	for i, item := range quote.Items {
+		view.Items[i] = PublicInvoiceItem{
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			DiscountAmount: item.DiscountAmount,
+			Amount:         item.Amount,
+		}
+	}
+	return view, nil
+}
+
+// transitionQuote validates and applies a status change to quote, stamping
+// the time of the change.
+func transitionQuote(quote *models.Quote, to models.QuoteStatus, now time.Time) error {
+	if _, known := quoteTransitions[to]; !known {
+		return newValidationError(fmt.Sprintf("unknown quote status %q", to))
+	}
+	if !canTransitionQuote(quote.Status, to) {
+		return InvalidQuoteTransitionError{From: quote.Status, To: to}
+	}
+
+	switch to {
+	case models.QuoteStatusSent:
+		if quoteExpired(quote, now) {
+			return newValidationError("expiry_date is in the past; move it out before sending the quote")
+		}
+		quote.SentAt = &now
+	case models.QuoteStatusAccepted:
+		if quoteExpired(quote, now) {
+			return InvalidQuoteTransitionError{From: models.QuoteStatusExpired, To: to}
+		}
+		quote.AcceptedAt = &now
+	case models.QuoteStatusDeclined:
+		quote.DeclinedAt = &now
+	}
+	quote.Status = to
+	return nil
+}
+
+func canTransitionQuote(from, to models.QuoteStatus) bool {
+	for _, allowed := range quoteTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteExpired reports whether the quote's expiry date is before the
+// calendar day of now. A quote is valid through its expiry date.
+func quoteExpired(quote *models.Quote, now time.Time) bool {
+	return quote.ExpiryDate != nil && dateOnly(*quote.ExpiryDate).Before(dateOnly(now))
+}
+
+func validateQuoteDates(issueDate time.Time, expiryDate *time.Time) error {
+	if expiryDate != nil && dateOnly(*expiryDate).Before(dateOnly(issueDate)) {
+		return newValidationError("expiry_date cannot be before issue_date")
+	}
+	return nil
+}
+
+// priceQuote prices the quote's items exactly as an invoice's would be and
+// copies the resulting totals onto the quote.
+func priceQuote(quote *models.Quote, inputs []CreateInvoiceItemInput, codes map[string]*models.TaxCode) ([]models.QuoteItem, error) {
+	priced := &models.Invoice{
+		Currency:       quote.Currency,
+		TaxRate:        quote.TaxRate,
+		Discount:       quote.Discount,
+		DiscountTiming: quote.DiscountTiming,
+	}
+	invoiceItems, err := priceInvoice(priced, inputs, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Subtotal = priced.Subtotal
+	quote.LineDiscountAmount = priced.LineDiscountAmount
+	quote.DiscountTiming = priced.DiscountTiming
+	quote.DiscountAmount = priced.DiscountAmount
+	quote.TaxAmount = priced.TaxAmount
+	quote.TaxBreakdown = priced.TaxBreakdown
+	quote.Total = priced.Total
+
+	items := make([]models.QuoteItem, len(invoiceItems))
+	for i, item := range invoiceItems {
+		items[i] = models.QuoteItem{
+			QuoteID:        quote.ID,
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			Discount:       item.Discount,
+			DiscountAmount: item.DiscountAmount,
+			Amount:         item.Amount,
+			TaxCodeID:      item.TaxCodeID,
+			Taxes:          item.Taxes,
+			CatalogItemID:  item.CatalogItemID,
+			Unit:           item.Unit,
+			SKU:            item.SKU,
+		}
+	}
+	return items, nil
+}
+
+// quoteItemInput re-prices a quote item as it was quoted, keeping what it
+// copied from the catalog.
+func quoteItemInput(item models.QuoteItem) CreateInvoiceItemInput {
+	return CreateInvoiceItemInput{
+		Description:    item.Description,
+		Quantity:       item.Quantity,
+		UnitPrice:      item.UnitPrice,
+		Discount:       item.Discount,
+		TaxCodeID:      item.TaxCodeID,
+		CatalogItemID:  item.CatalogItemID,
+		Unit:           item.Unit,
+		SKU:            item.SKU,
+		CatalogApplied: true,
+	}
+}
+
+// quoteToken returns the public link token for a quote that has been issued
+// to the client. Drafts have no link.
+func quoteToken(quote *models.Quote) string {
+	if quote.Status == models.QuoteStatusDraft {
+		return ""
+	}
+	return signQuoteToken(quote.ID)
+}
+
+// signQuoteToken is the quote ID followed by an HMAC over it, so a token
+// cannot be built for a quote without the server's secret.
+func signQuoteToken(id string) string {
+	mac := hmac.New(sha256.New, shareLinkSecret())
+	mac.Write([]byte("quote|" + id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
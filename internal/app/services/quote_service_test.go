@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+// fakeQuoteRepository holds a single quote in memory.
+type fakeQuoteRepository struct {
+	repositories.QuoteRepository
+	quote *models.Quote
+}
+
+func (r *fakeQuoteRepository) GetByID(ctx context.Context, id string, userID string) (*models.Quote, error) {
+	if r.quote == nil || r.quote.ID != id || r.quote.UserID != userID {
+		return nil, nil
+	}
+	copied := *r.quote
+	copied.Items = nil
+	return &copied, nil
+}
+
+func (r *fakeQuoteRepository) GetItems(ctx context.Context, quoteID string) ([]models.QuoteItem, error) {
+	return append([]models.QuoteItem(nil), r.quote.Items...), nil
+}
+
+func (r *fakeQuoteRepository) Update(ctx context.Context, quote *models.Quote, expected models.QuoteStatus) error {
+	if r.quote.Status != expected {
+		return repositories.ErrQuoteStatusChanged
+	}
+	stored := *quote
+	r.quote = &stored
+	return nil
+}
+
+func TestUpdateQuoteStatusKeepsTaxRate(t *testing.T) {
+	quote := &models.Quote{ID: "quote-1", UserID: "user-1", Status: models.QuoteStatusDraft, Currency: "USD", TaxRate: dec("10")}
+	items, err := priceQuote(quote, []CreateInvoiceItemInput{
+		{Description: "Consulting", Quantity: dec("1"), UnitPrice: dec("1000")},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote.Items = items
+	repo := &fakeQuoteRepository{quote: quote}
+	svc := &QuoteService{quotes: repo}
+
+	sent := models.QuoteStatusSent
+	updated, err := svc.Update(context.Background(), "quote-1", "user-1", UpdateQuoteInput{Status: &sent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != models.QuoteStatusSent {
+		t.Errorf("status = %s, want sent", updated.Status)
+	}
+	assertAmount(t, "tax rate", updated.TaxRate, "10")
+	assertAmount(t, "tax", updated.TaxAmount, "100")
+	assertAmount(t, "total", updated.Total, "1100")
+	assertAmount(t, "stored total", repo.quote.Total, "1100")
+}
@@ -0,0 +1,437 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/cron"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+const (
+	// recurringBatchSize bounds how many schedules one generator pass loads.
+	recurringBatchSize = 100
+	// recurringMaxCatchUp bounds how many missed occurrences of a single
+	// schedule are generated in one pass after downtime.
+	recurringMaxCatchUp = 12
+)
+
+var ErrRecurringInvoiceNotFound = errors.New("recurring invoice not found")
+
+type RecurringInvoiceService struct {
+	recurring repositories.RecurringInvoiceRepository
+	clients   repositories.ClientRepository
+	invoices  *InvoiceService
+}
+
+type CreateRecurringInvoiceInput struct {
+	ClientID         string                   `json:"client_id"`
+	Name             string                   `json:"name"`
+	Currency         string                   `json:"currency"`
+	TaxRate          money.Decimal            `json:"tax_rate"`
+	Discount         *models.Discount         `json:"discount,omitempty"`
+	DiscountTiming   models.DiscountTiming    `json:"discount_timing,omitempty"`
+	Notes            *string                  `json:"notes,omitempty"`
+	Items            []CreateInvoiceItemInput `json:"items"`
+	Cadence          models.RecurringCadence  `json:"cadence"`
+	CronExpression   *string                  `json:"cron_expression,omitempty"`
+	StartDate        time.Time                `json:"start_date"`
+	EndDate          *time.Time               `json:"end_date,omitempty"`
+	PaymentTermsDays *int                     `json:"payment_terms_days,omitempty"`
+	AutoSend         bool                     `json:"auto_send"`
+}
+
+// UpdateRecurringInvoiceInput replaces the schedule's template, except that
+// an omitted TaxRate keeps the schedule's current rate.
+type UpdateRecurringInvoiceInput struct {
+	CreateRecurringInvoiceInput
+	TaxRate *money.Decimal `json:"tax_rate,omitempty"`
+	Active  *bool          `json:"active,omitempty"`
+}
+
+// RecurringRunResult describes one occurrence handled by the generator.
+type RecurringRunResult struct {
+	RecurringInvoiceID string
+	UserID             string
+	Period             time.Time
+	InvoiceID          string
+	InvoiceNumber      string
+	Duplicate          bool
+	Sent               bool
+	Err                error
+	// SendErr is set when the invoice was generated but could not be sent.
+	// The invoice stays a draft for the user to send and the schedule still
+	// moves on.
+	SendErr error
+}
+
+func NewRecurringInvoiceService(recurringRepo repositories.RecurringInvoiceRepository, clientRepo repositories.ClientRepository, invoiceService *InvoiceService) *RecurringInvoiceService {
+	return &RecurringInvoiceService{
+		recurring: recurringRepo,
+		clients:   clientRepo,
+		invoices:  invoiceService,
+	}
+}
+
+func (s *RecurringInvoiceService) List(ctx context.Context, userID string) ([]models.RecurringInvoice, error) {
+	return s.recurring.List(ctx, userID)
+}
+
+func (s *RecurringInvoiceService) GetByID(ctx context.Context, id string, userID string) (*models.RecurringInvoice, error) {
+	recurring, err := s.recurring.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if recurring == nil {
+		return nil, ErrRecurringInvoiceNotFound
+	}
+	return recurring, nil
+}
+
+func (s *RecurringInvoiceService) Create(ctx context.Context, userID string, input CreateRecurringInvoiceInput) (*models.RecurringInvoice, error) {
+	recurring := &models.RecurringInvoice{
+		UserID: userID,
+		Active: true,
+	}
+	if err := s.apply(ctx, recurring, input, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.recurring.Create(ctx, recurring)
+}
+
+func (s *RecurringInvoiceService) Update(ctx context.Context, id string, userID string, input UpdateRecurringInvoiceInput) (*models.RecurringInvoice, error) {
+	recurring, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Active != nil {
+		recurring.Active = *input.Active
+	}
+	input.CreateRecurringInvoiceInput.TaxRate = recurring.TaxRate
+	if input.TaxRate != nil {
+		input.CreateRecurringInvoiceInput.TaxRate = *input.TaxRate
+	}
+	if err := s.apply(ctx, recurring, input.CreateRecurringInvoiceInput, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.recurring.Update(ctx, recurring)
+}
+
+func (s *RecurringInvoiceService) Delete(ctx context.Context, id string, userID string) error {
+	if _, err := s.GetByID(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.recurring.Delete(ctx, id, userID)
+}
+
+// apply validates input, copies it onto recurring and schedules the next run
+// on or after today, so that saving a schedule never back-fills past periods.
+func (s *RecurringInvoiceService) apply(ctx context.Context, recurring *models.RecurringInvoice, input CreateRecurringInvoiceInput, now time.Time) error {
+	client, err := s.clients.GetByID(ctx, input.ClientID, recurring.UserID)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return newValidationError("client not found")
+	}
+
+	if strings.TrimSpace(input.Name) == "" {
+		return newValidationError("name is required")
+	}
+	if len(input.Items) == 0 {
+		return newValidationError("at least one item is required")
+	}
+	if err := validateInvoiceItems(input.Items); err != nil {
+		return err
+	}
+	if input.StartDate.IsZero() {
+		return newValidationError("start_date is required")
+	}
+	if input.EndDate != nil && input.EndDate.Before(input.StartDate) {
+		return newValidationError("end_date must not be before start_date")
+	}
+	if input.PaymentTermsDays != nil && *input.PaymentTermsDays < 0 {
+		return newValidationError("payment_terms_days must not be negative")
+	}
+
+	switch input.Cadence {
+	case models.RecurringCadenceWeekly, models.RecurringCadenceMonthly,
+		models.RecurringCadenceQuarterly, models.RecurringCadenceYearly:
+		input.CronExpression = nil
+	case models.RecurringCadenceCustom:
+		if input.CronExpression == nil || strings.TrimSpace(*input.CronExpression) == "" {
+			return newValidationError("cron_expression is required for custom cadence")
+		}
+		if _, err := cron.Parse(*input.CronExpression); err != nil {
+			return newValidationError(fmt.Sprintf("invalid cron_expression: %v", err))
+		}
+	default:
+		return newValidationError("cadence must be one of weekly, monthly, quarterly, yearly or custom")
+	}
+
+	if input.Currency == "" {
+		input.Currency = client.Currency
+	}
+	catalogCurrency := input.Currency
+	if catalogCurrency == "" {
+		catalogCurrency = "USD"
+	}
+	if err := s.invoices.applyCatalogItems(ctx, recurring.UserID, catalogCurrency, input.Items); err != nil {
+		return err
+	}
+
+	// Price a sample invoice so tax codes and discounts that could never
+	// produce a valid invoice are rejected now rather than on every run.
+	taxCodes, err := s.invoices.loadTaxCodes(ctx, recurring.UserID, input.Items)
+	if err != nil {
+		return err
+	}
+	sample := &models.Invoice{
+		Currency:       input.Currency,
+		TaxRate:        input.TaxRate,
+		Discount:       input.Discount,
+		DiscountTiming: input.DiscountTiming,
+	}
+	if _, err := priceInvoice(sample, input.Items, taxCodes); err != nil {
+		return err
+	}
+
+	items := make([]models.RecurringInvoiceItem, len(input.Items))
+	for i, item := range input.Items {
+		items[i] = models.RecurringInvoiceItem{
+			Description:   item.Description,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			Discount:      item.Discount,
+			TaxCodeID:     item.TaxCodeID,
+			CatalogItemID: item.CatalogItemID,
+			Unit:          item.Unit,
+			SKU:           item.SKU,
+		}
+	}
+
+	recurring.ClientID = input.ClientID
+	recurring.Name = strings.TrimSpace(input.Name)
+	recurring.Currency = input.Currency
+	recurring.TaxRate = input.TaxRate
+	recurring.Discount = input.Discount
+	recurring.DiscountTiming = sample.DiscountTiming
+	recurring.Notes = input.Notes
+	recurring.Items = items
+	recurring.Cadence = input.Cadence
+	recurring.CronExpression = input.CronExpression
+	recurring.StartDate = dateOnly(input.StartDate)
+	recurring.EndDate = input.EndDate
+	recurring.PaymentTermsDays = input.PaymentTermsDays
+	recurring.AutoSend = input.AutoSend
+	recurring.NextRunDate = firstOccurrenceOnOrAfter(recurring, dateOnly(now))
+
+	return nil
+}
+
+// GenerateDue creates invoices for every schedule whose next run date has
+// arrived. Each occurrence is keyed by (schedule, period) in the invoices
+// table, so a restart or a second worker never produces a duplicate.
+func (s *RecurringInvoiceService) GenerateDue(ctx context.Context, now time.Time) ([]RecurringRunResult, error) {
+	today := dateOnly(now)
+
+	due, err := s.recurring.ListDue(ctx, today, recurringBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("list due recurring invoices: %w", err)
+	}
+
+	var results []RecurringRunResult
+	for i := range due {
+		recurring := &due[i]
+		for run := 0; run < recurringMaxCatchUp; run++ {
+			if recurring.NextRunDate == nil || recurring.NextRunDate.After(today) {
+				break
+			}
+			period := *recurring.NextRunDate
+
+			result := s.generate(ctx, recurring, period)
+			results = append(results, result)
+			if result.Err != nil {
+				break
+			}
+
+			next := nextOccurrence(recurring, period)
+			advanced, err := s.recurring.Advance(ctx, recurring.ID, period, next, now.UTC())
+			if err != nil {
+				return results, fmt.Errorf("advance recurring invoice %s: %w", recurring.ID, err)
+			}
+			if !advanced {
+				// Another worker already moved this schedule on.
+				break
+			}
+			recurring.NextRunDate = next
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RecurringInvoiceService) generate(ctx context.Context, recurring *models.RecurringInvoice, period time.Time) RecurringRunResult {
+	result := RecurringRunResult{
+		RecurringInvoiceID: recurring.ID,
+		UserID:             recurring.UserID,
+		Period:             period,
+	}
+
+	items := make([]CreateInvoiceItemInput, len(recurring.Items))
+	for i, item := range recurring.Items {
+		items[i] = CreateInvoiceItemInput{
+			Description:    item.Description,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			Discount:       item.Discount,
+			TaxCodeID:      item.TaxCodeID,
+			CatalogItemID:  item.CatalogItemID,
+			Unit:           item.Unit,
+			SKU:            item.SKU,
+			CatalogApplied: true,
+		}
+	}
+
+	var dueDate *time.Time
+	if recurring.PaymentTermsDays != nil {
+		d := period.AddDate(0, 0, *recurring.PaymentTermsDays)
+		dueDate = &d
+	}
+
+	recurringID := recurring.ID
+	input := CreateInvoiceInput{
+		ClientID:           recurring.ClientID,
+		Status:             models.InvoiceStatusDraft,
+		IssueDate:          period,
+		DueDate:            dueDate,
+		Currency:           recurring.Currency,
+		TaxRate:            &recurring.TaxRate,
+		Discount:           recurring.Discount,
+		DiscountTiming:     recurring.DiscountTiming,
+		Notes:              recurring.Notes,
+		Items:              items,
+		RecurringInvoiceID: &recurringID,
+		RecurringPeriod:    &period,
+	}
+
+	invoice, err := s.invoices.Create(ctx, recurring.UserID, input)
+	if errors.Is(err, repositories.ErrRecurringPeriodAlreadyBilled) {
+		result.Duplicate = true
+		return result
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.InvoiceID = invoice.ID
+	result.InvoiceNumber = invoice.InvoiceNumber
+
+	if recurring.AutoSend {
+		// A failed send leaves the generated invoice as a draft for the user
+		// to deliver manually; the occurrence itself is still complete.
+		if _, err := s.invoices.Send(ctx, invoice.ID, recurring.UserID, SendInvoiceInput{}); err != nil {
+			result.SendErr = fmt.Errorf("auto-send invoice %s: %w", invoice.InvoiceNumber, err)
+		} else {
+			result.Sent = true
+		}
+	}
+
+	return result
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func cadenceMonths(cadence models.RecurringCadence) int {
+	switch cadence {
+	case models.RecurringCadenceMonthly:
+		return 1
+	case models.RecurringCadenceQuarterly:
+		return 3
+	case models.RecurringCadenceYearly:
+		return 12
+	}
+	return 0
+}
+
+// addMonthsClamped adds months to t, clamping to the last day of the target
+// month so that a schedule anchored on the 31st runs on Feb 28/29.
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// nextOccurrence returns the occurrence after prev, or nil once the schedule
+// has passed its end date.
+func nextOccurrence(recurring *models.RecurringInvoice, prev time.Time) *time.Time {
+	var next time.Time
+
+	switch recurring.Cadence {
+	case models.RecurringCadenceWeekly:
+		next = prev.AddDate(0, 0, 7)
+	case models.RecurringCadenceCustom:
+		if recurring.CronExpression == nil {
+			return nil
+		}
+		schedule, err := cron.Parse(*recurring.CronExpression)
+		if err != nil {
+			return nil
+		}
+		next = schedule.NextDate(prev)
+		if next.IsZero() {
+			return nil
+		}
+	default:
+		step := cadenceMonths(recurring.Cadence)
+		if step == 0 {
+			return nil
+		}
+		// Occurrences are always computed from the start date, not from prev,
+		// so clamping in short months does not drift the anchor day.
+		start := recurring.StartDate
+		elapsed := (prev.Year()-start.Year())*12 + int(prev.Month()) - int(start.Month())
+		next = addMonthsClamped(start, (elapsed/step+1)*step)
+	}
+
+	if recurring.EndDate != nil && next.After(dateOnly(*recurring.EndDate)) {
+		return nil
+	}
+	return &next
+}
+
+// firstOccurrenceOnOrAfter returns the earliest occurrence that is on or after
+// both the start date and from.
+func firstOccurrenceOnOrAfter(recurring *models.RecurringInvoice, from time.Time) *time.Time {
+	first := recurring.StartDate
+	if recurring.Cadence == models.RecurringCadenceCustom {
+		next := nextOccurrence(recurring, first.AddDate(0, 0, -1))
+		if next == nil {
+			return nil
+		}
+		first = *next
+	}
+	if recurring.EndDate != nil && first.After(dateOnly(*recurring.EndDate)) {
+		return nil
+	}
+
+	current := &first
+	for current != nil && current.Before(from) {
+		current = nextOccurrence(recurring, *current)
+	}
+	return current
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+func TestAddMonthsClamped(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		from   time.Time
+		months int
+		want   time.Time
+	}{
+		{day(2024, 1, 15), 1, day(2024, 2, 15)},
+		{day(2024, 1, 31), 1, day(2024, 2, 29)},
+		{day(2023, 1, 31), 1, day(2023, 2, 28)},
+		{day(2024, 1, 31), 3, day(2024, 4, 30)},
+		{day(2024, 3, 31), -1, day(2024, 2, 29)},
+		{day(2024, 11, 30), 3, day(2025, 2, 28)},
+		{day(2024, 2, 29), 12, day(2025, 2, 28)},
+		{day(2024, 5, 31), 0, day(2024, 5, 31)},
+	}
+	for _, tt := range tests {
+		if got := addMonthsClamped(tt.from, tt.months); !got.Equal(tt.want) {
+			t.Errorf("addMonthsClamped(%s, %d) = %s, want %s", tt.from.Format("2006-01-02"), tt.months, got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+// fakeRecurringRepository serves one schedule and records how it advances.
+type fakeRecurringRepository struct {
+	repositories.RecurringInvoiceRepository
+	recurring models.RecurringInvoice
+}
+
+func (r *fakeRecurringRepository) ListDue(ctx context.Context, asOf time.Time, limit int) ([]models.RecurringInvoice, error) {
+	if r.recurring.NextRunDate == nil || r.recurring.NextRunDate.After(asOf) {
+		return nil, nil
+	}
+	return []models.RecurringInvoice{r.recurring}, nil
+}
+
+func (r *fakeRecurringRepository) Advance(ctx context.Context, id string, previousRun time.Time, nextRun *time.Time, ranAt time.Time) (bool, error) {
+	if r.recurring.NextRunDate == nil || !r.recurring.NextRunDate.Equal(previousRun) {
+		return false, nil
+	}
+	r.recurring.NextRunDate = nextRun
+	r.recurring.LastRunAt = &ranAt
+	return true, nil
+}
+
+func TestGenerateDueAdvancesWhenAutoSendFails(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+	first := day(time.January, 1)
+	recurring := &fakeRecurringRepository{recurring: models.RecurringInvoice{
+		ID: "recurring-1", UserID: "user-1", ClientID: "client-1", Currency: "USD",
+		Cadence: models.RecurringCadenceMonthly, StartDate: first, NextRunDate: &first, AutoSend: true, Active: true,
+		Items: []models.RecurringInvoiceItem{{Description: "Retainer", Quantity: dec("1"), UnitPrice: dec("500")}},
+	}}
+	invoices := newFakeInvoiceRepository()
+	// No mailer is configured, so every auto-send fails.
+	invoiceService := &InvoiceService{
+		invoices: invoices,
+		clients:  fakeClientRepository{client: &models.Client{ID: "client-1", UserID: "user-1", Currency: "USD"}},
+	}
+	svc := &RecurringInvoiceService{recurring: recurring, invoices: invoiceService}
+
+	results, err := svc.GenerateDue(context.Background(), day(time.February, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("generated %d occurrences, want 2 (January and February)", len(results))
+	}
+	for _, res := range results {
+		if res.Err != nil || res.InvoiceID == "" {
+			t.Errorf("%s: invoice %q, error %v; want an invoice", res.Period.Format("2006-01-02"), res.InvoiceID, res.Err)
+		}
+		if res.SendErr == nil || res.Sent {
+			t.Errorf("%s: sent %v, send error %v; want a send error", res.Period.Format("2006-01-02"), res.Sent, res.SendErr)
+		}
+	}
+	if next := recurring.recurring.NextRunDate; next == nil || !next.Equal(day(time.March, 1)) {
+		t.Errorf("next run = %v, want 2024-03-01", next)
+	}
+	for id, invoice := range invoices.invoices {
+		if invoice.Status != models.InvoiceStatusDraft {
+			t.Errorf("%s status = %s, want draft", id, invoice.Status)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	templates "github.com/nava1525/bilio-backend/internal/templates"
+	"github.com/nava1525/bilio-backend/pkg/locale"
+	"github.com/nava1525/bilio-backend/pkg/mailer"
+)
+
+const (
+	// reminderBatchSize bounds how many invoices one reminder pass loads.
+	reminderBatchSize = 100
+
+	maxReminderSteps       = 10
+	minReminderOffsetDays  = -60
+	maxReminderOffsetDays  = 365
+	maxReminderSubjectSize = 200
+	maxReminderBodySize    = 5000
+)
+
+var (
+	reminderEmailHTML = htmltemplate.Must(htmltemplate.New("reminder.html").Parse(templates.ReminderEmailHTML))
+	reminderEmailText = texttemplate.Must(texttemplate.New("reminder.txt").Parse(templates.ReminderEmailText))
+
+	reminderPlaceholder = regexp.MustCompile(`\{[a-z_]+\}`)
+)
+
+// reminderPlaceholders lists the tokens a reminder subject or body may use.
+var reminderPlaceholders = map[string]bool{
+	"{client_name}":    true,
+	"{workspace_name}": true,
+	"{invoice_number}": true,
+	"{amount_due}":     true,
+	"{due_date}":       true,
+	"{days_overdue}":   true,
+	"{days_until_due}": true,
+	"{payment_link}":   true,
+}
+
+// reminderOpenStatuses are the statuses reminders are sent for. Paid,
+// cancelled and void invoices drop out of the schedule on their own.
+var reminderOpenStatuses = []models.InvoiceStatus{
+	models.InvoiceStatusPending,
+	models.InvoiceStatusOverdue,
+	models.InvoiceStatusPartiallyPaid,
+}
+
+// defaultReminderSteps is the schedule offered to workspaces that have not
+// saved a policy yet: a heads-up before the due date, one on the day, then
+// two escalating overdue notices.
+var defaultReminderSteps = []models.ReminderStep{
+	{
+		OffsetDays: -3,
+		Subject:    "Upcoming: invoice {invoice_number} is due on {due_date}",
+		Body:       "Hi {client_name},\n\nA friendly reminder that invoice {invoice_number} for {amount_due} is due in {days_until_due} days, on {due_date}.\n\nThank you,\n{workspace_name}",
+	},
+	{
+		OffsetDays: 0,
+		Subject:    "Invoice {invoice_number} is due today",
+		Body:       "Hi {client_name},\n\nInvoice {invoice_number} for {amount_due} is due today. If you have already paid, please disregard this message.\n\nThank you,\n{workspace_name}",
+	},
+	{
+		OffsetDays: 7,
+		Subject:    "Overdue: invoice {invoice_number}",
+		Body:       "Hi {client_name},\n\nInvoice {invoice_number} for {amount_due} was due on {due_date} and is now {days_overdue} days overdue. Please arrange payment at your earliest convenience.\n\nThank you,\n{workspace_name}",
+	},
+	{
+		OffsetDays: 14,
+		Subject:    "Second notice: invoice {invoice_number} is {days_overdue} days overdue",
+		Body:       "Hi {client_name},\n\nWe have not yet received payment for invoice {invoice_number}, which was due on {due_date}. The outstanding balance is {amount_due}. Please settle it promptly or reply to let us know when we can expect payment.\n\nRegards,\n{workspace_name}",
+	},
+}
+
+type ReminderService struct {
+	reminders repositories.ReminderRepository
+	invoices  *InvoiceService
+}
+
+type UpdateReminderPolicyInput struct {
+	Enabled bool                  `json:"enabled"`
+	Steps   []models.ReminderStep `json:"steps"`
+}
+
+// ReminderRunResult describes one reminder handled by the scheduler.
+type ReminderRunResult struct {
+	InvoiceID     string
+	InvoiceNumber string
+	UserID        string
+	OffsetDays    int
+	Status        models.InvoiceReminderStatus
+	// Duplicate is set when another worker already handled the step.
+	Duplicate bool
+	Err       error
+}
+
+type reminderEmailData struct {
+	invoiceText
+	Subject       string
+	WorkspaceName string
+	InvoiceNumber string
+	DueDate       string
+	AmountDue     string
+	PaymentLink   string
+	Body          string
+}
+
+func NewReminderService(reminderRepo repositories.ReminderRepository, invoiceService *InvoiceService) *ReminderService {
+	return &ReminderService{
+		reminders: reminderRepo,
+		invoices:  invoiceService,
+	}
+}
+
+// GetPolicy returns the workspace's reminder policy, or the disabled default
+// schedule if none has been saved.
+func (s *ReminderService) GetPolicy(ctx context.Context, userID string) (*models.ReminderPolicy, error) {
+	policy, err := s.reminders.GetPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		steps := make([]models.ReminderStep, len(defaultReminderSteps))
+		copy(steps, defaultReminderSteps)
+		policy = &models.ReminderPolicy{UserID: userID, Steps: steps}
+	}
+	return policy, nil
+}
+
+// UpdatePolicy replaces the workspace's reminder policy. Steps are stored in
+// offset order.
+func (s *ReminderService) UpdatePolicy(ctx context.Context, userID string, input UpdateReminderPolicyInput) (*models.ReminderPolicy, error) {
+	steps, err := normalizeReminderSteps(input.Steps)
+	if err != nil {
+		return nil, err
+	}
+	if input.Enabled && len(steps) == 0 {
+		return nil, newValidationError("at least one step is required when reminders are enabled")
+	}
+
+	policy := &models.ReminderPolicy{
+		UserID:  userID,
+		Enabled: input.Enabled,
+		Steps:   steps,
+	}
+	if err := s.reminders.SavePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save reminder policy: %w", err)
+	}
+	return policy, nil
+}
+
+// ListForInvoice returns every reminder sent or skipped for an invoice.
+func (s *ReminderService) ListForInvoice(ctx context.Context, invoiceID string, userID string) ([]models.InvoiceReminder, error) {
+	if _, err := s.invoices.GetByID(ctx, invoiceID, userID); err != nil {
+		return nil, err
+	}
+	return s.reminders.ListByInvoice(ctx, invoiceID, userID)
+}
+
+// SendDue emails the latest due reminder step for every open invoice in a
+// workspace with reminders enabled. Steps that fell due while the worker was
+// down are not sent in a burst; only the most recent one goes out. It is safe
+// to run repeatedly and from several processes at once.
+func (s *ReminderService) SendDue(ctx context.Context, now time.Time) ([]ReminderRunResult, error) {
+	if s.invoices.mailer == nil {
+		return nil, fmt.Errorf("email sender not configured")
+	}
+	today := dateOnly(now)
+
+	due, err := s.reminders.ListDue(ctx, reminderOpenStatuses, today, reminderBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("list due reminders: %w", err)
+	}
+
+	var results []ReminderRunResult
+	for i := range due {
+		step, ok := latestDueStep(&due[i], today)
+		if !ok {
+			continue
+		}
+		results = append(results, s.send(ctx, &due[i], step, today))
+	}
+
+	return results, nil
+}
+
+func (s *ReminderService) send(ctx context.Context, candidate *repositories.ReminderCandidate, step models.ReminderStep, today time.Time) ReminderRunResult {
+	result := ReminderRunResult{
+		InvoiceID:  candidate.InvoiceID,
+		UserID:     candidate.UserID,
+		OffsetDays: step.OffsetDays,
+	}
+
+	invoice, err := s.invoices.GetByID(ctx, candidate.InvoiceID, candidate.UserID)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.InvoiceNumber = invoice.InvoiceNumber
+
+	client, err := s.invoices.clients.GetByID(ctx, invoice.ClientID, candidate.UserID)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	invoice.Client = client
+	workspace, err := s.invoices.users.GetByID(ctx, candidate.UserID)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	profile, err := s.invoices.workspaces.GetProfile(ctx, candidate.UserID)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	workspaceLocale := DefaultLocale
+	if profile != nil {
+		workspaceLocale = profile.Locale
+	}
+
+	msg, err := buildReminderEmail(invoice, workspace, invoiceLocale(invoice, workspaceLocale), step, today)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	reminder := &models.InvoiceReminder{
+		UserID:     candidate.UserID,
+		InvoiceID:  candidate.InvoiceID,
+		OffsetDays: step.OffsetDays,
+		Status:     models.InvoiceReminderSent,
+		Subject:    msg.Subject,
+	}
+	// Without an address the step is recorded as skipped so the schedule
+	// moves on instead of retrying every run.
+	if client == nil || client.Email == nil || strings.TrimSpace(*client.Email) == "" {
+		reminder.Status = models.InvoiceReminderSkipped
+	} else {
+		recipient := strings.TrimSpace(*client.Email)
+		reminder.SentTo = &recipient
+		msg.To = recipient
+		msg.Cc = client.CCEmails
+	}
+	result.Status = reminder.Status
+
+	claimed, err := s.reminders.Claim(ctx, reminder)
+	if err != nil {
+		result.Err = fmt.Errorf("record reminder: %w", err)
+		return result
+	}
+	if !claimed {
+		result.Duplicate = true
+		return result
+	}
+	if reminder.Status == models.InvoiceReminderSkipped {
+		return result
+	}
+
+	if err := s.invoices.mailer.Send(ctx, msg); err != nil {
+		result.Err = fmt.Errorf("send reminder email: %w", err)
+		if releaseErr := s.reminders.Release(ctx, reminder.ID); releaseErr != nil {
+			result.Err = fmt.Errorf("%w (and failed to release claim: %v)", result.Err, releaseErr)
+		}
+	}
+	return result
+}
+
+// latestDueStep picks the latest step on or before today that comes after
+// the last step already handled.
+func latestDueStep(candidate *repositories.ReminderCandidate, today time.Time) (models.ReminderStep, bool) {
+	var latest models.ReminderStep
+	found := false
+	for _, step := range candidate.Steps {
+		if candidate.DueDate.AddDate(0, 0, step.OffsetDays).After(today) {
+			continue
+		}
+		if candidate.LastOffset != nil && step.OffsetDays <= *candidate.LastOffset {
+			continue
+		}
+		if !found || step.OffsetDays > latest.OffsetDays {
+			latest = step
+			found = true
+		}
+	}
+	return latest, found
+}
+
+func normalizeReminderSteps(input []models.ReminderStep) ([]models.ReminderStep, error) {
+	if len(input) > maxReminderSteps {
+		return nil, newValidationError(fmt.Sprintf("a policy may have at most %d steps", maxReminderSteps))
+	}
+
+	steps := make([]models.ReminderStep, 0, len(input))
+	seen := make(map[int]bool, len(input))
+	for i, step := range input {
+		label := fmt.Sprintf("steps[%d]", i)
+		if step.OffsetDays < minReminderOffsetDays || step.OffsetDays > maxReminderOffsetDays {
+			return nil, newValidationError(fmt.Sprintf("%s offset_days must be between %d and %d", label, minReminderOffsetDays, maxReminderOffsetDays))
+		}
+		if seen[step.OffsetDays] {
+			return nil, newValidationError(fmt.Sprintf("%s offset_days %d is used by another step", label, step.OffsetDays))
+		}
+		seen[step.OffsetDays] = true
+
+		step.Subject = strings.TrimSpace(step.Subject)
+		step.Body = strings.TrimSpace(step.Body)
+		if step.Subject == "" || step.Body == "" {
+			return nil, newValidationError(fmt.Sprintf("%s subject and body are required", label))
+		}
+		if strings.ContainsAny(step.Subject, "\r\n") {
+			return nil, newValidationError(fmt.Sprintf("%s subject must be a single line", label))
+		}
+		if len(step.Subject) > maxReminderSubjectSize {
+			return nil, newValidationError(fmt.Sprintf("%s subject must be at most %d characters", label, maxReminderSubjectSize))
+		}
+		if len(step.Body) > maxReminderBodySize {
+			return nil, newValidationError(fmt.Sprintf("%s body must be at most %d characters", label, maxReminderBodySize))
+		}
+		for _, text := range []string{step.Subject, step.Body} {
+			for _, token := range reminderPlaceholder.FindAllString(text, -1) {
+				if !reminderPlaceholders[token] {
+					return nil, newValidationError(fmt.Sprintf("%s uses unknown placeholder %s", label, token))
+				}
+			}
+		}
+		steps = append(steps, step)
+	}
+
+	sort.Slice(steps, func(i, j int) bool { return steps[i].OffsetDays < steps[j].OffsetDays })
+	return steps, nil
+}
+
+// buildReminderEmail renders a reminder step for invoice. The amount and due
+// date in the step's text and the email's labels follow loc.
+func buildReminderEmail(invoice *models.Invoice, workspace *models.User, loc *locale.Locale, step models.ReminderStep, today time.Time) (mailer.Message, error) {
+	data := reminderEmailData{
+		invoiceText:   invoiceText{locale: loc},
+		WorkspaceName: workspaceDisplayName(workspace),
+		InvoiceNumber: invoice.InvoiceNumber,
+		AmountDue:     loc.FormatMoney(invoice.BalanceDue, invoice.Currency),
+	}
+	if invoice.DueDate != nil {
+		data.DueDate = loc.FormatDate(*invoice.DueDate)
+	}
+	if invoice.PaymentLink != nil {
+		data.PaymentLink = *invoice.PaymentLink
+	}
+
+	var daysOverdue, daysUntilDue int
+	if invoice.DueDate != nil {
+		days := int(today.Sub(dateOnly(*invoice.DueDate)).Hours() / 24)
+		if days > 0 {
+			daysOverdue = days
+		} else {
+			daysUntilDue = -days
+		}
+	}
+	clientName := ""
+	if invoice.Client != nil {
+		clientName = invoice.Client.Name
+	}
+
+	replacer := strings.NewReplacer(
+		"{client_name}", clientName,
+		"{workspace_name}", data.WorkspaceName,
+		"{invoice_number}", data.InvoiceNumber,
+		"{amount_due}", data.AmountDue,
+		"{due_date}", data.DueDate,
+		"{days_overdue}", strconv.Itoa(daysOverdue),
+		"{days_until_due}", strconv.Itoa(daysUntilDue),
+		"{payment_link}", data.PaymentLink,
+	)
+	data.Subject = replacer.Replace(step.Subject)
+	data.Body = replacer.Replace(step.Body)
+
+	var htmlBody, textBody bytes.Buffer
+	if err := reminderEmailHTML.Execute(&htmlBody, data); err != nil {
+		return mailer.Message{}, fmt.Errorf("render reminder email: %w", err)
+	}
+	if err := reminderEmailText.Execute(&textBody, data); err != nil {
+		return mailer.Message{}, fmt.Errorf("render reminder email: %w", err)
+	}
+
+	return mailer.Message{
+		Subject:  data.Subject,
+		TextBody: textBody.String(),
+		HTMLBody: htmlBody.String(),
+	}, nil
+}
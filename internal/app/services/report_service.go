@@ -2,66 +2,115 @@ package services
 
 import (
 	"context"
-	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/nava1525/bilio-backend/internal/app/models"
 	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
 )
 
 type ReportService struct {
-	invoices repositories.InvoiceRepository
-	expenses repositories.ExpenseRepository
-	clients  repositories.ClientRepository
+	invoices   repositories.InvoiceRepository
+	expenses   repositories.ExpenseRepository
+	clients    repositories.ClientRepository
+	rates      repositories.ExchangeRateRepository
+	workspaces repositories.WorkspaceRepository
+	catalog    repositories.CatalogItemRepository
 }
 
+// Report amounts are in the workspace's base currency. Invoice amounts are
+// converted at the rate on their issue date and expenses at the rate on
+// their expense date.
 type SummaryReport struct {
-	TotalRevenue    float64 `json:"total_revenue"`
-	TotalExpenses   float64 `json:"total_expenses"`
-	NetProfit       float64 `json:"net_profit"`
-	OutstandingInvoices int `json:"outstanding_invoices"`
-	PaidInvoices    int    `json:"paid_invoices"`
-	TotalInvoices    int    `json:"total_invoices"`
+	BaseCurrency  string        `json:"base_currency"`
+	TotalRevenue  money.Decimal `json:"total_revenue"`
+	TotalExpenses money.Decimal `json:"total_expenses"`
+	// RealizedFXGainLoss is the gain (or, when negative, loss) from payments
+	// received at a different rate than their invoice was issued at.
+	RealizedFXGainLoss  money.Decimal `json:"realized_fx_gain_loss"`
+	NetProfit           money.Decimal `json:"net_profit"`
+	TotalDiscounts      money.Decimal `json:"total_discounts"`
+	TotalCredited       money.Decimal `json:"total_credited"`
+	OutstandingInvoices int           `json:"outstanding_invoices"`
+	PaidInvoices        int           `json:"paid_invoices"`
+	TotalInvoices       int           `json:"total_invoices"`
+	// MissingExchangeRates lists, per currency, the invoices and expenses
+	// left out of the amounts above for want of a rate. They still count
+	// towards the invoice counts.
+	MissingExchangeRates []MissingExchangeRate `json:"missing_exchange_rates"`
 }
 
 type ClientProfitability struct {
-	ClientID      string  `json:"client_id"`
-	ClientName    string  `json:"client_name"`
-	TotalRevenue  float64 `json:"total_revenue"`
-	TotalExpenses float64 `json:"total_expenses"`
-	NetProfit     float64 `json:"net_profit"`
-	ProfitMargin  float64 `json:"profit_margin"` // percentage
+	ClientID           string        `json:"client_id"`
+	ClientName         string        `json:"client_name"`
+	BaseCurrency       string        `json:"base_currency"`
+	TotalRevenue       money.Decimal `json:"total_revenue"`
+	TotalExpenses      money.Decimal `json:"total_expenses"`
+	RealizedFXGainLoss money.Decimal `json:"realized_fx_gain_loss"`
+	NetProfit          money.Decimal `json:"net_profit"`
+	ProfitMargin       money.Decimal `json:"profit_margin"` // percentage
+	// MissingExchangeRates lists what was left out for want of a rate.
+	MissingExchangeRates []MissingExchangeRate `json:"missing_exchange_rates"`
 }
 
 type TaxSummary struct {
-	Period       string            `json:"period"`
-	TotalRevenue float64           `json:"total_revenue"`
-	TotalExpenses float64          `json:"total_expenses"`
-	NetIncome    float64           `json:"net_income"`
-	Invoices     []TaxInvoiceEntry `json:"invoices"`
-	Expenses     []TaxExpenseEntry `json:"expenses"`
+	Period        string              `json:"period"`
+	BaseCurrency  string              `json:"base_currency"`
+	TotalRevenue  money.Decimal       `json:"total_revenue"`
+	TotalExpenses money.Decimal       `json:"total_expenses"`
+	NetIncome     money.Decimal       `json:"net_income"`
+	TaxComponents []TaxComponentTotal `json:"tax_components"`
+	Invoices      []TaxInvoiceEntry   `json:"invoices"`
+	Expenses      []TaxExpenseEntry   `json:"expenses"`
+	// MissingExchangeRates lists what was left out for want of a rate.
+	MissingExchangeRates []MissingExchangeRate `json:"missing_exchange_rates"`
 }
 
+// TaxComponentTotal is the tax collected for one component, e.g. CGST at 9%,
+// across every invoice in the period.
+type TaxComponentTotal struct {
+	Name          string        `json:"name"`
+	Rate          money.Decimal `json:"rate"`
+	TaxableAmount money.Decimal `json:"taxable_amount"`
+	TaxAmount     money.Decimal `json:"tax_amount"`
+}
+
+// TaxInvoiceEntry amounts are in the base currency, converted from Currency
+// at ExchangeRate.
 type TaxInvoiceEntry struct {
-	InvoiceNumber string    `json:"invoice_number"`
-	Date          time.Time `json:"date"`
-	ClientName    string    `json:"client_name"`
-	Amount        float64   `json:"amount"`
-	TaxAmount     float64   `json:"tax_amount"`
+	InvoiceNumber  string                  `json:"invoice_number"`
+	Date           time.Time               `json:"date"`
+	ClientName     string                  `json:"client_name"`
+	Currency       string                  `json:"currency"`
+	ExchangeRate   money.Decimal           `json:"exchange_rate"`
+	Amount         money.Decimal           `json:"amount"`
+	DiscountAmount money.Decimal           `json:"discount_amount"`
+	CreditedAmount money.Decimal           `json:"credited_amount"`
+	TaxAmount      money.Decimal           `json:"tax_amount"`
+	TaxBreakdown   []models.InvoiceTaxLine `json:"tax_breakdown,omitempty"`
 }
 
+// TaxExpenseEntry amounts are in the base currency, converted from Currency
+// at ExchangeRate.
 type TaxExpenseEntry struct {
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
-	Category    string    `json:"category"`
-	Amount      float64   `json:"amount"`
+	Description  string        `json:"description"`
+	Date         time.Time     `json:"date"`
+	Category     string        `json:"category"`
+	Currency     string        `json:"currency"`
+	ExchangeRate money.Decimal `json:"exchange_rate"`
+	Amount       money.Decimal `json:"amount"`
 }
 
-func NewReportService(invoiceRepo repositories.InvoiceRepository, expenseRepo repositories.ExpenseRepository, clientRepo repositories.ClientRepository) *ReportService {
+func NewReportService(invoiceRepo repositories.InvoiceRepository, expenseRepo repositories.ExpenseRepository, clientRepo repositories.ClientRepository, rateRepo repositories.ExchangeRateRepository, workspaceRepo repositories.WorkspaceRepository, catalogRepo repositories.CatalogItemRepository) *ReportService {
 	return &ReportService{
-		invoices: invoiceRepo,
-		expenses: expenseRepo,
-		clients:  clientRepo,
+		invoices:   invoiceRepo,
+		expenses:   expenseRepo,
+		clients:    clientRepo,
+		rates:      rateRepo,
+		workspaces: workspaceRepo,
+		catalog:    catalogRepo,
 	}
 }
 
@@ -84,31 +133,101 @@ func (s *ReportService) GetSummary(ctx context.Context, userID string, fromDate,
 		return nil, err
 	}
 
-	totalRevenue := 0.0
-	totalExpenses := 0.0
+	fx, err := loadExchangeRates(ctx, s.rates, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalRevenue := money.Zero
+	totalExpenses := money.Zero
+	totalFXGainLoss := money.Zero
+	totalDiscounts := money.Zero
+	totalCredited := money.Zero
 	outstandingCount := 0
 	paidCount := 0
 
+	totalInvoices := 0
+
 	for _, inv := range invoices {
-		if inv.Status == models.InvoiceStatusPaid {
-			totalRevenue += inv.Total
+		// Voided invoices were withdrawn and are left out of the summary.
+		if inv.Status == models.InvoiceStatusVoid {
+			continue
+		}
+		totalInvoices++
+		switch inv.Status {
+		case models.InvoiceStatusPaid:
 			paidCount++
-		} else if inv.Status == models.InvoiceStatusPending || inv.Status == models.InvoiceStatusOverdue {
+		case models.InvoiceStatusPending, models.InvoiceStatusOverdue, models.InvoiceStatusPartiallyPaid:
 			outstandingCount++
 		}
+
+		// Discounts count once an invoice is issued; drafts may still change
+		// and cancelled invoices were never owed.
+		discount := money.Zero
+		if inv.Status != models.InvoiceStatusDraft && inv.Status != models.InvoiceStatusCancelled {
+			discount, err = fx.convert(invoiceDiscountTotal(inv), inv.Currency, inv.IssueDate)
+			if err != nil {
+				if fx.leaveOut(err, false) {
+					continue
+				}
+				return nil, err
+			}
+		}
+		credited, err := fx.convert(inv.CreditedAmount, inv.Currency, inv.IssueDate)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
+		}
+
+		// Revenue is money actually received, so partial payments count
+		// towards it while their remaining balance keeps the invoice outstanding.
+		// Credit notes and refunds are netted off.
+		revenue, err := fx.convert(recognisedRevenue(inv), inv.Currency, inv.IssueDate)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
+		}
+		gainLoss, err := s.realizedFXGainLoss(ctx, fx, inv)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
+		}
+
+		totalDiscounts = totalDiscounts.Add(discount)
+		totalCredited = totalCredited.Add(credited)
+		totalRevenue = totalRevenue.Add(revenue)
+		totalFXGainLoss = totalFXGainLoss.Add(gainLoss)
 	}
 
 	for _, exp := range expenses {
-		totalExpenses += exp.Amount
+		amount, err := fx.convert(exp.Amount, exp.Currency, exp.ExpenseDate)
+		if err != nil {
+			if fx.leaveOut(err, true) {
+				continue
+			}
+			return nil, err
+		}
+		totalExpenses = totalExpenses.Add(amount)
 	}
 
 	return &SummaryReport{
-		TotalRevenue:       totalRevenue,
-		TotalExpenses:      totalExpenses,
-		NetProfit:          totalRevenue - totalExpenses,
-		OutstandingInvoices: outstandingCount,
-		PaidInvoices:       paidCount,
-		TotalInvoices:      len(invoices),
+		BaseCurrency:         fx.base,
+		TotalRevenue:         totalRevenue,
+		TotalExpenses:        totalExpenses,
+		RealizedFXGainLoss:   totalFXGainLoss,
+		NetProfit:            totalRevenue.Sub(totalExpenses).Add(totalFXGainLoss),
+		TotalDiscounts:       totalDiscounts,
+		TotalCredited:        totalCredited,
+		OutstandingInvoices:  outstandingCount,
+		PaidInvoices:         paidCount,
+		TotalInvoices:        totalInvoices,
+		MissingExchangeRates: fx.missingRates(),
 	}, nil
 }
 
@@ -118,7 +237,7 @@ func (s *ReportService) GetClientProfitability(ctx context.Context, userID strin
 		return nil, err
 	}
 	if client == nil {
-		return nil, errors.New("client not found")
+		return nil, ErrClientNotFound
 	}
 
 	clientIDPtr := &clientID
@@ -142,31 +261,60 @@ func (s *ReportService) GetClientProfitability(ctx context.Context, userID strin
 		return nil, err
 	}
 
-	totalRevenue := 0.0
+	fx, err := loadExchangeRates(ctx, s.rates, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalRevenue := money.Zero
+	totalFXGainLoss := money.Zero
 	for _, inv := range invoices {
-		if inv.Status == models.InvoiceStatusPaid {
-			totalRevenue += inv.Total
+		revenue, err := fx.convert(recognisedRevenue(inv), inv.Currency, inv.IssueDate)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
+		}
+		gainLoss, err := s.realizedFXGainLoss(ctx, fx, inv)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
 		}
+		totalRevenue = totalRevenue.Add(revenue)
+		totalFXGainLoss = totalFXGainLoss.Add(gainLoss)
 	}
 
-	totalExpenses := 0.0
+	totalExpenses := money.Zero
 	for _, exp := range expenses {
-		totalExpenses += exp.Amount
+		amount, err := fx.convert(exp.Amount, exp.Currency, exp.ExpenseDate)
+		if err != nil {
+			if fx.leaveOut(err, true) {
+				continue
+			}
+			return nil, err
+		}
+		totalExpenses = totalExpenses.Add(amount)
 	}
 
-	netProfit := totalRevenue - totalExpenses
-	profitMargin := 0.0
-	if totalRevenue > 0 {
-		profitMargin = (netProfit / totalRevenue) * 100
+	netProfit := totalRevenue.Sub(totalExpenses).Add(totalFXGainLoss)
+	profitMargin := money.Zero
+	if totalRevenue.IsPositive() {
+		profitMargin = netProfit.Mul(money.Hundred).Div(totalRevenue, 2, money.HalfUp)
 	}
 
 	return &ClientProfitability{
-		ClientID:     clientID,
-		ClientName:    client.Name,
-		TotalRevenue:  totalRevenue,
-		TotalExpenses: totalExpenses,
-		NetProfit:     netProfit,
-		ProfitMargin:  profitMargin,
+		ClientID:             clientID,
+		ClientName:           client.Name,
+		BaseCurrency:         fx.base,
+		TotalRevenue:         totalRevenue,
+		TotalExpenses:        totalExpenses,
+		RealizedFXGainLoss:   totalFXGainLoss,
+		NetProfit:            netProfit,
+		ProfitMargin:         profitMargin,
+		MissingExchangeRates: fx.missingRates(),
 	}, nil
 }
 
@@ -189,45 +337,385 @@ func (s *ReportService) GetTaxSummary(ctx context.Context, userID string, fromDa
 		return nil, err
 	}
 
-	totalRevenue := 0.0
-	totalExpenses := 0.0
+	creditNotes, err := s.invoices.ListCreditNotes(ctx, userID, repositories.CreditNoteFilters{})
+	if err != nil {
+		return nil, err
+	}
+	creditsByInvoice := make(map[string][]models.CreditNote)
+	for _, cn := range creditNotes {
+		creditsByInvoice[cn.InvoiceID] = append(creditsByInvoice[cn.InvoiceID], cn)
+	}
+
+	fx, err := loadExchangeRates(ctx, s.rates, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalRevenue := money.Zero
+	totalExpenses := money.Zero
 	taxInvoices := []TaxInvoiceEntry{}
 	taxExpenses := []TaxExpenseEntry{}
+	components := newTaxComponentTotals()
 
 	for _, inv := range invoices {
-		if inv.Status == models.InvoiceStatusPaid {
-			totalRevenue += inv.Total
-			taxInvoices = append(taxInvoices, TaxInvoiceEntry{
-				InvoiceNumber: inv.InvoiceNumber,
-				Date:          inv.IssueDate,
-				ClientName:    "", // Would need to join with clients table
-				Amount:        inv.Total,
-				TaxAmount:     inv.TaxAmount,
-			})
+		revenue := recognisedRevenue(inv)
+		if !revenue.IsPositive() {
+			continue
+		}
+		rate, err := fx.rate(inv.Currency, inv.IssueDate)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
+		}
+		convert := func(amount money.Decimal) money.Decimal {
+			return amount.Mul(rate).RoundCurrency(fx.base, money.HalfUp)
 		}
+
+		// Tax is recognised in proportion to the share of the invoice collected,
+		// after taking off the tax its credit notes reversed.
+		breakdown := collectedTaxBreakdown(inv, creditsByInvoice[inv.ID])
+		taxAmount := money.Zero
+		for i, line := range breakdown {
+			line.TaxableAmount = convert(line.TaxableAmount)
+			line.Amount = convert(line.Amount)
+			breakdown[i] = line
+			taxAmount = taxAmount.Add(line.Amount)
+			components.add(line)
+		}
+
+		revenue = convert(revenue)
+		totalRevenue = totalRevenue.Add(revenue)
+		taxInvoices = append(taxInvoices, TaxInvoiceEntry{
+			InvoiceNumber:  inv.InvoiceNumber,
+			Date:           inv.IssueDate,
+			ClientName:     "", // Would need to join with clients table
+			Currency:       inv.Currency,
+			ExchangeRate:   rate,
+			Amount:         revenue,
+			DiscountAmount: convert(invoiceDiscountTotal(inv)),
+			CreditedAmount: convert(inv.CreditedAmount),
+			TaxAmount:      taxAmount,
+			TaxBreakdown:   breakdown,
+		})
 	}
 
 	for _, exp := range expenses {
-		totalExpenses += exp.Amount
+		rate, err := fx.rate(exp.Currency, exp.ExpenseDate)
+		if err != nil {
+			if fx.leaveOut(err, true) {
+				continue
+			}
+			return nil, err
+		}
+		amount := exp.Amount.Mul(rate).RoundCurrency(fx.base, money.HalfUp)
+		totalExpenses = totalExpenses.Add(amount)
 		category := ""
 		if exp.Category != nil {
 			category = *exp.Category
 		}
 		taxExpenses = append(taxExpenses, TaxExpenseEntry{
-			Description: exp.Description,
-			Date:        exp.ExpenseDate,
-			Category:    category,
-			Amount:      exp.Amount,
+			Description:  exp.Description,
+			Date:         exp.ExpenseDate,
+			Category:     category,
+			Currency:     exp.Currency,
+			ExchangeRate: rate,
+			Amount:       amount,
 		})
 	}
 
 	return &TaxSummary{
-		Period:       fromDate.Format("2006-01") + " to " + toDate.Format("2006-01"),
-		TotalRevenue: totalRevenue,
-		TotalExpenses: totalExpenses,
-		NetIncome:    totalRevenue - totalExpenses,
-		Invoices:     taxInvoices,
-		Expenses:     taxExpenses,
+		Period:               fromDate.Format("2006-01") + " to " + toDate.Format("2006-01"),
+		BaseCurrency:         fx.base,
+		TotalRevenue:         totalRevenue,
+		TotalExpenses:        totalExpenses,
+		NetIncome:            totalRevenue.Sub(totalExpenses),
+		TaxComponents:        components.list(),
+		Invoices:             taxInvoices,
+		Expenses:             taxExpenses,
+		MissingExchangeRates: fx.missingRates(),
 	}, nil
 }
 
+// realizedFXGainLoss is the base-currency difference between what an
+// invoice's payments were worth at the rate on their payment date and at the
+// invoice's issue-date rate. Refunds are negative payments, so one refunded
+// at a better rate than the invoice's is a loss.
+func (s *ReportService) realizedFXGainLoss(ctx context.Context, fx *exchangeRates, inv models.Invoice) (money.Decimal, error) {
+	if inv.Status == models.InvoiceStatusVoid || inv.AmountPaid.IsZero() || strings.EqualFold(inv.Currency, fx.base) {
+		return money.Zero, nil
+	}
+	payments, err := s.invoices.GetPayments(ctx, inv.ID)
+	if err != nil {
+		return money.Zero, err
+	}
+
+	total := money.Zero
+	for _, p := range payments {
+		atPayment, err := fx.convert(p.Amount, inv.Currency, p.PaymentDate)
+		if err != nil {
+			return money.Zero, err
+		}
+		atIssue, err := fx.convert(p.Amount, inv.Currency, inv.IssueDate)
+		if err != nil {
+			return money.Zero, err
+		}
+		total = total.Add(atPayment.Sub(atIssue))
+	}
+	return total, nil
+}
+
+// invoiceDiscountTotal is everything taken off an invoice: item discounts
+// plus the invoice-level discount.
+func invoiceDiscountTotal(inv models.Invoice) money.Decimal {
+	return inv.LineDiscountAmount.Add(inv.DiscountAmount)
+}
+
+// recognisedRevenue is the part of an invoice's payments it still earns once
+// credit notes are taken off. Refunds are negative payments and so are already
+// netted out of AmountPaid; anything paid beyond the credited value is owed
+// back to the client rather than earned.
+func recognisedRevenue(inv models.Invoice) money.Decimal {
+	if inv.Status == models.InvoiceStatusVoid {
+		return money.Zero
+	}
+	value := inv.Total.Sub(inv.CreditedAmount).Max(money.Zero)
+	return inv.AmountPaid.Min(value).Max(money.Zero)
+}
+
+// collectedTaxBreakdown nets an invoice's tax breakdown against its credit
+// notes and scales what remains to the share of the credited value that has
+// been paid. The last component absorbs rounding so the lines always add up to
+// the collected tax.
+func collectedTaxBreakdown(inv models.Invoice, credits []models.CreditNote) []models.InvoiceTaxLine {
+	breakdown := netTaxBreakdown(inv.TaxBreakdown, credits)
+	if len(breakdown) == 0 {
+		return nil
+	}
+
+	value := inv.Total.Sub(inv.CreditedAmount)
+	revenue := recognisedRevenue(inv)
+	if !value.IsPositive() || !revenue.LessThan(value) {
+		return breakdown
+	}
+
+	places := money.MinorUnits(inv.Currency)
+	taxAmount := money.Zero
+	for _, line := range breakdown {
+		taxAmount = taxAmount.Add(line.Amount)
+	}
+	collected := taxAmount.Mul(revenue).Div(value, places, money.HalfUp)
+	lines := make([]models.InvoiceTaxLine, len(breakdown))
+	allocated := money.Zero
+	for i, line := range breakdown {
+		line.TaxableAmount = line.TaxableAmount.Mul(revenue).Div(value, places, money.HalfUp)
+		if i == len(breakdown)-1 {
+			line.Amount = collected.Sub(allocated)
+		} else {
+			line.Amount = line.Amount.Mul(revenue).Div(value, places, money.HalfUp)
+			allocated = allocated.Add(line.Amount)
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// netTaxBreakdown subtracts the credit notes' tax lines from the invoice's,
+// matching components by name and rate.
+func netTaxBreakdown(breakdown []models.InvoiceTaxLine, credits []models.CreditNote) []models.InvoiceTaxLine {
+	if len(credits) == 0 {
+		return breakdown
+	}
+
+	lines := make([]models.InvoiceTaxLine, len(breakdown))
+	copy(lines, breakdown)
+	for _, cn := range credits {
+		for _, credit := range cn.TaxBreakdown {
+			matched := false
+			for i := range lines {
+				if lines[i].Name == credit.Name && lines[i].Rate.Equal(credit.Rate) {
+					lines[i].TaxableAmount = lines[i].TaxableAmount.Sub(credit.TaxableAmount)
+					lines[i].Amount = lines[i].Amount.Sub(credit.Amount)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				credit.TaxableAmount = credit.TaxableAmount.Neg()
+				credit.Amount = credit.Amount.Neg()
+				lines = append(lines, credit)
+			}
+		}
+	}
+	return lines
+}
+
+type taxComponentTotals struct {
+	order  []string
+	totals map[string]*TaxComponentTotal
+}
+
+func newTaxComponentTotals() *taxComponentTotals {
+	return &taxComponentTotals{totals: make(map[string]*TaxComponentTotal)}
+}
+
+func (t *taxComponentTotals) add(line models.InvoiceTaxLine) {
+	key := line.Name + "@" + line.Rate.String()
+	total, ok := t.totals[key]
+	if !ok {
+		total = &TaxComponentTotal{Name: line.Name, Rate: line.Rate}
+		t.totals[key] = total
+		t.order = append(t.order, key)
+	}
+	total.TaxableAmount = total.TaxableAmount.Add(line.TaxableAmount)
+	total.TaxAmount = total.TaxAmount.Add(line.Amount)
+}
+
+func (t *taxComponentTotals) list() []TaxComponentTotal {
+	result := make([]TaxComponentTotal, 0, len(t.order))
+	for _, key := range t.order {
+		result = append(result, *t.totals[key])
+	}
+	return result
+}
+
+// ItemRevenueReport breaks revenue down by catalog item. Amounts exclude tax
+// and are in the workspace's base currency, converted at each invoice's
+// issue-date rate.
+type ItemRevenueReport struct {
+	BaseCurrency  string        `json:"base_currency"`
+	TotalInvoiced money.Decimal `json:"total_invoiced"`
+	TotalRevenue  money.Decimal `json:"total_revenue"`
+	// Items are ordered by revenue, highest first. Lines billed as free text,
+	// late fees and lines whose catalog item was deleted are grouped in a
+	// last row without a catalog_item_id, whose quantity is left at 0 since
+	// its lines count different things.
+	Items []ItemRevenue `json:"items"`
+	// MissingExchangeRates lists the invoices left out for want of a rate.
+	MissingExchangeRates []MissingExchangeRate `json:"missing_exchange_rates"`
+}
+
+// ItemRevenue is what one catalog item billed. Invoiced is its line amounts
+// after item and invoice discounts; Revenue is the part of that earned, in
+// proportion to how much of each invoice was paid and not credited.
+type ItemRevenue struct {
+	CatalogItemID *string             `json:"catalog_item_id,omitempty"`
+	Name          string              `json:"name"`
+	SKU           *string             `json:"sku,omitempty"`
+	Unit          *models.CatalogUnit `json:"unit,omitempty"`
+	Quantity      money.Decimal       `json:"quantity"`
+	Invoiced      money.Decimal       `json:"invoiced"`
+	Revenue       money.Decimal       `json:"revenue"`
+}
+
+func (s *ReportService) GetItemRevenue(ctx context.Context, userID string, fromDate, toDate *time.Time) (*ItemRevenueReport, error) {
+	filters := repositories.InvoiceFilters{
+		FromDate: fromDate,
+		ToDate:   toDate,
+	}
+	invoices, err := s.invoices.List(ctx, userID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	fx, err := loadExchangeRates(ctx, s.rates, s.workspaces, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byItem := make(map[string]*ItemRevenue)
+	uncatalogued := &ItemRevenue{Name: "Uncatalogued"}
+	for _, inv := range invoices {
+		if inv.Status == models.InvoiceStatusVoid || !inv.Total.IsPositive() {
+			continue
+		}
+		items, err := s.invoices.GetItems(ctx, inv.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		var billed []int
+		var billedAmounts []money.Decimal
+		for i, item := range items {
+			if !item.LateFee {
+				billed = append(billed, i)
+				billedAmounts = append(billedAmounts, item.Amount)
+			}
+		}
+		shares := make([]money.Decimal, len(items))
+		for j, share := range allocateDiscount(inv.DiscountAmount, billedAmounts, inv.Currency) {
+			shares[billed[j]] = share
+		}
+
+		// Every line converts at the invoice's issue-date rate, so an invoice
+		// without one is left out whole.
+		rate, err := fx.rate(inv.Currency, inv.IssueDate)
+		if err != nil {
+			if fx.leaveOut(err, false) {
+				continue
+			}
+			return nil, err
+		}
+
+		places := money.MinorUnits(inv.Currency)
+		revenue := recognisedRevenue(inv)
+		for i, item := range items {
+			net := item.Amount.Sub(shares[i])
+			earned := net.Mul(revenue).Div(inv.Total, places, money.HalfUp)
+			invoiced := net.Mul(rate).RoundCurrency(fx.base, money.HalfUp)
+			earned = earned.Mul(rate).RoundCurrency(fx.base, money.HalfUp)
+
+			row := uncatalogued
+			if item.CatalogItemID != nil && !item.LateFee {
+				row = byItem[*item.CatalogItemID]
+				if row == nil {
+					row = &ItemRevenue{CatalogItemID: item.CatalogItemID, Name: item.Description, SKU: item.SKU, Unit: item.Unit}
+					byItem[*item.CatalogItemID] = row
+				}
+				row.Quantity = row.Quantity.Add(item.Quantity)
+			}
+			row.Invoiced = row.Invoiced.Add(invoiced)
+			row.Revenue = row.Revenue.Add(earned)
+		}
+	}
+
+	report := &ItemRevenueReport{
+		BaseCurrency:         fx.base,
+		TotalInvoiced:        money.Zero,
+		TotalRevenue:         money.Zero,
+		Items:                []ItemRevenue{},
+		MissingExchangeRates: fx.missingRates(),
+	}
+	for id, row := range byItem {
+		// Name the row after the catalog item as it is now; the lines keep
+		// whatever description they were billed with.
+		catalogItem, err := s.catalog.GetByID(ctx, id, userID)
+		if err != nil {
+			return nil, err
+		}
+		if catalogItem != nil {
+			row.Name = catalogItem.Name
+			row.SKU = catalogItem.SKU
+			unit := catalogItem.Unit
+			row.Unit = &unit
+		}
+		report.Items = append(report.Items, *row)
+	}
+	sort.Slice(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if !a.Revenue.Equal(b.Revenue) {
+			return b.Revenue.LessThan(a.Revenue)
+		}
+		return a.Name < b.Name
+	})
+	if !uncatalogued.Invoiced.IsZero() || !uncatalogued.Revenue.IsZero() {
+		report.Items = append(report.Items, *uncatalogued)
+	}
+
+	for _, row := range report.Items {
+		report.TotalInvoiced = report.TotalInvoiced.Add(row.Invoiced)
+		report.TotalRevenue = report.TotalRevenue.Add(row.Revenue)
+	}
+	return report, nil
+}
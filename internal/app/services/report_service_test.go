@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+)
+
+type fakeExpenseRepository struct {
+	repositories.ExpenseRepository
+	expenses []models.Expense
+}
+
+func (r fakeExpenseRepository) List(ctx context.Context, userID string, filters repositories.ExpenseFilters) ([]models.Expense, error) {
+	return r.expenses, nil
+}
+
+type fakeExchangeRateRepository struct {
+	repositories.ExchangeRateRepository
+	rates []models.ExchangeRate
+}
+
+func (r fakeExchangeRateRepository) List(ctx context.Context, userID string, filters repositories.ExchangeRateFilters) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	for _, rate := range r.rates {
+		if rate.BaseCurrency == filters.BaseCurrency {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+func TestSummaryListsMissingRatesPerCurrency(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC) }
+	invoices := newFakeInvoiceRepository()
+	for _, inv := range []models.Invoice{
+		{Currency: "INR", IssueDate: day(4), AmountPaid: dec("1000")},
+		{Currency: "EUR", IssueDate: day(5), AmountPaid: dec("100")},
+		{Currency: "EUR", IssueDate: day(1), AmountPaid: dec("50")},
+		{Currency: "GBP", IssueDate: day(9), AmountPaid: dec("10")},
+		{Currency: "INR", IssueDate: day(6), AmountPaid: dec("500")},
+	} {
+		inv.UserID = "user-1"
+		inv.Status = models.InvoiceStatusPaid
+		inv.Total = inv.AmountPaid
+		if _, err := invoices.Create(context.Background(), &inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc := &ReportService{
+		invoices: invoices,
+		expenses: fakeExpenseRepository{expenses: []models.Expense{
+			{Currency: "INR", ExpenseDate: day(7), Amount: dec("300")},
+			{Currency: "USD", ExpenseDate: day(8), Amount: dec("20")},
+		}},
+		// Only GBP has a rate against INR; EUR has none and USD none yet on
+		// the expense's date.
+		rates: fakeExchangeRateRepository{rates: []models.ExchangeRate{
+			{Currency: "GBP", BaseCurrency: "INR", RateDate: day(1), Rate: dec("105")},
+			{Currency: "USD", BaseCurrency: "INR", RateDate: day(10), Rate: dec("83")},
+		}},
+		// Without a chosen base the report falls back to the invoices' currency.
+		workspaces: &fakeWorkspaceRepository{invoiceCurrency: "INR"},
+	}
+
+	report, err := svc.GetSummary(context.Background(), "user-1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.BaseCurrency != "INR" {
+		t.Errorf("base currency = %s, want INR", report.BaseCurrency)
+	}
+	assertAmount(t, "revenue", report.TotalRevenue, "2550")
+	assertAmount(t, "expenses", report.TotalExpenses, "300")
+	if report.TotalInvoices != 5 || report.PaidInvoices != 5 {
+		t.Errorf("invoices = %d total, %d paid, want 5 and 5", report.TotalInvoices, report.PaidInvoices)
+	}
+
+	want := []MissingExchangeRate{
+		{Currency: "EUR", FromDate: day(1), ToDate: day(5), Invoices: 2},
+		{Currency: "USD", FromDate: day(8), ToDate: day(8), Expenses: 1},
+	}
+	if len(report.MissingExchangeRates) != len(want) {
+		t.Fatalf("missing rates = %+v, want %+v", report.MissingExchangeRates, want)
+	}
+	for i, got := range report.MissingExchangeRates {
+		w := want[i]
+		if got.Currency != w.Currency || !got.FromDate.Equal(w.FromDate) || !got.ToDate.Equal(w.ToDate) ||
+			got.Invoices != w.Invoices || got.Expenses != w.Expenses {
+			t.Errorf("missing rates[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+}
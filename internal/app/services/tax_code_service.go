@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+var ErrTaxCodeNotFound = errors.New("tax code not found")
+
+type TaxCodeService struct {
+	taxCodes repositories.TaxCodeRepository
+}
+
+type TaxCodeInput struct {
+	Code       string                `json:"code"`
+	Name       string                `json:"name"`
+	Components []models.TaxComponent `json:"components"`
+}
+
+func NewTaxCodeService(taxCodeRepo repositories.TaxCodeRepository) *TaxCodeService {
+	return &TaxCodeService{taxCodes: taxCodeRepo}
+}
+
+func (s *TaxCodeService) List(ctx context.Context, userID string) ([]models.TaxCode, error) {
+	return s.taxCodes.List(ctx, userID)
+}
+
+func (s *TaxCodeService) GetByID(ctx context.Context, id string, userID string) (*models.TaxCode, error) {
+	taxCode, err := s.taxCodes.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if taxCode == nil {
+		return nil, ErrTaxCodeNotFound
+	}
+	return taxCode, nil
+}
+
+func (s *TaxCodeService) Create(ctx context.Context, userID string, input TaxCodeInput) (*models.TaxCode, error) {
+	taxCode := &models.TaxCode{UserID: userID}
+	if err := applyTaxCodeInput(taxCode, input); err != nil {
+		return nil, err
+	}
+
+	created, err := s.taxCodes.Create(ctx, taxCode)
+	if errors.Is(err, repositories.ErrTaxCodeExists) {
+		return nil, newValidationError(fmt.Sprintf("tax code %q already exists", taxCode.Code))
+	}
+	return created, err
+}
+
+// Update changes a tax code for future pricing. Invoices already issued keep
+// the components they were priced with.
+func (s *TaxCodeService) Update(ctx context.Context, id string, userID string, input TaxCodeInput) (*models.TaxCode, error) {
+	taxCode, err := s.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTaxCodeInput(taxCode, input); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.taxCodes.Update(ctx, taxCode)
+	if errors.Is(err, repositories.ErrTaxCodeExists) {
+		return nil, newValidationError(fmt.Sprintf("tax code %q already exists", taxCode.Code))
+	}
+	return updated, err
+}
+
+func (s *TaxCodeService) Delete(ctx context.Context, id string, userID string) error {
+	if _, err := s.GetByID(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.taxCodes.Delete(ctx, id, userID)
+}
+
+func applyTaxCodeInput(taxCode *models.TaxCode, input TaxCodeInput) error {
+	code := strings.TrimSpace(input.Code)
+	if code == "" {
+		return newValidationError("code is required")
+	}
+	if len(input.Components) == 0 {
+		return newValidationError("at least one component is required")
+	}
+
+	components := make([]models.TaxComponent, len(input.Components))
+	seen := make(map[string]bool, len(input.Components))
+	for i, component := range input.Components {
+		name := strings.TrimSpace(component.Name)
+		if name == "" {
+			return newValidationError(fmt.Sprintf("components[%d].name is required", i))
+		}
+		if seen[strings.ToLower(name)] {
+			return newValidationError(fmt.Sprintf("component %q is listed more than once", name))
+		}
+		seen[strings.ToLower(name)] = true
+		if component.Rate.IsNegative() || component.Rate.GreaterThan(money.Hundred) {
+			return newValidationError(fmt.Sprintf("components[%d].rate must be between 0 and 100", i))
+		}
+		components[i] = models.TaxComponent{Name: name, Rate: component.Rate}
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = code
+	}
+
+	taxCode.Code = code
+	taxCode.Name = name
+	taxCode.Components = components
+	return nil
+}
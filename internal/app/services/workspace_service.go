@@ -0,0 +1,568 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	"github.com/nava1525/bilio-backend/internal/app/repositories"
+	"github.com/nava1525/bilio-backend/pkg/blobstore"
+	"github.com/nava1525/bilio-backend/pkg/mailer"
+	"github.com/nava1525/bilio-backend/pkg/money"
+	"github.com/nava1525/bilio-backend/pkg/pdf"
+)
+
+const (
+	// MaxLogoSize is the largest logo file accepted.
+	MaxLogoSize = 1 << 20
+	// maxLogoDimension keeps logos small enough to embed in every PDF.
+	maxLogoDimension = 1200
+	// maxTemplateSize bounds a workspace's template source.
+	maxTemplateSize = 64 << 10
+
+	maxLegalNameLength   = 200
+	maxProfileTextLength = 2000
+	maxTaxIDLength       = 100
+)
+
+var (
+	ErrLogoNotFound              = errors.New("workspace has no logo")
+	ErrLogoTooLarge              = fmt.Errorf("logo exceeds the %d MB limit", MaxLogoSize>>20)
+	ErrUnsupportedLogoType       = errors.New("unsupported logo type; upload a PNG or JPEG image")
+	ErrWorkspaceTemplateNotFound = errors.New("workspace has no template of its own for this kind")
+
+	hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// logoTypes maps the accepted logo content types to file extensions. Only
+// formats the PDF renderer can decode are accepted.
+var logoTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+}
+
+type WorkspaceService struct {
+	workspaces repositories.WorkspaceRepository
+	invoices   *InvoiceService
+}
+
+// UpdateWorkspaceProfileInput replaces a workspace's branding; fields left
+// out are cleared. The logo is managed separately.
+type UpdateWorkspaceProfileInput struct {
+	LegalName     *string              `json:"legal_name,omitempty"`
+	Address       *string              `json:"address,omitempty"`
+	TaxID         *string              `json:"tax_id,omitempty"`
+	BankDetails   *string              `json:"bank_details,omitempty"`
+	FooterText    *string              `json:"footer_text,omitempty"`
+	BrandColor    *string              `json:"brand_color,omitempty"`
+	AccentColor   *string              `json:"accent_color,omitempty"`
+	InvoiceLayout models.InvoiceLayout `json:"invoice_layout,omitempty"`
+	Locale        string               `json:"locale,omitempty"`
+	BaseCurrency  string               `json:"base_currency,omitempty"`
+}
+
+// UploadLogoInput is a logo image uploaded for the workspace.
+type UploadLogoInput struct {
+	Content io.Reader
+}
+
+// InvoiceTemplate is the html/template source used for one kind of invoice
+// rendering: the workspace's own when Custom is set, otherwise the built-in
+// template of Layout.
+type InvoiceTemplate struct {
+	Kind      models.TemplateKind  `json:"kind"`
+	Layout    models.InvoiceLayout `json:"layout,omitempty"`
+	Custom    bool                 `json:"custom"`
+	Content   string               `json:"content"`
+	UpdatedAt *time.Time           `json:"updated_at,omitempty"`
+}
+
+type SaveTemplateInput struct {
+	Content string `json:"content"`
+}
+
+// PreviewInvoiceInput selects what to preview. Format is "share", "email" or
+// "pdf". Layout previews a built-in layout instead of the workspace's current
+// one, and Content previews unsaved template source for the share and email
+// formats. Without an InvoiceID a sample invoice is rendered.
+type PreviewInvoiceInput struct {
+	Format    string               `json:"format"`
+	Layout    models.InvoiceLayout `json:"layout,omitempty"`
+	Content   *string              `json:"content,omitempty"`
+	InvoiceID *string              `json:"invoice_id,omitempty"`
+	PageSize  string               `json:"page_size,omitempty"`
+}
+
+// InvoicePreview is a rendered preview.
+type InvoicePreview struct {
+	ContentType string
+	Content     []byte
+}
+
+func NewWorkspaceService(workspaceRepo repositories.WorkspaceRepository, invoiceService *InvoiceService) *WorkspaceService {
+	return &WorkspaceService{
+		workspaces: workspaceRepo,
+		invoices:   invoiceService,
+	}
+}
+
+// GetProfile returns the workspace's profile, or an empty classic profile in
+// the default locale if none has been saved. A workspace that has not chosen
+// a base currency shows the one its reports fall back to.
+func (s *WorkspaceService) GetProfile(ctx context.Context, userID string) (*models.WorkspaceProfile, error) {
+	profile, err := s.workspaces.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		profile = &models.WorkspaceProfile{
+			UserID:        userID,
+			InvoiceLayout: models.InvoiceLayoutClassic,
+			Locale:        DefaultLocale,
+		}
+	}
+	if profile.BaseCurrency == "" {
+		profile.BaseCurrency, err = defaultBaseCurrency(ctx, s.workspaces, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
+
+func (s *WorkspaceService) UpdateProfile(ctx context.Context, userID string, input UpdateWorkspaceProfileInput) (*models.WorkspaceProfile, error) {
+	if input.InvoiceLayout == "" {
+		input.InvoiceLayout = models.InvoiceLayoutClassic
+	}
+	if err := validateInvoiceLayout(input.InvoiceLayout); err != nil {
+		return nil, err
+	}
+	tag, err := normalizeLocale(input.Locale)
+	if err != nil {
+		return nil, err
+	}
+	baseCurrency, err := normalizeBaseCurrency(input.BaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile.InvoiceLayout = input.InvoiceLayout
+	profile.Locale = tag
+	// Stored rates are against the base currency, so leaving it out keeps
+	// the one in use rather than clearing it.
+	if baseCurrency != "" {
+		profile.BaseCurrency = baseCurrency
+	}
+
+	fields := []struct {
+		name  string
+		value *string
+		max   int
+		dest  **string
+	}{
+		{"legal_name", input.LegalName, maxLegalNameLength, &profile.LegalName},
+		{"address", input.Address, maxProfileTextLength, &profile.Address},
+		{"tax_id", input.TaxID, maxTaxIDLength, &profile.TaxID},
+		{"bank_details", input.BankDetails, maxProfileTextLength, &profile.BankDetails},
+		{"footer_text", input.FooterText, maxProfileTextLength, &profile.FooterText},
+	}
+	for _, field := range fields {
+		value, err := normalizeProfileText(field.name, field.value, field.max)
+		if err != nil {
+			return nil, err
+		}
+		*field.dest = value
+	}
+
+	for _, color := range []struct {
+		name  string
+		value *string
+		dest  **string
+	}{
+		{"brand_color", input.BrandColor, &profile.BrandColor},
+		{"accent_color", input.AccentColor, &profile.AccentColor},
+	} {
+		value, err := normalizeHexColor(color.name, color.value)
+		if err != nil {
+			return nil, err
+		}
+		*color.dest = value
+	}
+
+	if err := s.workspaces.SaveProfile(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to save workspace profile: %w", err)
+	}
+	return profile, nil
+}
+
+// UploadLogo stores a PNG or JPEG logo, replacing any earlier one.
+func (s *WorkspaceService) UploadLogo(ctx context.Context, userID string, input UploadLogoInput) (*models.WorkspaceProfile, error) {
+	blobs := s.invoices.blobs
+	if blobs == nil {
+		return nil, fmt.Errorf("logo storage not configured")
+	}
+
+	content, err := io.ReadAll(io.LimitReader(input.Content, MaxLogoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read logo: %w", err)
+	}
+	if len(content) == 0 {
+		return nil, newValidationError("logo is empty")
+	}
+	if len(content) > MaxLogoSize {
+		return nil, ErrLogoTooLarge
+	}
+
+	// The type is taken from the content so the stored type always matches
+	// what the PDF renderer will decode.
+	contentType := http.DetectContentType(content)
+	if _, ok := logoTypes[contentType]; !ok {
+		return nil, ErrUnsupportedLogoType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, newValidationError("logo could not be read as an image")
+	}
+	if config.Width > maxLogoDimension || config.Height > maxLogoDimension {
+		return nil, newValidationError(fmt.Sprintf("logo must be at most %d×%d pixels", maxLogoDimension, maxLogoDimension))
+	}
+
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := profile.Logo
+
+	logo := &models.WorkspaceLogo{
+		StorageKey:  fmt.Sprintf("workspaces/%s/logo-%s", userID, uuid.NewString()),
+		ContentType: contentType,
+		SizeBytes:   int64(len(content)),
+		Width:       config.Width,
+		Height:      config.Height,
+	}
+	if err := blobs.Put(ctx, logo.StorageKey, bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("store logo: %w", err)
+	}
+	if err := s.workspaces.SetLogo(ctx, userID, logo); err != nil {
+		_ = blobs.Delete(ctx, logo.StorageKey)
+		return nil, fmt.Errorf("failed to save logo: %w", err)
+	}
+	if previous != nil {
+		_ = blobs.Delete(ctx, previous.StorageKey)
+	}
+
+	return s.GetProfile(ctx, userID)
+}
+
+// OpenLogo returns the workspace's logo and its content. The caller must
+// close the reader.
+func (s *WorkspaceService) OpenLogo(ctx context.Context, userID string) (*models.WorkspaceLogo, io.ReadCloser, error) {
+	profile, err := s.workspaces.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if profile == nil || profile.Logo == nil || s.invoices.blobs == nil {
+		return nil, nil, ErrLogoNotFound
+	}
+
+	content, err := s.invoices.blobs.Open(ctx, profile.Logo.StorageKey)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return nil, nil, ErrLogoNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("open logo: %w", err)
+	}
+	return profile.Logo, content, nil
+}
+
+func (s *WorkspaceService) DeleteLogo(ctx context.Context, userID string) error {
+	profile, err := s.workspaces.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if profile == nil || profile.Logo == nil {
+		return ErrLogoNotFound
+	}
+
+	if err := s.workspaces.SetLogo(ctx, userID, nil); err != nil {
+		return fmt.Errorf("failed to delete logo: %w", err)
+	}
+	if s.invoices.blobs != nil {
+		_ = s.invoices.blobs.Delete(ctx, profile.Logo.StorageKey)
+	}
+	return nil
+}
+
+// ListTemplates returns the template in use for each kind of rendering.
+func (s *WorkspaceService) ListTemplates(ctx context.Context, userID string) ([]InvoiceTemplate, error) {
+	kinds := []models.TemplateKind{models.TemplateKindShare, models.TemplateKindEmail}
+	templates := make([]InvoiceTemplate, 0, len(kinds))
+	for _, kind := range kinds {
+		template, err := s.GetTemplate(ctx, userID, kind, "")
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, nil
+}
+
+// GetTemplate returns the template in use for kind. Asking for a layout
+// returns that layout's built-in template instead, as a starting point for a
+// template of the workspace's own.
+func (s *WorkspaceService) GetTemplate(ctx context.Context, userID string, kind models.TemplateKind, layout models.InvoiceLayout) (*InvoiceTemplate, error) {
+	if err := validateTemplateKind(kind); err != nil {
+		return nil, err
+	}
+
+	if layout == "" {
+		custom, err := s.workspaces.GetTemplate(ctx, userID, kind)
+		if err != nil {
+			return nil, err
+		}
+		if custom != nil {
+			return &InvoiceTemplate{Kind: kind, Custom: true, Content: custom.Content, UpdatedAt: &custom.UpdatedAt}, nil
+		}
+
+		profile, err := s.GetProfile(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		layout = profile.InvoiceLayout
+	}
+	if err := validateInvoiceLayout(layout); err != nil {
+		return nil, err
+	}
+
+	return &InvoiceTemplate{Kind: kind, Layout: layout, Content: invoiceTemplateSources[kind][layout]}, nil
+}
+
+// SaveTemplate stores the workspace's own template for kind. The template is
+// rendered against a sample invoice first so mistakes such as unknown fields
+// are reported now rather than when a client opens an invoice.
+func (s *WorkspaceService) SaveTemplate(ctx context.Context, userID string, kind models.TemplateKind, input SaveTemplateInput) (*InvoiceTemplate, error) {
+	if err := validateTemplateKind(kind); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		return nil, newValidationError("content is required")
+	}
+	if len(input.Content) > maxTemplateSize {
+		return nil, newValidationError(fmt.Sprintf("template must be at most %d KB", maxTemplateSize>>10))
+	}
+
+	brand, err := s.invoices.loadBranding(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	brand.overrides[kind] = input.Content
+	if _, err := renderInvoicePreview(sampleInvoice(), brand, string(kind), pdf.PageA4); err != nil {
+		return nil, err
+	}
+
+	template := &models.WorkspaceTemplate{UserID: userID, Kind: kind, Content: input.Content}
+	if err := s.workspaces.SaveTemplate(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to save template: %w", err)
+	}
+	return &InvoiceTemplate{Kind: kind, Custom: true, Content: template.Content, UpdatedAt: &template.UpdatedAt}, nil
+}
+
+// DeleteTemplate removes the workspace's own template so its layout's
+// built-in template applies again.
+func (s *WorkspaceService) DeleteTemplate(ctx context.Context, userID string, kind models.TemplateKind) error {
+	if err := validateTemplateKind(kind); err != nil {
+		return err
+	}
+	deleted, err := s.workspaces.DeleteTemplate(ctx, userID, kind)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	if !deleted {
+		return ErrWorkspaceTemplateNotFound
+	}
+	return nil
+}
+
+// Preview renders an invoice with the workspace's branding as it would be
+// shared, emailed or downloaded.
+func (s *WorkspaceService) Preview(ctx context.Context, userID string, input PreviewInvoiceInput) (*InvoicePreview, error) {
+	kind := models.TemplateKind(input.Format)
+	if input.Format != "pdf" {
+		if err := validateTemplateKind(kind); err != nil {
+			return nil, newValidationError(`format must be "share", "email" or "pdf"`)
+		}
+	}
+	size, err := ParsePageSize(input.PageSize)
+	if err != nil {
+		return nil, newValidationError(err.Error())
+	}
+
+	brand, err := s.invoices.loadBranding(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Layout != "" {
+		if err := validateInvoiceLayout(input.Layout); err != nil {
+			return nil, err
+		}
+		brand.profile.InvoiceLayout = input.Layout
+		delete(brand.overrides, kind)
+	}
+	if input.Content != nil {
+		if input.Format == "pdf" {
+			return nil, newValidationError("content can only be previewed for the share and email formats")
+		}
+		brand.overrides[kind] = *input.Content
+	}
+
+	invoice := sampleInvoice()
+	if input.InvoiceID != nil {
+		invoice, err = s.invoices.GetByID(ctx, *input.InvoiceID, userID)
+		if err != nil {
+			return nil, err
+		}
+		client, err := s.invoices.clients.GetByID(ctx, invoice.ClientID, userID)
+		if err != nil {
+			return nil, err
+		}
+		invoice.Client = client
+	}
+
+	return renderInvoicePreview(invoice, brand, input.Format, size)
+}
+
+// renderInvoicePreview renders invoice in format. Email previews show the
+// logo inline rather than through the attachment the real email carries.
+func renderInvoicePreview(invoice *models.Invoice, brand *invoiceBranding, format string, size pdf.PageSize) (*InvoicePreview, error) {
+	switch format {
+	case "pdf":
+		var buf bytes.Buffer
+		if _, err := renderInvoicePDF(invoice, brand, size).WriteTo(&buf); err != nil {
+			return nil, fmt.Errorf("failed to render invoice pdf: %w", err)
+		}
+		return &InvoicePreview{ContentType: "application/pdf", Content: buf.Bytes()}, nil
+	case string(models.TemplateKindEmail):
+		msg, err := buildInvoiceEmail(invoice, brand, nil)
+		if err != nil {
+			return nil, templateRenderError(err)
+		}
+		html := strings.ReplaceAll(msg.HTMLBody, "cid:"+logoContentID, string(brand.logoDataURL()))
+		return &InvoicePreview{ContentType: "text/html; charset=utf-8", Content: []byte(html)}, nil
+	default:
+		page, err := renderInvoiceShareHTML(invoice, brand)
+		if err != nil {
+			return nil, templateRenderError(err)
+		}
+		return &InvoicePreview{ContentType: "text/html; charset=utf-8", Content: page}, nil
+	}
+}
+
+// templateRenderError reports a workspace template that fails to execute,
+// such as one naming a field the data does not have, as a validation error.
+func templateRenderError(err error) error {
+	if _, ok := AsValidationError(err); ok {
+		return err
+	}
+	return newValidationError(fmt.Sprintf("template could not be rendered: %v", err))
+}
+
+// sampleInvoice is the invoice rendered in previews when none is chosen.
+func sampleInvoice() *models.Invoice {
+	issueDate := dateOnly(time.Now().UTC())
+	dueDate := issueDate.AddDate(0, 0, 30)
+	notes := "Thank you for your business."
+	email := "billing@acme.example"
+
+	return &models.Invoice{
+		InvoiceNumber: "INV-0001",
+		Status:        models.InvoiceStatusPending,
+		IssueDate:     issueDate,
+		DueDate:       &dueDate,
+		Currency:      "USD",
+		Subtotal:      money.NewFromInt(1500),
+		TaxRate:       money.NewFromInt(10),
+		TaxAmount:     money.NewFromInt(150),
+		TaxBreakdown: []models.InvoiceTaxLine{
+			{Name: "Tax", Rate: money.NewFromInt(10), TaxableAmount: money.NewFromInt(1500), Amount: money.NewFromInt(150)},
+		},
+		Total:      money.NewFromInt(1650),
+		BalanceDue: money.NewFromInt(1650),
+		Notes:      &notes,
+		Items: []models.InvoiceItem{
+			{Description: "Website design", Quantity: money.NewFromInt(1), UnitPrice: money.NewFromInt(1200), Amount: money.NewFromInt(1200)},
+			{Description: "Hosting (12 months)", Quantity: money.NewFromInt(12), UnitPrice: money.NewFromInt(25), Amount: money.NewFromInt(300)},
+		},
+		Client: &models.Client{Name: "Acme Corporation", Email: &email, Currency: "USD"},
+	}
+}
+
+func validateInvoiceLayout(layout models.InvoiceLayout) error {
+	for _, known := range models.InvoiceLayouts {
+		if layout == known {
+			return nil
+		}
+	}
+	return newValidationError(`invoice_layout must be "classic", "modern" or "minimal"`)
+}
+
+func validateTemplateKind(kind models.TemplateKind) error {
+	if kind != models.TemplateKindShare && kind != models.TemplateKindEmail {
+		return newValidationError(`template kind must be "share" or "email"`)
+	}
+	return nil
+}
+
+func normalizeProfileText(field string, value *string, max int) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if len(trimmed) > max {
+		return nil, newValidationError(fmt.Sprintf("%s must be at most %d characters", field, max))
+	}
+	return &trimmed, nil
+}
+
+// normalizeHexColor accepts a "#rrggbb" colour and returns it in lower case.
+func normalizeHexColor(field string, value *string) (*string, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	color := strings.ToLower(strings.TrimSpace(*value))
+	if !hexColorPattern.MatchString(color) {
+		return nil, newValidationError(fmt.Sprintf("%s must be a colour such as \"#1a73e8\"", field))
+	}
+	return &color, nil
+}
+
+// logoExtension returns the file extension for a logo's content type.
+func logoExtension(contentType string) string {
+	return logoTypes[contentType]
+}
+
+// logoAttachment is the logo sent inline with invoice emails.
+func logoAttachment(brand *invoiceBranding) mailer.Attachment {
+	return mailer.Attachment{
+		Filename:    "logo" + logoExtension(brand.profile.Logo.ContentType),
+		ContentType: brand.profile.Logo.ContentType,
+		Content:     brand.logo,
+		ContentID:   logoContentID,
+	}
+}
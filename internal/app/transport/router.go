@@ -15,6 +15,8 @@ import (
 	appRepositories "github.com/nava1525/bilio-backend/internal/app/repositories"
 	appServices "github.com/nava1525/bilio-backend/internal/app/services"
 	"github.com/nava1525/bilio-backend/internal/config"
+	pkgblobstore "github.com/nava1525/bilio-backend/pkg/blobstore"
+	pkgfxrates "github.com/nava1525/bilio-backend/pkg/fxrates"
 	pkgmailer "github.com/nava1525/bilio-backend/pkg/mailer"
 	pkgmiddleware "github.com/nava1525/bilio-backend/pkg/middleware"
 )
@@ -37,30 +39,26 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, db *sql.DB) (http.Hand
 	}))
 
 	healthHandler := appHandlers.NewHealthHandler()
-	
+
 	// Repositories
 	userRepo := appRepositories.NewUserRepository(db)
 	clientRepo := appRepositories.NewClientRepository(db)
 	invoiceRepo := appRepositories.NewInvoiceRepository(db)
+	recurringInvoiceRepo := appRepositories.NewRecurringInvoiceRepository(db)
+	invoiceNumberingRepo := appRepositories.NewInvoiceNumberingRepository(db)
+	taxCodeRepo := appRepositories.NewTaxCodeRepository(db)
+	invoiceShareRepo := appRepositories.NewInvoiceShareRepository(db)
+	reminderRepo := appRepositories.NewReminderRepository(db)
+	quoteRepo := appRepositories.NewQuoteRepository(db)
+	invoiceAttachmentRepo := appRepositories.NewInvoiceAttachmentRepository(db)
+	lateFeeRepo := appRepositories.NewLateFeeRepository(db)
+	workspaceRepo := appRepositories.NewWorkspaceRepository(db)
+	exchangeRateRepo := appRepositories.NewExchangeRateRepository(db)
+	catalogItemRepo := appRepositories.NewCatalogItemRepository(db)
 	expenseRepo := appRepositories.NewExpenseRepository(db)
 	waitlistRepo := appRepositories.NewWaitlistRepository(db)
 	promocodeRepo := appRepositories.NewPromocodeRepository(db)
 
-	// Services
-	authService := appServices.NewAuthService(userRepo)
-	clientService := appServices.NewClientService(clientRepo)
-	invoiceService := appServices.NewInvoiceService(invoiceRepo, clientRepo)
-	expenseService := appServices.NewExpenseService(expenseRepo, clientRepo)
-	reportService := appServices.NewReportService(invoiceRepo, expenseRepo, clientRepo)
-
-	// Handlers
-	authHandler := appHandlers.NewAuthHandler(authService)
-	clientHandler := appHandlers.NewClientHandler(clientService)
-	invoiceHandler := appHandlers.NewInvoiceHandler(invoiceService)
-	expenseHandler := appHandlers.NewExpenseHandler(expenseService)
-	reportHandler := appHandlers.NewReportHandler(reportService)
-	userHandler := appHandlers.NewUserHandler(userRepo)
-
 	if cfg.Email.SMTP.Username == "" || cfg.Email.SMTP.Password == "" {
 		return nil, fmt.Errorf("email smtp credentials missing; set EMAIL_USER and EMAIL_PASSWORD")
 	}
@@ -76,6 +74,59 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, db *sql.DB) (http.Hand
 		return nil, err
 	}
 
+	blobs, err := pkgblobstore.New(pkgblobstore.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalPath: cfg.Storage.LocalPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rateProvider, err := pkgfxrates.New(pkgfxrates.Config{
+		Driver:      cfg.ExchangeRates.Provider,
+		FixturePath: cfg.ExchangeRates.FixturePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Services
+	authService := appServices.NewAuthService(userRepo)
+	clientService := appServices.NewClientService(clientRepo, taxCodeRepo, invoiceRepo)
+	invoiceService := appServices.NewInvoiceService(invoiceRepo, clientRepo, userRepo, workspaceRepo, taxCodeRepo, catalogItemRepo, invoiceAttachmentRepo, blobs, mailer)
+	recurringInvoiceService := appServices.NewRecurringInvoiceService(recurringInvoiceRepo, clientRepo, invoiceService)
+	invoiceNumberingService := appServices.NewInvoiceNumberingService(invoiceNumberingRepo)
+	taxCodeService := appServices.NewTaxCodeService(taxCodeRepo)
+	catalogItemService := appServices.NewCatalogItemService(catalogItemRepo, taxCodeRepo)
+	creditNoteService := appServices.NewCreditNoteService(invoiceRepo)
+	invoiceShareService := appServices.NewInvoiceShareService(invoiceShareRepo, invoiceService)
+	reminderService := appServices.NewReminderService(reminderRepo, invoiceService)
+	quoteService := appServices.NewQuoteService(quoteRepo, invoiceService)
+	lateFeeService := appServices.NewLateFeeService(lateFeeRepo, invoiceService)
+	workspaceService := appServices.NewWorkspaceService(workspaceRepo, invoiceService)
+	expenseService := appServices.NewExpenseService(expenseRepo, clientRepo)
+	exchangeRateService := appServices.NewExchangeRateService(exchangeRateRepo, workspaceRepo, rateProvider)
+	reportService := appServices.NewReportService(invoiceRepo, expenseRepo, clientRepo, exchangeRateRepo, workspaceRepo, catalogItemRepo)
+
+	// Handlers
+	authHandler := appHandlers.NewAuthHandler(authService)
+	clientHandler := appHandlers.NewClientHandler(clientService)
+	invoiceHandler := appHandlers.NewInvoiceHandler(invoiceService)
+	recurringInvoiceHandler := appHandlers.NewRecurringInvoiceHandler(recurringInvoiceService)
+	invoiceNumberingHandler := appHandlers.NewInvoiceNumberingHandler(invoiceNumberingService)
+	taxCodeHandler := appHandlers.NewTaxCodeHandler(taxCodeService)
+	catalogItemHandler := appHandlers.NewCatalogItemHandler(catalogItemService)
+	creditNoteHandler := appHandlers.NewCreditNoteHandler(creditNoteService)
+	invoiceShareHandler := appHandlers.NewInvoiceShareHandler(invoiceShareService)
+	reminderHandler := appHandlers.NewReminderHandler(reminderService)
+	quoteHandler := appHandlers.NewQuoteHandler(quoteService)
+	lateFeeHandler := appHandlers.NewLateFeeHandler(lateFeeService)
+	workspaceHandler := appHandlers.NewWorkspaceHandler(workspaceService)
+	expenseHandler := appHandlers.NewExpenseHandler(expenseService)
+	exchangeRateHandler := appHandlers.NewExchangeRateHandler(exchangeRateService)
+	reportHandler := appHandlers.NewReportHandler(reportService)
+	userHandler := appHandlers.NewUserHandler(userRepo)
+
 	waitlistService := appServices.NewWaitlistService(waitlistRepo, promocodeRepo, mailer)
 	waitlistHandler := appHandlers.NewWaitlistHandler(waitlistService, logger)
 
@@ -100,6 +151,14 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, db *sql.DB) (http.Hand
 		r.Get("/promocode", promocodeHandler.Generate)
 		r.Post("/waitlist", waitlistHandler.Join)
 
+		// Shared invoices, opened by clients through a signed link
+		r.Get("/share/{token}", invoiceShareHandler.View)
+
+		// Quotes, accepted or declined by clients through a signed link
+		r.Get("/public/quotes/{token}", quoteHandler.View)
+		r.Post("/public/quotes/{token}/accept", quoteHandler.Accept)
+		r.Post("/public/quotes/{token}/decline", quoteHandler.Decline)
+
 		// Protected endpoints - require authentication
 		r.Group(func(r chi.Router) {
 			r.Use(authMiddleware)
@@ -111,6 +170,11 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, db *sql.DB) (http.Hand
 				r.Get("/{id}", clientHandler.Get)
 				r.Put("/{id}", clientHandler.Update)
 				r.Delete("/{id}", clientHandler.Delete)
+				r.Get("/{id}/late-fee-policy", lateFeeHandler.GetClientPolicy)
+				r.Put("/{id}/late-fee-policy", lateFeeHandler.UpdateClientPolicy)
+				r.Delete("/{id}/late-fee-policy", lateFeeHandler.DeleteClientPolicy)
+				r.Get("/{id}/credits", clientHandler.ListCredits)
+				r.Post("/{id}/credits", clientHandler.AddDeposit)
 			})
 
 			// Invoices
@@ -119,9 +183,93 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, db *sql.DB) (http.Hand
 				r.Post("/", invoiceHandler.Create)
 				r.Get("/{id}", invoiceHandler.Get)
 				r.Put("/{id}", invoiceHandler.Update)
+				r.Delete("/{id}", invoiceHandler.Delete)
 				r.Post("/{id}/send", invoiceHandler.Send)
 				r.Post("/{id}/mark-paid", invoiceHandler.MarkPaid)
 				r.Get("/{id}/pdf", invoiceHandler.GetPDF)
+				r.Post("/{id}/duplicate", invoiceHandler.Duplicate)
+				r.Post("/{id}/void", invoiceHandler.Void)
+				r.Get("/{id}/history", invoiceHandler.History)
+				r.Get("/{id}/share-links", invoiceShareHandler.List)
+				r.Post("/{id}/share-links", invoiceShareHandler.Create)
+				r.Delete("/{id}/share-links/{linkID}", invoiceShareHandler.Revoke)
+				r.Get("/{id}/credit-notes", creditNoteHandler.ListForInvoice)
+				r.Post("/{id}/credit-notes", creditNoteHandler.Create)
+				r.Get("/{id}/reminders", reminderHandler.ListForInvoice)
+				r.Get("/{id}/late-fees", lateFeeHandler.ListForInvoice)
+				r.Get("/{id}/attachments", invoiceHandler.ListAttachments)
+				r.Post("/{id}/attachments", invoiceHandler.UploadAttachment)
+				r.Get("/{id}/attachments/{attachmentID}", invoiceHandler.DownloadAttachment)
+				r.Delete("/{id}/attachments/{attachmentID}", invoiceHandler.DeleteAttachment)
+			})
+
+			// Quotes
+			r.Route("/quotes", func(r chi.Router) {
+				r.Get("/", quoteHandler.List)
+				r.Post("/", quoteHandler.Create)
+				r.Get("/{id}", quoteHandler.Get)
+				r.Put("/{id}", quoteHandler.Update)
+				r.Delete("/{id}", quoteHandler.Delete)
+				r.Post("/{id}/convert", quoteHandler.Convert)
+			})
+
+			// Invoice numbering
+			r.Get("/invoice-numbering", invoiceNumberingHandler.Get)
+			r.Put("/invoice-numbering", invoiceNumberingHandler.Update)
+
+			// Payment reminder policy
+			r.Get("/reminder-policy", reminderHandler.GetPolicy)
+			r.Put("/reminder-policy", reminderHandler.UpdatePolicy)
+
+			// Late fee policy
+			r.Get("/late-fee-policy", lateFeeHandler.GetPolicy)
+			r.Put("/late-fee-policy", lateFeeHandler.UpdatePolicy)
+
+			// Workspace branding and invoice templates
+			r.Route("/workspace", func(r chi.Router) {
+				r.Get("/profile", workspaceHandler.GetProfile)
+				r.Put("/profile", workspaceHandler.UpdateProfile)
+				r.Get("/logo", workspaceHandler.GetLogo)
+				r.Put("/logo", workspaceHandler.UploadLogo)
+				r.Delete("/logo", workspaceHandler.DeleteLogo)
+				r.Get("/templates", workspaceHandler.ListTemplates)
+				r.Post("/templates/preview", workspaceHandler.Preview)
+				r.Get("/templates/{kind}", workspaceHandler.GetTemplate)
+				r.Put("/templates/{kind}", workspaceHandler.SaveTemplate)
+				r.Delete("/templates/{kind}", workspaceHandler.DeleteTemplate)
+			})
+
+			// Recurring invoices
+			r.Route("/recurring-invoices", func(r chi.Router) {
+				r.Get("/", recurringInvoiceHandler.List)
+				r.Post("/", recurringInvoiceHandler.Create)
+				r.Get("/{id}", recurringInvoiceHandler.Get)
+				r.Put("/{id}", recurringInvoiceHandler.Update)
+				r.Delete("/{id}", recurringInvoiceHandler.Delete)
+			})
+
+			// Credit notes
+			r.Route("/credit-notes", func(r chi.Router) {
+				r.Get("/", creditNoteHandler.List)
+				r.Get("/{id}", creditNoteHandler.Get)
+			})
+
+			// Tax codes
+			r.Route("/tax-codes", func(r chi.Router) {
+				r.Get("/", taxCodeHandler.List)
+				r.Post("/", taxCodeHandler.Create)
+				r.Get("/{id}", taxCodeHandler.Get)
+				r.Put("/{id}", taxCodeHandler.Update)
+				r.Delete("/{id}", taxCodeHandler.Delete)
+			})
+
+			// Products and services catalog
+			r.Route("/items", func(r chi.Router) {
+				r.Get("/", catalogItemHandler.List)
+				r.Post("/", catalogItemHandler.Create)
+				r.Get("/{id}", catalogItemHandler.Get)
+				r.Put("/{id}", catalogItemHandler.Update)
+				r.Delete("/{id}", catalogItemHandler.Delete)
 			})
 
 			// Expenses
@@ -132,11 +280,20 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, db *sql.DB) (http.Hand
 				r.Put("/{id}", expenseHandler.Update)
 			})
 
+			// Exchange rates against the workspace base currency
+			r.Route("/exchange-rates", func(r chi.Router) {
+				r.Get("/", exchangeRateHandler.List)
+				r.Post("/import", exchangeRateHandler.Import)
+				r.Post("/sync", exchangeRateHandler.Sync)
+				r.Delete("/{id}", exchangeRateHandler.Delete)
+			})
+
 			// Reports
 			r.Route("/reports", func(r chi.Router) {
 				r.Get("/summary", reportHandler.GetSummary)
 				r.Get("/client-profit/{id}", reportHandler.GetClientProfitability)
 				r.Get("/tax-summary", reportHandler.GetTaxSummary)
+				r.Get("/items", reportHandler.GetItemRevenue)
 			})
 		})
 	})
@@ -30,6 +30,22 @@ type Config struct {
 	Logging struct {
 		Level string
 	}
+	Worker struct {
+		Enabled             bool
+		OverdueInterval     time.Duration
+		RecurringInterval   time.Duration
+		ReminderInterval    time.Duration
+		QuoteExpiryInterval time.Duration
+		LateFeeInterval     time.Duration
+	}
+	Storage struct {
+		Driver    string
+		LocalPath string
+	}
+	ExchangeRates struct {
+		Provider    string
+		FixturePath string
+	}
 	Email struct {
 		From string
 		SMTP struct {
@@ -63,6 +79,19 @@ func Load() (*Config, error) {
 
 	v.SetDefault("logging.level", "info")
 
+	v.SetDefault("worker.enabled", true)
+	v.SetDefault("worker.overdueinterval", "1h")
+	v.SetDefault("worker.recurringinterval", "1h")
+	v.SetDefault("worker.reminderinterval", "1h")
+	v.SetDefault("worker.quoteexpiryinterval", "1h")
+	v.SetDefault("worker.latefeeinterval", "1h")
+
+	v.SetDefault("storage.driver", "local")
+	v.SetDefault("storage.localpath", "./data/attachments")
+
+	v.SetDefault("exchangerates.provider", "fixture")
+	v.SetDefault("exchangerates.fixturepath", "")
+
 	v.SetDefault("email.from", "waitlist@billstack.com")
 	v.SetDefault("email.smtp.host", "smtp.gmail.com")
 	v.SetDefault("email.smtp.port", 587)
@@ -119,6 +148,22 @@ func Load() (*Config, error) {
 		cfg.Email.From = cfg.Email.SMTP.Username
 	}
 
+	if cfg.Worker.OverdueInterval <= 0 {
+		cfg.Worker.OverdueInterval = time.Hour
+	}
+	if cfg.Worker.RecurringInterval <= 0 {
+		cfg.Worker.RecurringInterval = time.Hour
+	}
+	if cfg.Worker.ReminderInterval <= 0 {
+		cfg.Worker.ReminderInterval = time.Hour
+	}
+	if cfg.Worker.QuoteExpiryInterval <= 0 {
+		cfg.Worker.QuoteExpiryInterval = time.Hour
+	}
+	if cfg.Worker.LateFeeInterval <= 0 {
+		cfg.Worker.LateFeeInterval = time.Hour
+	}
+
 	if cfg.Email.SMTP.Host == "" {
 		return nil, fmt.Errorf("email smtp host is required")
 	}
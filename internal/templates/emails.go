@@ -4,3 +4,21 @@ import _ "embed"
 
 //go:embed email/welcome.html
 var WelcomeEmailHTML string
+
+//go:embed email/invoice.html
+var InvoiceEmailHTML string
+
+//go:embed email/invoice_modern.html
+var InvoiceEmailModernHTML string
+
+//go:embed email/invoice_minimal.html
+var InvoiceEmailMinimalHTML string
+
+//go:embed email/invoice.txt
+var InvoiceEmailText string
+
+//go:embed email/reminder.html
+var ReminderEmailHTML string
+
+//go:embed email/reminder.txt
+var ReminderEmailText string
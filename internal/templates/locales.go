@@ -0,0 +1,9 @@
+package templates
+
+import "embed"
+
+// Locales holds the message catalogs, one JSON file per language. See
+// pkg/locale for the format.
+//
+//go:embed locales/*.json
+var Locales embed.FS
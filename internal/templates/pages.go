@@ -0,0 +1,12 @@
+package templates
+
+import _ "embed"
+
+//go:embed invoice/share.html
+var InvoiceShareHTML string
+
+//go:embed invoice/share_modern.html
+var InvoiceShareModernHTML string
+
+//go:embed invoice/share_minimal.html
+var InvoiceShareMinimalHTML string
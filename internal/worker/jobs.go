@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/nava1525/bilio-backend/internal/app/models"
+	appRepositories "github.com/nava1525/bilio-backend/internal/app/repositories"
+	appServices "github.com/nava1525/bilio-backend/internal/app/services"
+	"github.com/nava1525/bilio-backend/internal/config"
+	pkgblobstore "github.com/nava1525/bilio-backend/pkg/blobstore"
+	pkgmailer "github.com/nava1525/bilio-backend/pkg/mailer"
+)
+
+// New wires the services the background jobs depend on and registers every
+// job enabled by cfg.
+func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*Runner, error) {
+	userRepo := appRepositories.NewUserRepository(db)
+	clientRepo := appRepositories.NewClientRepository(db)
+	invoiceRepo := appRepositories.NewInvoiceRepository(db)
+	recurringInvoiceRepo := appRepositories.NewRecurringInvoiceRepository(db)
+	taxCodeRepo := appRepositories.NewTaxCodeRepository(db)
+	reminderRepo := appRepositories.NewReminderRepository(db)
+	quoteRepo := appRepositories.NewQuoteRepository(db)
+	invoiceAttachmentRepo := appRepositories.NewInvoiceAttachmentRepository(db)
+	lateFeeRepo := appRepositories.NewLateFeeRepository(db)
+	workspaceRepo := appRepositories.NewWorkspaceRepository(db)
+	catalogItemRepo := appRepositories.NewCatalogItemRepository(db)
+
+	mailer, err := pkgmailer.NewSMTPMailer(pkgmailer.SMTPConfig{
+		Host:     cfg.Email.SMTP.Host,
+		Port:     cfg.Email.SMTP.Port,
+		Username: cfg.Email.SMTP.Username,
+		Password: cfg.Email.SMTP.Password,
+		From:     cfg.Email.From,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialize mailer: %w", err)
+	}
+
+	blobs, err := pkgblobstore.New(pkgblobstore.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalPath: cfg.Storage.LocalPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialize blob store: %w", err)
+	}
+
+	invoiceService := appServices.NewInvoiceService(invoiceRepo, clientRepo, userRepo, workspaceRepo, taxCodeRepo, catalogItemRepo, invoiceAttachmentRepo, blobs, mailer)
+	recurringService := appServices.NewRecurringInvoiceService(recurringInvoiceRepo, clientRepo, invoiceService)
+	reminderService := appServices.NewReminderService(reminderRepo, invoiceService)
+	quoteService := appServices.NewQuoteService(quoteRepo, invoiceService)
+	lateFeeService := appServices.NewLateFeeService(lateFeeRepo, invoiceService)
+
+	runner := NewRunner(log)
+	runner.Register(overdueJob(invoiceService, log, cfg.Worker.OverdueInterval))
+	runner.Register(recurringJob(recurringService, log, cfg.Worker.RecurringInterval))
+	runner.Register(reminderJob(reminderService, log, cfg.Worker.ReminderInterval))
+	runner.Register(quoteExpiryJob(quoteService, log, cfg.Worker.QuoteExpiryInterval))
+	runner.Register(lateFeeJob(lateFeeService, log, cfg.Worker.LateFeeInterval))
+
+	return runner, nil
+}
+
+func overdueJob(invoices *appServices.InvoiceService, log zerolog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "invoice-overdue-sweep",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			transitions, err := invoices.MarkOverdueInvoices(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			for _, t := range transitions {
+				log.Info().
+					Str("invoice_id", t.InvoiceID).
+					Str("invoice_number", t.InvoiceNumber).
+					Str("user_id", t.UserID).
+					Str("from", string(t.From)).
+					Str("to", string(t.To)).
+					Msg("invoice marked overdue")
+			}
+			return nil
+		},
+	}
+}
+
+func recurringJob(recurring *appServices.RecurringInvoiceService, log zerolog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "recurring-invoice-generation",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			results, err := recurring.GenerateDue(ctx, time.Now())
+			for _, res := range results {
+				event := log.Info()
+				msg := "recurring invoice generated"
+				switch {
+				case res.Err != nil:
+					event = log.Error().Err(res.Err)
+					msg = "recurring invoice generation failed"
+				case res.SendErr != nil:
+					event = log.Warn().Err(res.SendErr)
+					msg = "recurring invoice generated but not sent"
+				case res.Duplicate:
+					msg = "recurring invoice period already billed"
+				}
+				event.
+					Str("recurring_invoice_id", res.RecurringInvoiceID).
+					Str("user_id", res.UserID).
+					Str("period", res.Period.Format("2006-01-02")).
+					Str("invoice_id", res.InvoiceID).
+					Str("invoice_number", res.InvoiceNumber).
+					Bool("sent", res.Sent).
+					Msg(msg)
+			}
+			return err
+		},
+	}
+}
+
+func reminderJob(reminders *appServices.ReminderService, log zerolog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "invoice-payment-reminders",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			results, err := reminders.SendDue(ctx, time.Now())
+			for _, res := range results {
+				event := log.Info()
+				msg := "payment reminder sent"
+				switch {
+				case res.Err != nil:
+					event = log.Error().Err(res.Err)
+					msg = "payment reminder failed"
+				case res.Duplicate:
+					msg = "payment reminder already handled"
+				case res.Status == models.InvoiceReminderSkipped:
+					msg = "payment reminder skipped; client has no email address"
+				}
+				event.
+					Str("invoice_id", res.InvoiceID).
+					Str("invoice_number", res.InvoiceNumber).
+					Str("user_id", res.UserID).
+					Int("offset_days", res.OffsetDays).
+					Msg(msg)
+			}
+			return err
+		},
+	}
+}
+
+func lateFeeJob(lateFees *appServices.LateFeeService, log zerolog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "invoice-late-fees",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			results, err := lateFees.ChargeDue(ctx, time.Now())
+			for _, res := range results {
+				if res.Err != nil {
+					log.Error().Err(res.Err).
+						Str("invoice_id", res.InvoiceID).
+						Str("user_id", res.UserID).
+						Msg("late fee failed")
+					continue
+				}
+				for _, fee := range res.Fees {
+					log.Info().
+						Str("invoice_id", res.InvoiceID).
+						Str("user_id", res.UserID).
+						Int("period", fee.Period).
+						Str("amount", fee.Amount.String()).
+						Msg("late fee charged")
+				}
+			}
+			return err
+		},
+	}
+}
+
+func quoteExpiryJob(quotes *appServices.QuoteService, log zerolog.Logger, interval time.Duration) Job {
+	return Job{
+		Name:     "quote-expiry-sweep",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			expired, err := quotes.ExpireQuotes(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if expired > 0 {
+				log.Info().Int64("count", expired).Msg("quotes marked expired")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Job is a unit of background work executed on a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner executes registered jobs. Each job runs once at startup and then on
+// its own ticker until the context is cancelled. Jobs are expected to be
+// idempotent so that several replicas can run the same schedule.
+type Runner struct {
+	log  zerolog.Logger
+	jobs []Job
+}
+
+func NewRunner(log zerolog.Logger) *Runner {
+	return &Runner{log: log}
+}
+
+func (r *Runner) Register(job Job) {
+	r.jobs = append(r.jobs, job)
+}
+
+// Run blocks until ctx is cancelled and every job has returned.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range r.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+
+	r.log.Info().Int("jobs", len(r.jobs)).Msg("background worker started")
+	wg.Wait()
+	r.log.Info().Msg("background worker stopped")
+}
+
+// RunOnce executes every job a single time, in registration order.
+func (r *Runner) RunOnce(ctx context.Context) error {
+	for _, job := range r.jobs {
+		if err := r.execute(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	_ = r.execute(ctx, job)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = r.execute(ctx, job)
+		}
+	}
+}
+
+func (r *Runner) execute(ctx context.Context, job Job) error {
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		r.log.Error().Err(err).Str("job", job.Name).Msg("background job failed")
+		return err
+	}
+	r.log.Debug().Str("job", job.Name).Dur("duration", time.Since(start)).Msg("background job finished")
+	return nil
+}
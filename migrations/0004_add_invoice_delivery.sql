@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Track when and to whom an invoice was last emailed
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS sent_at TIMESTAMPTZ;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS sent_to TEXT;
+
+COMMIT;
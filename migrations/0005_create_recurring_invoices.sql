@@ -0,0 +1,38 @@
+BEGIN;
+
+-- Templates that generate invoices on a schedule
+CREATE TABLE IF NOT EXISTS recurring_invoices (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    tax_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    items JSONB NOT NULL DEFAULT '[]',
+    cadence TEXT NOT NULL, -- weekly, monthly, quarterly, yearly, custom
+    cron_expression TEXT, -- required when cadence = custom
+    start_date DATE NOT NULL,
+    end_date DATE,
+    next_run_date DATE, -- NULL once the schedule has finished
+    last_run_at TIMESTAMPTZ,
+    payment_terms_days INTEGER,
+    auto_send BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_invoices_user_id ON recurring_invoices(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_invoices_next_run ON recurring_invoices(next_run_date) WHERE active;
+
+-- Link generated invoices back to their schedule. The unique period guards
+-- against generating the same occurrence twice after a restart.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS recurring_invoice_id TEXT REFERENCES recurring_invoices(id) ON DELETE SET NULL;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS recurring_period DATE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_recurring_period
+    ON invoices(recurring_invoice_id, recurring_period)
+    WHERE recurring_invoice_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Per-workspace invoice number format
+CREATE TABLE IF NOT EXISTS invoice_numbering (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    format TEXT NOT NULL DEFAULT 'INV-{SEQ:4}',
+    yearly_reset BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Next sequence value per workspace. period_year is 0 unless the workspace
+-- resets its sequence every year. The row is locked while a number is
+-- allocated and only advances when the invoice insert commits, so numbers
+-- are gap-free.
+CREATE TABLE IF NOT EXISTS invoice_number_counters (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_year INTEGER NOT NULL DEFAULT 0,
+    next_value BIGINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (user_id, period_year)
+);
+
+COMMIT;
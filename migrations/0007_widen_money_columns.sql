@@ -0,0 +1,18 @@
+BEGIN;
+
+-- Amounts are handled as exact decimals in the application. Widen the money
+-- columns so three-decimal currencies (BHD, KWD, ...) and sub-cent unit
+-- prices are stored without Postgres silently rounding them. Widening is
+-- lossless for existing rows.
+ALTER TABLE invoices ALTER COLUMN subtotal TYPE DECIMAL(18,3);
+ALTER TABLE invoices ALTER COLUMN tax_amount TYPE DECIMAL(18,3);
+ALTER TABLE invoices ALTER COLUMN total TYPE DECIMAL(18,3);
+
+ALTER TABLE invoice_items ALTER COLUMN quantity TYPE DECIMAL(12,3);
+ALTER TABLE invoice_items ALTER COLUMN unit_price TYPE DECIMAL(18,4);
+ALTER TABLE invoice_items ALTER COLUMN amount TYPE DECIMAL(18,3);
+
+ALTER TABLE payments ALTER COLUMN amount TYPE DECIMAL(18,3);
+ALTER TABLE expenses ALTER COLUMN amount TYPE DECIMAL(18,3);
+
+COMMIT;
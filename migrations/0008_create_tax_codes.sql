@@ -0,0 +1,31 @@
+BEGIN;
+
+-- Reusable tax codes, e.g. "GST18" = CGST 9% + SGST 9%, or "VAT20"
+CREATE TABLE IF NOT EXISTS tax_codes (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    components JSONB NOT NULL DEFAULT '[]', -- [{"name": "CGST", "rate": 9}]
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_codes_user_id ON tax_codes(user_id);
+
+-- Each item keeps a snapshot of the tax components applied to it, so later
+-- edits to a tax code never change issued invoices.
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS tax_code_id TEXT REFERENCES tax_codes(id) ON DELETE SET NULL;
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS taxes JSONB NOT NULL DEFAULT '[]';
+
+-- Tax totals per component across the invoice's items
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS tax_breakdown JSONB NOT NULL DEFAULT '[]';
+
+-- Invoices created before line-level taxes carry their single invoice-wide rate
+UPDATE invoices
+SET tax_breakdown = jsonb_build_array(jsonb_build_object(
+    'name', 'Tax', 'rate', tax_rate, 'taxable_amount', subtotal, 'amount', tax_amount))
+WHERE tax_amount <> 0 AND tax_breakdown = '[]';
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- Item discounts reduce the line before tax. amount stays the net line
+-- amount, so subtotal is still the sum of item amounts.
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS discount_type TEXT
+    CHECK (discount_type IN ('percentage', 'fixed'));
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS discount_value DECIMAL(18, 4);
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0;
+
+-- Invoice-level discount, applied before or after tax
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS line_discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS discount_type TEXT
+    CHECK (discount_type IN ('percentage', 'fixed'));
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS discount_value DECIMAL(18, 4);
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS discount_timing TEXT NOT NULL DEFAULT 'pre_tax'
+    CHECK (discount_timing IN ('pre_tax', 'post_tax'));
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0;
+
+ALTER TABLE recurring_invoices ADD COLUMN IF NOT EXISTS discount_type TEXT
+    CHECK (discount_type IN ('percentage', 'fixed'));
+ALTER TABLE recurring_invoices ADD COLUMN IF NOT EXISTS discount_value DECIMAL(18, 4);
+ALTER TABLE recurring_invoices ADD COLUMN IF NOT EXISTS discount_timing TEXT NOT NULL DEFAULT 'pre_tax'
+    CHECK (discount_timing IN ('pre_tax', 'post_tax'));
+
+COMMIT;
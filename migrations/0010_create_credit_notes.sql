@@ -0,0 +1,55 @@
+BEGIN;
+
+-- Credit notes reverse all or part of an issued invoice. Figures are stored
+-- as positive amounts; they are subtracted from the invoice they credit.
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invoice_id TEXT NOT NULL REFERENCES invoices(id) ON DELETE RESTRICT,
+    client_id TEXT NOT NULL REFERENCES clients(id) ON DELETE RESTRICT,
+    credit_note_number TEXT NOT NULL,
+    issue_date TIMESTAMPTZ NOT NULL,
+    currency TEXT NOT NULL,
+    reason TEXT,
+    subtotal DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    tax_breakdown JSONB NOT NULL DEFAULT '[]',
+    total DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, credit_note_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_notes_user_id ON credit_notes(user_id);
+CREATE INDEX IF NOT EXISTS idx_credit_notes_invoice_id ON credit_notes(invoice_id);
+
+CREATE TABLE IF NOT EXISTS credit_note_items (
+    id TEXT PRIMARY KEY,
+    credit_note_id TEXT NOT NULL REFERENCES credit_notes(id) ON DELETE CASCADE,
+    invoice_item_id TEXT REFERENCES invoice_items(id) ON DELETE SET NULL,
+    description TEXT NOT NULL,
+    quantity DECIMAL(12, 3) NOT NULL,
+    unit_price DECIMAL(18, 4) NOT NULL,
+    amount DECIMAL(18, 3) NOT NULL,
+    taxes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_note_items_credit_note_id ON credit_note_items(credit_note_id);
+CREATE INDEX IF NOT EXISTS idx_credit_note_items_invoice_item_id ON credit_note_items(invoice_item_id);
+
+-- Refunds are payments with a negative amount linked to the credit note
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS credit_note_id TEXT REFERENCES credit_notes(id) ON DELETE RESTRICT;
+
+-- Credit notes are numbered from their own sequence
+ALTER TABLE invoice_numbering ADD COLUMN IF NOT EXISTS credit_note_format TEXT NOT NULL DEFAULT 'CN-{SEQ:4}';
+
+CREATE TABLE IF NOT EXISTS credit_note_number_counters (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_year INTEGER NOT NULL DEFAULT 0,
+    next_value BIGINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (user_id, period_year)
+);
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+-- Issued invoices are voided rather than deleted, so their number stays
+-- reserved and the record is kept as it was.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS voided_at TIMESTAMPTZ;
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+-- Audit trail of invoice voids and draft deletions. invoice_id is not a
+-- foreign key so entries outlive the drafts they describe.
+CREATE TABLE IF NOT EXISTS invoice_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invoice_id TEXT NOT NULL,
+    invoice_number TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('voided', 'deleted')),
+    from_status TEXT NOT NULL,
+    to_status TEXT,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_events_invoice ON invoice_events(invoice_id, created_at);
+
+COMMIT;
@@ -0,0 +1,28 @@
+BEGIN;
+
+-- Share links let a client view an invoice without an account. The token is
+-- derived from the link ID and signed, so nothing secret is stored here.
+CREATE TABLE IF NOT EXISTS invoice_share_links (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invoice_id TEXT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_share_links_invoice ON invoice_share_links(invoice_id);
+
+CREATE TABLE IF NOT EXISTS invoice_share_views (
+    id TEXT PRIMARY KEY,
+    share_link_id TEXT NOT NULL REFERENCES invoice_share_links(id) ON DELETE CASCADE,
+    invoice_id TEXT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    format TEXT NOT NULL CHECK (format IN ('html', 'json', 'pdf')),
+    ip_address TEXT,
+    user_agent TEXT,
+    viewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_share_views_link ON invoice_share_views(share_link_id, viewed_at);
+
+COMMIT;
@@ -0,0 +1,30 @@
+BEGIN;
+
+-- One reminder policy per workspace. steps is a list of
+-- {"offset_days", "subject", "body"} relative to the invoice due date;
+-- negative offsets fall before it.
+CREATE TABLE IF NOT EXISTS reminder_policies (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    steps JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Every reminder sent, or skipped because the client had no email address.
+-- The unique key means each step goes out at most once per invoice, even
+-- with several workers running.
+CREATE TABLE IF NOT EXISTS invoice_reminders (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invoice_id TEXT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    offset_days INTEGER NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'skipped')),
+    sent_to TEXT,
+    subject TEXT NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (invoice_id, offset_days)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_reminders_invoice ON invoice_reminders(invoice_id, sent_at);
+
+COMMIT;
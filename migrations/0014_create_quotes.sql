@@ -0,0 +1,77 @@
+BEGIN;
+
+-- Quotes (estimates) share the invoice's pricing structure and can be
+-- converted into an invoice once accepted.
+CREATE TABLE IF NOT EXISTS quotes (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    quote_number TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'sent', 'accepted', 'declined', 'expired')),
+    issue_date DATE NOT NULL,
+    expiry_date DATE,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    subtotal DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    line_discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    discount_type TEXT CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value DECIMAL(18, 4),
+    discount_timing TEXT NOT NULL DEFAULT 'pre_tax'
+        CHECK (discount_timing IN ('pre_tax', 'post_tax')),
+    discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    tax_breakdown JSONB NOT NULL DEFAULT '[]',
+    total DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    notes TEXT,
+    sent_at TIMESTAMPTZ,
+    accepted_at TIMESTAMPTZ,
+    accepted_by TEXT,
+    declined_at TIMESTAMPTZ,
+    decline_reason TEXT,
+    invoice_id TEXT REFERENCES invoices(id) ON DELETE SET NULL,
+    converted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, quote_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotes_user_id ON quotes(user_id);
+CREATE INDEX IF NOT EXISTS idx_quotes_client_id ON quotes(client_id);
+CREATE INDEX IF NOT EXISTS idx_quotes_expiry ON quotes(expiry_date) WHERE status = 'sent';
+
+CREATE TABLE IF NOT EXISTS quote_items (
+    id TEXT PRIMARY KEY,
+    quote_id TEXT NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    description TEXT NOT NULL,
+    quantity DECIMAL(12, 3) NOT NULL,
+    unit_price DECIMAL(18, 4) NOT NULL,
+    discount_type TEXT CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value DECIMAL(18, 4),
+    discount_amount DECIMAL(18, 3) NOT NULL DEFAULT 0,
+    amount DECIMAL(18, 3) NOT NULL,
+    tax_code_id TEXT REFERENCES tax_codes(id) ON DELETE SET NULL,
+    taxes JSONB NOT NULL DEFAULT '[]',
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_items_quote_id ON quote_items(quote_id);
+
+-- Invoices created from a quote link back to it. A quote converts at most
+-- once.
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS quote_id TEXT REFERENCES quotes(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_quote_id ON invoices(quote_id) WHERE quote_id IS NOT NULL;
+
+-- Quotes are numbered from their own sequence
+ALTER TABLE invoice_numbering ADD COLUMN IF NOT EXISTS quote_format TEXT NOT NULL DEFAULT 'QUO-{SEQ:4}';
+
+CREATE TABLE IF NOT EXISTS quote_number_counters (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_year INTEGER NOT NULL DEFAULT 0,
+    next_value BIGINT NOT NULL DEFAULT 1,
+    PRIMARY KEY (user_id, period_year)
+);
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN;
+
+-- Late fee policies. A row without client_id is the workspace default; a row
+-- with one overrides it for that client, and a disabled override opts the
+-- client out. amount is a flat fee in the invoice's currency or, for
+-- percentage policies, the monthly interest rate.
+CREATE TABLE IF NOT EXISTS late_fee_policies (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    fee_type TEXT NOT NULL CHECK (fee_type IN ('flat', 'percentage')),
+    amount DECIMAL(18,4) NOT NULL CHECK (amount > 0),
+    grace_days INTEGER NOT NULL DEFAULT 0 CHECK (grace_days >= 0),
+    max_amount DECIMAL(18,3) CHECK (max_amount > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_late_fee_policies_workspace ON late_fee_policies(user_id) WHERE client_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_late_fee_policies_client ON late_fee_policies(user_id, client_id) WHERE client_id IS NOT NULL;
+
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS late_fee BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Every late fee charged. Flat fees are period 1; percentage policies charge
+-- period n once n-1 months have passed since the grace period ended. The
+-- unique key keeps repeated runs from charging a period twice.
+CREATE TABLE IF NOT EXISTS invoice_late_fees (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invoice_id TEXT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    invoice_item_id TEXT REFERENCES invoice_items(id) ON DELETE SET NULL,
+    period INTEGER NOT NULL CHECK (period > 0),
+    amount DECIMAL(18,3) NOT NULL,
+    charged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (invoice_id, period)
+);
+
+COMMIT;
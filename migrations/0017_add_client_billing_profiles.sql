@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Billing defaults applied to a client's new invoices when the invoice leaves
+-- the field out.
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS payment_terms TEXT; -- due_on_receipt, net_N or eom
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS default_tax_rate DECIMAL(5,2);
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS default_tax_code_id TEXT REFERENCES tax_codes(id) ON DELETE SET NULL;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS language TEXT;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS cc_emails JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS notes_footer TEXT;
+
+-- Language the invoice is written in, e.g. "en" or "de"
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS language TEXT;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- Client credit ledger. Deposits, overpayments and credit notes add credit
+-- (positive amounts); credit applied to an invoice takes it away (negative
+-- amounts). A client's balance in a currency is the sum of its entries.
+CREATE TABLE IF NOT EXISTS client_credits (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id TEXT NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('deposit', 'overpayment', 'credit_note', 'applied')),
+    amount DECIMAL(18,3) NOT NULL CHECK (amount <> 0),
+    currency TEXT NOT NULL,
+    invoice_id TEXT REFERENCES invoices(id) ON DELETE SET NULL,
+    payment_id TEXT REFERENCES payments(id) ON DELETE SET NULL,
+    credit_note_id TEXT REFERENCES credit_notes(id) ON DELETE SET NULL,
+    payment_method TEXT,
+    notes TEXT,
+    entry_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_credits_client ON client_credits(client_id, currency);
+CREATE INDEX IF NOT EXISTS idx_client_credits_user ON client_credits(user_id);
+
+COMMIT;
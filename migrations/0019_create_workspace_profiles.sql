@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Branding shown on a workspace's invoices, one row per workspace.
+CREATE TABLE IF NOT EXISTS workspace_profiles (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    legal_name TEXT,
+    address TEXT,
+    tax_id TEXT,
+    bank_details TEXT,
+    footer_text TEXT,
+    brand_color TEXT,  -- "#rrggbb"
+    accent_color TEXT, -- "#rrggbb"
+    invoice_layout TEXT NOT NULL DEFAULT 'classic' CHECK (invoice_layout IN ('classic', 'modern', 'minimal')),
+    logo_key TEXT,
+    logo_content_type TEXT,
+    logo_size_bytes BIGINT,
+    logo_width INTEGER,
+    logo_height INTEGER,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Workspace overrides of the built-in invoice templates. kind is the
+-- rendering the template replaces: the shared invoice page or the invoice
+-- email.
+CREATE TABLE IF NOT EXISTS workspace_templates (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('share', 'email')),
+    content TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind)
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Locale invoices are rendered in, e.g. "en-US" or "de-DE"; a client's
+-- language overrides it
+ALTER TABLE workspace_profiles ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en-US';
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- Currency reports are converted into, e.g. "USD"
+ALTER TABLE workspace_profiles ADD COLUMN IF NOT EXISTS base_currency TEXT NOT NULL DEFAULT 'USD';
+
+-- Dated exchange rates: one unit of currency is worth rate units of
+-- base_currency on rate_date.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    base_currency TEXT NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(20,10) NOT NULL CHECK (rate > 0),
+    source TEXT NOT NULL CHECK (source IN ('upload', 'provider')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, base_currency, currency, rate_date)
+);
+
+COMMIT;
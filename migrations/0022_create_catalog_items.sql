@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Products and services a workspace sells, with a default unit price per
+-- currency
+CREATE TABLE IF NOT EXISTS catalog_items (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    sku TEXT,
+    unit TEXT NOT NULL DEFAULT 'piece' CHECK (unit IN ('hour', 'day', 'piece')),
+    prices JSONB NOT NULL DEFAULT '[]', -- [{"currency": "USD", "unit_price": 120}]
+    tax_code_id TEXT REFERENCES tax_codes(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_items_user_id ON catalog_items(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_items_sku ON catalog_items(user_id, sku) WHERE sku IS NOT NULL;
+
+-- Items billed from the catalog keep a link to it along with a snapshot of
+-- its unit and SKU, so later catalog edits never change issued documents.
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS catalog_item_id TEXT REFERENCES catalog_items(id) ON DELETE SET NULL;
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS unit TEXT;
+ALTER TABLE invoice_items ADD COLUMN IF NOT EXISTS sku TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_invoice_items_catalog_item_id ON invoice_items(catalog_item_id) WHERE catalog_item_id IS NOT NULL;
+
+ALTER TABLE quote_items ADD COLUMN IF NOT EXISTS catalog_item_id TEXT REFERENCES catalog_items(id) ON DELETE SET NULL;
+ALTER TABLE quote_items ADD COLUMN IF NOT EXISTS unit TEXT;
+ALTER TABLE quote_items ADD COLUMN IF NOT EXISTS sku TEXT;
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+-- 0021 gave every workspace a USD base currency. From here on a NULL base
+-- currency means the workspace has not chosen one, and reports fall back to
+-- the currency most of its invoices are in.
+--
+-- The backfill runs once, while the column still has 0021's NOT NULL: a
+-- workspace that has loaded no rates cannot have relied on the USD default,
+-- so it takes the currency most of its invoices are in, the most recently
+-- used one on a tie. Workspaces with invoices but no profile get one.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'workspace_profiles' AND column_name = 'base_currency' AND is_nullable = 'NO'
+    ) THEN
+        ALTER TABLE workspace_profiles ALTER COLUMN base_currency DROP DEFAULT;
+        ALTER TABLE workspace_profiles ALTER COLUMN base_currency DROP NOT NULL;
+
+        WITH dominant AS (
+            SELECT DISTINCT ON (user_id) user_id, currency
+            FROM invoices
+            WHERE status <> 'void'
+            GROUP BY user_id, currency
+            ORDER BY user_id, COUNT(*) DESC, MAX(created_at) DESC
+        )
+        INSERT INTO workspace_profiles (user_id, base_currency)
+        SELECT d.user_id, d.currency
+        FROM dominant d
+        WHERE NOT EXISTS (SELECT 1 FROM exchange_rates r WHERE r.user_id = d.user_id)
+        ON CONFLICT (user_id) DO UPDATE SET base_currency = EXCLUDED.base_currency
+        WHERE workspace_profiles.base_currency = 'USD';
+    END IF;
+END $$;
+
+COMMIT;
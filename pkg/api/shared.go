@@ -5,7 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -15,7 +19,10 @@ import (
 	"github.com/nava1525/bilio-backend/internal/config"
 	"github.com/nava1525/bilio-backend/internal/database"
 	"github.com/nava1525/bilio-backend/internal/logger"
+	pkgblobstore "github.com/nava1525/bilio-backend/pkg/blobstore"
+	pkgfxrates "github.com/nava1525/bilio-backend/pkg/fxrates"
 	pkgmailer "github.com/nava1525/bilio-backend/pkg/mailer"
+	"github.com/nava1525/bilio-backend/pkg/pdf"
 )
 
 var (
@@ -25,14 +32,25 @@ var (
 	sharedLogger logger.Logger
 
 	// Services
-	authService      *services.AuthService
-	clientService    *services.ClientService
-	invoiceService   *services.InvoiceService
-	expenseService   *services.ExpenseService
-	reportService    *services.ReportService
-	waitlistService  *services.WaitlistService
-	promocodeService *services.PromocodeService
-	userService      *services.UserService
+	authService         *services.AuthService
+	clientService       *services.ClientService
+	invoiceService      *services.InvoiceService
+	recurringService    *services.RecurringInvoiceService
+	numberingService    *services.InvoiceNumberingService
+	taxCodeService      *services.TaxCodeService
+	catalogItemService  *services.CatalogItemService
+	creditNoteService   *services.CreditNoteService
+	shareService        *services.InvoiceShareService
+	reminderService     *services.ReminderService
+	quoteService        *services.QuoteService
+	lateFeeService      *services.LateFeeService
+	workspaceService    *services.WorkspaceService
+	expenseService      *services.ExpenseService
+	exchangeRateService *services.ExchangeRateService
+	reportService       *services.ReportService
+	waitlistService     *services.WaitlistService
+	promocodeService    *services.PromocodeService
+	userService         *services.UserService
 )
 
 func initServices() error {
@@ -55,18 +73,21 @@ func initServices() error {
 	userRepo := repositories.NewUserRepository(sharedDB)
 	clientRepo := repositories.NewClientRepository(sharedDB)
 	invoiceRepo := repositories.NewInvoiceRepository(sharedDB)
+	recurringInvoiceRepo := repositories.NewRecurringInvoiceRepository(sharedDB)
+	invoiceNumberingRepo := repositories.NewInvoiceNumberingRepository(sharedDB)
+	taxCodeRepo := repositories.NewTaxCodeRepository(sharedDB)
+	invoiceShareRepo := repositories.NewInvoiceShareRepository(sharedDB)
+	reminderRepo := repositories.NewReminderRepository(sharedDB)
+	quoteRepo := repositories.NewQuoteRepository(sharedDB)
+	invoiceAttachmentRepo := repositories.NewInvoiceAttachmentRepository(sharedDB)
+	lateFeeRepo := repositories.NewLateFeeRepository(sharedDB)
+	workspaceRepo := repositories.NewWorkspaceRepository(sharedDB)
+	exchangeRateRepo := repositories.NewExchangeRateRepository(sharedDB)
+	catalogItemRepo := repositories.NewCatalogItemRepository(sharedDB)
 	expenseRepo := repositories.NewExpenseRepository(sharedDB)
 	waitlistRepo := repositories.NewWaitlistRepository(sharedDB)
 	promocodeRepo := repositories.NewPromocodeRepository(sharedDB)
 
-	// Services
-	authService = services.NewAuthService(userRepo)
-	clientService = services.NewClientService(clientRepo)
-	invoiceService = services.NewInvoiceService(invoiceRepo, clientRepo)
-	expenseService = services.NewExpenseService(expenseRepo, clientRepo)
-	reportService = services.NewReportService(invoiceRepo, expenseRepo, clientRepo)
-	userService = services.NewUserService(userRepo)
-
 	// Email service
 	if cfg.Email.SMTP.Username == "" || cfg.Email.SMTP.Password == "" {
 		return fmt.Errorf("email smtp credentials missing; set EMAIL_USER and EMAIL_PASSWORD")
@@ -83,6 +104,43 @@ func initServices() error {
 		return fmt.Errorf("initialize mailer: %w", err)
 	}
 
+	// Attachment storage
+	blobs, err := pkgblobstore.New(pkgblobstore.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalPath: cfg.Storage.LocalPath,
+	})
+	if err != nil {
+		return fmt.Errorf("initialize blob store: %w", err)
+	}
+
+	// Exchange rate provider
+	rateProvider, err := pkgfxrates.New(pkgfxrates.Config{
+		Driver:      cfg.ExchangeRates.Provider,
+		FixturePath: cfg.ExchangeRates.FixturePath,
+	})
+	if err != nil {
+		return fmt.Errorf("initialize exchange rate provider: %w", err)
+	}
+
+	// Services
+	authService = services.NewAuthService(userRepo)
+	clientService = services.NewClientService(clientRepo, taxCodeRepo, invoiceRepo)
+	invoiceService = services.NewInvoiceService(invoiceRepo, clientRepo, userRepo, workspaceRepo, taxCodeRepo, catalogItemRepo, invoiceAttachmentRepo, blobs, mailer)
+	recurringService = services.NewRecurringInvoiceService(recurringInvoiceRepo, clientRepo, invoiceService)
+	numberingService = services.NewInvoiceNumberingService(invoiceNumberingRepo)
+	taxCodeService = services.NewTaxCodeService(taxCodeRepo)
+	catalogItemService = services.NewCatalogItemService(catalogItemRepo, taxCodeRepo)
+	creditNoteService = services.NewCreditNoteService(invoiceRepo)
+	shareService = services.NewInvoiceShareService(invoiceShareRepo, invoiceService)
+	reminderService = services.NewReminderService(reminderRepo, invoiceService)
+	quoteService = services.NewQuoteService(quoteRepo, invoiceService)
+	lateFeeService = services.NewLateFeeService(lateFeeRepo, invoiceService)
+	workspaceService = services.NewWorkspaceService(workspaceRepo, invoiceService)
+	expenseService = services.NewExpenseService(expenseRepo, clientRepo)
+	exchangeRateService = services.NewExchangeRateService(exchangeRateRepo, workspaceRepo, rateProvider)
+	reportService = services.NewReportService(invoiceRepo, expenseRepo, clientRepo, exchangeRateRepo, workspaceRepo, catalogItemRepo)
+	userService = services.NewUserService(userRepo)
+
 	waitlistService = services.NewWaitlistService(waitlistRepo, promocodeRepo, mailer)
 	promocodeService = services.NewPromocodeService(promocodeRepo)
 
@@ -102,7 +160,7 @@ func EnsureInitialized() error {
 func HandleCORS(w http.ResponseWriter, r *http.Request) {
 	// Ensure initialization before accessing sharedConfig
 	_ = EnsureInitialized()
-	
+
 	origins := []string{"*"}
 	if sharedConfig != nil && len(sharedConfig.CORS.AllowedOrigins) > 0 {
 		origins = sharedConfig.CORS.AllowedOrigins
@@ -149,6 +207,74 @@ func RespondError(w http.ResponseWriter, status int, message string) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
+func RespondPDF(w http.ResponseWriter, filename string, content []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+// RespondAttachment streams a stored attachment as a download.
+func RespondAttachment(w http.ResponseWriter, attachment *models.InvoiceAttachment, content io.Reader) {
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+// AttachmentPart limits the request body to the attachment size and returns
+// the "file" part of a multipart/form-data upload.
+func AttachmentPart(w http.ResponseWriter, r *http.Request) (*multipart.Part, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxAttachmentSize+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// RespondLogo streams the workspace logo.
+func RespondLogo(w http.ResponseWriter, logo *models.WorkspaceLogo, content io.Reader) {
+	w.Header().Set("Content-Type", logo.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(logo.SizeBytes, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+// RespondPreview writes a rendered invoice preview.
+func RespondPreview(w http.ResponseWriter, preview *services.InvoicePreview) {
+	w.Header().Set("Content-Type", preview.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(preview.Content)))
+	w.Header().Set("Content-Security-Policy", services.InvoicePageCSP)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(preview.Content)
+}
+
+// RespondHTML serves a rendered invoice page under the same
+// Content-Security-Policy as the chi handlers.
+func RespondHTML(w http.ResponseWriter, page []byte) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(page)))
+	w.Header().Set("Content-Security-Policy", services.InvoicePageCSP)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(page)
+}
+
 func GetUserID(r *http.Request) (string, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
@@ -201,12 +327,78 @@ func GetInvoiceService() *services.InvoiceService {
 	return invoiceService
 }
 
+// GetRecurringInvoiceService returns the initialized recurring invoice service
+func GetRecurringInvoiceService() *services.RecurringInvoiceService {
+	_ = EnsureInitialized()
+	return recurringService
+}
+
+// GetInvoiceNumberingService returns the initialized invoice numbering service
+func GetInvoiceNumberingService() *services.InvoiceNumberingService {
+	_ = EnsureInitialized()
+	return numberingService
+}
+
+// GetTaxCodeService returns the initialized tax code service
+func GetTaxCodeService() *services.TaxCodeService {
+	_ = EnsureInitialized()
+	return taxCodeService
+}
+
+// GetCatalogItemService returns the initialized catalog item service
+func GetCatalogItemService() *services.CatalogItemService {
+	_ = EnsureInitialized()
+	return catalogItemService
+}
+
+// GetCreditNoteService returns the initialized credit note service
+func GetCreditNoteService() *services.CreditNoteService {
+	_ = EnsureInitialized()
+	return creditNoteService
+}
+
+// GetInvoiceShareService returns the initialized invoice share service
+func GetInvoiceShareService() *services.InvoiceShareService {
+	_ = EnsureInitialized()
+	return shareService
+}
+
+// GetReminderService returns the initialized payment reminder service
+func GetReminderService() *services.ReminderService {
+	_ = EnsureInitialized()
+	return reminderService
+}
+
+// GetQuoteService returns the initialized quote service
+func GetQuoteService() *services.QuoteService {
+	_ = EnsureInitialized()
+	return quoteService
+}
+
+// GetLateFeeService returns the initialized late fee service
+func GetLateFeeService() *services.LateFeeService {
+	_ = EnsureInitialized()
+	return lateFeeService
+}
+
+// GetWorkspaceService returns the initialized workspace service
+func GetWorkspaceService() *services.WorkspaceService {
+	_ = EnsureInitialized()
+	return workspaceService
+}
+
 // GetExpenseService returns the initialized expense service
 func GetExpenseService() *services.ExpenseService {
 	_ = EnsureInitialized()
 	return expenseService
 }
 
+// GetExchangeRateService returns the initialized exchange rate service
+func GetExchangeRateService() *services.ExchangeRateService {
+	_ = EnsureInitialized()
+	return exchangeRateService
+}
+
 // GetReportService returns the initialized report service
 func GetReportService() *services.ReportService {
 	_ = EnsureInitialized()
@@ -267,20 +459,72 @@ type (
 	RegisterInput = services.RegisterInput
 
 	// Client service types
-	CreateClientInput = services.CreateClientInput
-	UpdateClientInput = services.UpdateClientInput
+	CreateClientInput  = services.CreateClientInput
+	UpdateClientInput  = services.UpdateClientInput
+	CreateDepositInput = services.CreateDepositInput
 
 	// Invoice service types
-	CreateInvoiceInput = services.CreateInvoiceInput
-	UpdateInvoiceInput = services.UpdateInvoiceInput
-	InvoiceFilters     = services.InvoiceFilters
-	CreatePaymentInput = services.CreatePaymentInput
+	CreateInvoiceInput    = services.CreateInvoiceInput
+	UpdateInvoiceInput    = services.UpdateInvoiceInput
+	InvoiceFilters        = services.InvoiceFilters
+	CreatePaymentInput    = services.CreatePaymentInput
+	SendInvoiceInput      = services.SendInvoiceInput
+	VoidInvoiceInput      = services.VoidInvoiceInput
+	DuplicateInvoiceInput = services.DuplicateInvoiceInput
+	UploadAttachmentInput = services.UploadAttachmentInput
+
+	// Recurring invoice service types
+	CreateRecurringInvoiceInput = services.CreateRecurringInvoiceInput
+	UpdateRecurringInvoiceInput = services.UpdateRecurringInvoiceInput
+
+	// Invoice numbering service types
+	UpdateInvoiceNumberingInput = services.UpdateInvoiceNumberingInput
+
+	// Tax code service types
+	TaxCodeInput = services.TaxCodeInput
+
+	// Catalog item service types
+	CatalogItemInput   = services.CatalogItemInput
+	CatalogItemFilters = services.CatalogItemFilters
+
+	// Invoice share service types
+	CreateShareLinkInput = services.CreateShareLinkInput
+	ShareViewer          = services.ShareViewer
+
+	// Reminder service types
+	UpdateReminderPolicyInput = services.UpdateReminderPolicyInput
+
+	// Late fee service types
+	UpdateLateFeePolicyInput = services.UpdateLateFeePolicyInput
+
+	// Workspace service types
+	UpdateWorkspaceProfileInput = services.UpdateWorkspaceProfileInput
+	UploadLogoInput             = services.UploadLogoInput
+	SaveTemplateInput           = services.SaveTemplateInput
+	PreviewInvoiceInput         = services.PreviewInvoiceInput
+
+	// Quote service types
+	CreateQuoteInput  = services.CreateQuoteInput
+	UpdateQuoteInput  = services.UpdateQuoteInput
+	QuoteFilters      = services.QuoteFilters
+	ConvertQuoteInput = services.ConvertQuoteInput
+	AcceptQuoteInput  = services.AcceptQuoteInput
+	DeclineQuoteInput = services.DeclineQuoteInput
+
+	// Credit note service types
+	CreateCreditNoteInput = services.CreateCreditNoteInput
+	CreditNoteFilters     = services.CreditNoteFilters
 
 	// Expense service types
 	CreateExpenseInput = services.CreateExpenseInput
 	UpdateExpenseInput = services.UpdateExpenseInput
 	ExpenseFilters     = services.ExpenseFilters
 
+	// Exchange rate service types
+	ExchangeRateFilters      = services.ExchangeRateFilters
+	ImportExchangeRatesInput = services.ImportExchangeRatesInput
+	SyncExchangeRatesInput   = services.SyncExchangeRatesInput
+
 	// User service types
 	CreateUserInput = services.CreateUserInput
 
@@ -290,8 +534,35 @@ type (
 
 // Re-export model types
 type InvoiceStatus = models.InvoiceStatus
+type QuoteStatus = models.QuoteStatus
+type InvoiceLayout = models.InvoiceLayout
+type TemplateKind = models.TemplateKind
 
 // Re-export service functions
 func AsValidationError(err error) (services.ValidationError, bool) {
 	return services.AsValidationError(err)
 }
+
+// RatesFilePart limits the request body to the rate file size and returns
+// the "file" part of a multipart/form-data upload.
+func RatesFilePart(w http.ResponseWriter, r *http.Request) (*multipart.Part, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxExchangeRateFileSize+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+func ParsePageSize(value string) (pdf.PageSize, error) {
+	return services.ParsePageSize(value)
+}
@@ -0,0 +1,167 @@
+// Package cron parses standard five-field cron expressions
+// (minute hour day-of-month month day-of-week) for date-level scheduling.
+//
+// Billing schedules only care about which calendar days match, so the minute
+// and hour fields are validated but not used when computing the next date.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds NextDate so impossible expressions such as
+// "0 0 31 2 *" terminate.
+const maxSearchDays = 366 * 5
+
+type Schedule struct {
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	domStar     bool
+	dowStar     bool
+}
+
+type fieldSpec struct {
+	name     string
+	min, max int
+}
+
+var fieldSpecs = []fieldSpec{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// Parse validates expr and returns its schedule.
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(fieldSpecs) {
+		return nil, fmt.Errorf("cron expression must have %d fields, got %d", len(fieldSpecs), len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		names := map[string]int(nil)
+		switch i {
+		case 3:
+			names = monthNames
+		case 4:
+			names = dayNames
+		}
+		b, err := parseField(field, fieldSpecs[i], names)
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	dow := bits[4]
+	if dow&(1<<7) != 0 {
+		// 7 is an alias for Sunday.
+		dow |= 1
+	}
+
+	return &Schedule{
+		daysOfMonth: bits[2],
+		months:      bits[3],
+		daysOfWeek:  dow,
+		domStar:     strings.HasPrefix(fields[2], "*"),
+		dowStar:     strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseField(field string, spec fieldSpec, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", spec.name, field)
+			}
+			step = n
+		}
+
+		lo, hi := spec.min, spec.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], spec, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = parseValue(bounds[1], spec, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = spec.max
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field %q", spec.name, field)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, spec fieldSpec, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < spec.min || n > spec.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (allowed %d-%d)", value, spec.name, spec.min, spec.max)
+	}
+	return n, nil
+}
+
+// Matches reports whether the calendar day of t satisfies the schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.months&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.daysOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	// Standard cron semantics: when both day fields are restricted, a day
+	// matching either one qualifies.
+	if !s.domStar && !s.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// NextDate returns the first matching calendar day strictly after t, at
+// midnight in t's location. The zero time is returned when nothing matches
+// within five years.
+func (s *Schedule) NextDate(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i < maxSearchDays; i++ {
+		day = day.AddDate(0, 0, 1)
+		if s.Matches(day) {
+			return day
+		}
+	}
+	return time.Time{}
+}
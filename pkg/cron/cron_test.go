@@ -0,0 +1,64 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		// 2024-01-10 is a Wednesday.
+		{"0 0 * * *", day(2024, 1, 10), day(2024, 1, 11)},
+		{"0 9 1 * *", day(2024, 1, 10), day(2024, 2, 1)},
+		{"0 9 1 * *", day(2024, 1, 1), day(2024, 2, 1)},
+		{"0 0 15 * *", time.Date(2024, 1, 14, 23, 59, 0, 0, time.UTC), day(2024, 1, 15)},
+		{"0 0 31 * *", day(2024, 1, 31), day(2024, 3, 31)},
+		{"0 0 29 2 *", day(2024, 3, 1), day(2028, 2, 29)},
+		{"0 0 * * mon", day(2024, 1, 10), day(2024, 1, 15)},
+		{"0 0 * * 7", day(2024, 1, 10), day(2024, 1, 14)},
+		{"0 0 * * 1-5", day(2024, 1, 12), day(2024, 1, 15)},
+		{"0 0 1 jan,jul *", day(2024, 2, 1), day(2024, 7, 1)},
+		{"0 0 */10 * *", day(2024, 1, 11), day(2024, 1, 21)},
+		// Both day fields restricted: either one matching is enough.
+		{"0 0 20 * fri", day(2024, 1, 10), day(2024, 1, 12)},
+		{"0 0 31 2 *", day(2024, 1, 1), time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.NextDate(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).NextDate(%s) = %s, want %s", tt.expr, tt.from.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 0 * *",
+		"0 0 * * * *",
+		"60 0 * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 32 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"0 0 10-5 * *",
+		"0 0 */0 * *",
+		"0 0 * foo *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package fxrates reads dated exchange rates from CSV and fetches them from
+// rate providers.
+package fxrates
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// RatePlaces is the number of decimal places rates are kept to.
+const RatePlaces = 10
+
+// DateLayout is how dates are written in rate files.
+const DateLayout = "2006-01-02"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Rate is the value of one unit of Currency in a base currency on Date.
+type Rate struct {
+	Date     time.Time
+	Currency string
+	Rate     money.Decimal
+}
+
+// Provider supplies exchange rates from an outside source.
+type Provider interface {
+	// Rates returns the rates of every currency the provider knows against
+	// base, published between from and to inclusive.
+	Rates(ctx context.Context, base string, from, to time.Time) ([]Rate, error)
+}
+
+type Config struct {
+	// Driver selects the provider. Only "fixture" is available.
+	Driver      string
+	FixturePath string
+}
+
+// New returns the provider selected by cfg.Driver.
+func New(cfg Config) (Provider, error) {
+	switch cfg.Driver {
+	case "", "fixture":
+		return NewFixtureProvider(cfg.FixturePath)
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider %q", cfg.Driver)
+	}
+}
+
+// ParseCSV reads rates from CSV with a header row naming the columns "date"
+// (YYYY-MM-DD), "currency" (ISO 4217 code) and "rate", in any order. Other
+// columns are ignored. Errors name the line they were found on.
+func ParseCSV(r io.Reader) ([]Rate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("csv is empty; expected a header row with date, currency and rate")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"date", "currency", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header has no %q column", name)
+		}
+	}
+
+	var rates []Rate
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		date, err := time.Parse(DateLayout, field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: date %q is not YYYY-MM-DD", line, field("date"))
+		}
+		currency := strings.ToUpper(field("currency"))
+		if !currencyPattern.MatchString(currency) {
+			return nil, fmt.Errorf("line %d: currency %q is not a three-letter code", line, field("currency"))
+		}
+		rate, err := money.Parse(field("rate"))
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("line %d: rate %q is not a positive number", line, field("rate"))
+		}
+		rates = append(rates, Rate{Date: date, Currency: currency, Rate: rate.Round(RatePlaces, money.HalfUp)})
+	}
+	return rates, nil
+}
+
+//go:embed fixture.csv
+var defaultFixture string
+
+// fixtureBase is the currency fixture rates are quoted against.
+const fixtureBase = "USD"
+
+// FixtureProvider serves rates from a local CSV file quoted against USD,
+// for development and tests. Rates against other bases are crossed through
+// USD.
+type FixtureProvider struct {
+	rates []Rate
+}
+
+// NewFixtureProvider loads the fixture at path, or the built-in sample
+// rates when path is empty.
+func NewFixtureProvider(path string) (*FixtureProvider, error) {
+	var r io.Reader = strings.NewReader(defaultFixture)
+	if strings.TrimSpace(path) != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open exchange rate fixture: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	rates, err := ParseCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("exchange rate fixture: %w", err)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+	return &FixtureProvider{rates: rates}, nil
+}
+
+func (p *FixtureProvider) Rates(ctx context.Context, base string, from, to time.Time) ([]Rate, error) {
+	base = strings.ToUpper(base)
+
+	// Group the fixture by date so each day's rates can be crossed through
+	// that day's rate for base.
+	var dates []time.Time
+	byDate := make(map[time.Time]map[string]money.Decimal)
+	for _, rate := range p.rates {
+		if rate.Date.Before(from) || rate.Date.After(to) {
+			continue
+		}
+		day, ok := byDate[rate.Date]
+		if !ok {
+			day = map[string]money.Decimal{fixtureBase: money.NewFromInt(1)}
+			byDate[rate.Date] = day
+			dates = append(dates, rate.Date)
+		}
+		day[rate.Currency] = rate.Rate
+	}
+
+	var result []Rate
+	for _, date := range dates {
+		day := byDate[date]
+		baseRate, ok := day[base]
+		if !ok {
+			continue
+		}
+		currencies := make([]string, 0, len(day))
+		for currency := range day {
+			if currency != base {
+				currencies = append(currencies, currency)
+			}
+		}
+		sort.Strings(currencies)
+		for _, currency := range currencies {
+			result = append(result, Rate{
+				Date:     date,
+				Currency: currency,
+				Rate:     day[currency].Div(baseRate, RatePlaces, money.HalfUp),
+			})
+		}
+	}
+	return result, nil
+}
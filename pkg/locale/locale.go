@@ -0,0 +1,323 @@
+// Package locale formats numbers, amounts and dates the way a region writes
+// them and looks up translated messages.
+//
+// Locales are loaded from message catalogs, one JSON file per language:
+//
+//	{
+//	  "language": "de",
+//	  "default_locale": "de-DE",
+//	  "locales": {
+//	    "de-DE": {
+//	      "decimal_separator": ",",
+//	      "group_separator": ".",
+//	      "grouping": "thousands",
+//	      "date_layout": "02.01.2006",
+//	      "symbol_position": "after"
+//	    }
+//	  },
+//	  "messages": {"invoice.title": "Rechnung", "month.1": "Jan.", ...}
+//	}
+//
+// Messages are fmt format strings. A message missing from a catalog falls
+// back to the default language's catalog, then to its key.
+package locale
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+// Grouping is how the integer digits of a number are grouped.
+type Grouping string
+
+const (
+	// GroupThousands groups every three digits: 1,234,567.
+	GroupThousands Grouping = "thousands"
+	// GroupIndian groups the last three digits, then every two: 12,34,567
+	// (lakh and crore).
+	GroupIndian Grouping = "indian"
+)
+
+// SymbolPosition is where the currency symbol goes relative to the amount.
+type SymbolPosition string
+
+const (
+	SymbolBefore SymbolPosition = "before"
+	SymbolAfter  SymbolPosition = "after"
+)
+
+// Format describes how a locale writes numbers, amounts and dates.
+type Format struct {
+	DecimalSeparator string         `json:"decimal_separator"`
+	GroupSeparator   string         `json:"group_separator"`
+	Grouping         Grouping       `json:"grouping"`
+	DateLayout       string         `json:"date_layout"`
+	SymbolPosition   SymbolPosition `json:"symbol_position"`
+}
+
+// Locale is a region's formats together with its language's messages.
+type Locale struct {
+	tag      string
+	language string
+	format   Format
+	messages map[string]string
+	fallback map[string]string
+}
+
+// Catalog is the content of one message catalog file.
+type Catalog struct {
+	Language      string            `json:"language"`
+	DefaultLocale string            `json:"default_locale"`
+	Locales       map[string]Format `json:"locales"`
+	Messages      map[string]string `json:"messages"`
+}
+
+// Registry holds the locales of a set of catalogs.
+type Registry struct {
+	locales   map[string]*Locale
+	languages map[string]*Locale
+	def       *Locale
+}
+
+// currencySymbols lists the currencies written with a symbol. Others are
+// written with their ISO code.
+var currencySymbols = map[string]string{
+	"EUR": "€",
+	"GBP": "£",
+	"INR": "₹",
+	"JPY": "¥",
+	"USD": "$",
+}
+
+// Load reads the catalogs in fsys matching pattern. defaultTag is the locale
+// used when no other matches, and its language's messages fill in those
+// missing from other catalogs.
+func Load(fsys fs.FS, pattern string, defaultTag string) (*Registry, error) {
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{
+		locales:   make(map[string]*Locale),
+		languages: make(map[string]*Locale),
+	}
+	catalogs := make([]Catalog, 0, len(paths))
+	for _, path := range paths {
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(content, &catalog); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", path, err)
+		}
+		if err := catalog.validate(); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", path, err)
+		}
+		catalogs = append(catalogs, catalog)
+	}
+
+	for _, catalog := range catalogs {
+		for tag, format := range catalog.Locales {
+			if _, ok := r.locales[tag]; ok {
+				return nil, fmt.Errorf("locale %s is defined twice", tag)
+			}
+			r.locales[tag] = &Locale{tag: tag, language: catalog.Language, format: format, messages: catalog.Messages}
+		}
+		r.languages[catalog.Language] = r.locales[catalog.DefaultLocale]
+	}
+
+	def, ok := r.locales[defaultTag]
+	if !ok {
+		return nil, fmt.Errorf("default locale %s is not defined", defaultTag)
+	}
+	r.def = def
+	for _, l := range r.locales {
+		l.fallback = def.messages
+	}
+	return r, nil
+}
+
+func (c Catalog) validate() error {
+	if c.Language == "" {
+		return fmt.Errorf("language is required")
+	}
+	if _, ok := c.Locales[c.DefaultLocale]; !ok {
+		return fmt.Errorf("default_locale %q is not one of its locales", c.DefaultLocale)
+	}
+	for tag, format := range c.Locales {
+		if lang, _, _ := strings.Cut(tag, "-"); lang != c.Language {
+			return fmt.Errorf("locale %s does not belong to language %s", tag, c.Language)
+		}
+		if format.DecimalSeparator == "" || format.DateLayout == "" {
+			return fmt.Errorf("locale %s needs a decimal_separator and a date_layout", tag)
+		}
+		if format.Grouping != GroupThousands && format.Grouping != GroupIndian {
+			return fmt.Errorf("locale %s has unknown grouping %q", tag, format.Grouping)
+		}
+		if format.SymbolPosition != SymbolBefore && format.SymbolPosition != SymbolAfter {
+			return fmt.Errorf("locale %s has unknown symbol_position %q", tag, format.SymbolPosition)
+		}
+	}
+	return nil
+}
+
+// Default returns the default locale.
+func (r *Registry) Default() *Locale {
+	return r.def
+}
+
+// Match returns the locale for tag, such as "en-IN". A bare language such as
+// "de" matches its catalog's default locale.
+func (r *Registry) Match(tag string) (*Locale, bool) {
+	if l, ok := r.locales[tag]; ok {
+		return l, true
+	}
+	if !strings.Contains(tag, "-") {
+		if l, ok := r.languages[strings.ToLower(tag)]; ok {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// Tags lists the available locales in alphabetical order.
+func (r *Registry) Tags() []string {
+	tags := make([]string, 0, len(r.locales))
+	for tag := range r.locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Tag returns the locale's tag, such as "en-IN".
+func (l *Locale) Tag() string {
+	return l.tag
+}
+
+// Language returns the locale's language, such as "en".
+func (l *Locale) Language() string {
+	return l.language
+}
+
+// T returns the message for key formatted with args.
+func (l *Locale) T(key string, args ...any) string {
+	message, ok := l.messages[key]
+	if !ok {
+		message, ok = l.fallback[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// FormatNumber writes d with the locale's separators, keeping its decimal
+// places.
+func (l *Locale) FormatNumber(d money.Decimal) string {
+	sign := ""
+	if d.IsNegative() {
+		sign = "-"
+		d = d.Neg()
+	}
+
+	whole, frac, _ := strings.Cut(d.String(), ".")
+	if frac != "" {
+		frac = l.format.DecimalSeparator + frac
+	}
+	return sign + l.group(whole) + frac
+}
+
+// FormatQuantity writes q like FormatNumber without trailing zeros, e.g.
+// "1.5" rather than "1.500".
+func (l *Locale) FormatQuantity(q money.Decimal) string {
+	s := q.String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return l.FormatNumber(money.MustParse(s))
+}
+
+// Symbol returns how currency is written: its symbol when it has one, else
+// its ISO code.
+func Symbol(currency string) string {
+	currency = strings.ToUpper(currency)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol
+	}
+	return currency
+}
+
+// FormatMoney writes amount rounded to currency's minor unit with the
+// currency's symbol, e.g. "$1,234.50", "₹12,34,567.00" or "1.234,50 €".
+func (l *Locale) FormatMoney(amount money.Decimal, currency string) string {
+	return l.FormatMoneySymbol(amount, currency, Symbol(currency))
+}
+
+// FormatMoneySymbol is FormatMoney with the symbol given, for output that
+// cannot show every symbol. Symbols that are letters, such as ISO codes,
+// are set apart from the number by a space.
+func (l *Locale) FormatMoneySymbol(amount money.Decimal, currency string, symbol string) string {
+	rounded := amount.RoundCurrency(currency, money.HalfUp)
+	number := l.FormatNumber(rounded.Abs())
+	sign := ""
+	if rounded.IsNegative() {
+		sign = "-"
+	}
+
+	if l.format.SymbolPosition == SymbolAfter {
+		return sign + number + " " + symbol
+	}
+	if r := []rune(symbol); len(r) > 0 && unicode.IsLetter(r[len(r)-1]) {
+		return sign + symbol + " " + number
+	}
+	return sign + symbol + number
+}
+
+// FormatDate writes t in the locale's date layout, a Go time layout in which
+// "Jan" stands for the catalog's "month.N" abbreviation.
+func (l *Locale) FormatDate(t time.Time) string {
+	// Month names are swapped for placeholders so time.Format leaves them
+	// alone, then filled in from the catalog.
+	layout := strings.Replace(l.format.DateLayout, "Jan", "\x00", 1)
+	formatted := t.Format(layout)
+	return strings.Replace(formatted, "\x00", l.T("month."+strconv.Itoa(int(t.Month()))), 1)
+}
+
+// group inserts the locale's group separator into a string of digits.
+func (l *Locale) group(digits string) string {
+	if l.format.GroupSeparator == "" || len(digits) <= 3 {
+		return digits
+	}
+
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if l.format.Grouping == GroupIndian {
+		size = 2
+	}
+
+	var groups []string
+	for len(head) > size {
+		groups = append([]string{head[len(head)-size:]}, groups...)
+		head = head[:len(head)-size]
+	}
+	groups = append([]string{head}, groups...)
+	groups = append(groups, tail)
+	return strings.Join(groups, l.format.GroupSeparator)
+}
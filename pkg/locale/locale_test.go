@@ -0,0 +1,117 @@
+package locale
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/nava1525/bilio-backend/pkg/money"
+)
+
+func testRegistry(t *testing.T) *Registry {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"en.json": {Data: []byte(`{
+			"language": "en",
+			"default_locale": "en-US",
+			"locales": {
+				"en-US": {"decimal_separator": ".", "group_separator": ",", "grouping": "thousands", "date_layout": "Jan 2, 2006", "symbol_position": "before"},
+				"en-IN": {"decimal_separator": ".", "group_separator": ",", "grouping": "indian", "date_layout": "02 Jan 2006", "symbol_position": "before"}
+			},
+			"messages": {"invoice.title": "Invoice", "month.3": "Mar"}
+		}`)},
+		"de.json": {Data: []byte(`{
+			"language": "de",
+			"default_locale": "de-DE",
+			"locales": {
+				"de-DE": {"decimal_separator": ",", "group_separator": ".", "grouping": "thousands", "date_layout": "02.01.2006", "symbol_position": "after"}
+			},
+			"messages": {"invoice.title": "Rechnung"}
+		}`)},
+	}
+	r, err := Load(fsys, "*.json", "en-US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func mustMatch(t *testing.T, r *Registry, tag string) *Locale {
+	t.Helper()
+	l, ok := r.Match(tag)
+	if !ok {
+		t.Fatalf("locale %s not found", tag)
+	}
+	return l
+}
+
+func TestFormatNumberGrouping(t *testing.T) {
+	r := testRegistry(t)
+	tests := []struct {
+		tag  string
+		in   string
+		want string
+	}{
+		{"en-IN", "0", "0"},
+		{"en-IN", "999", "999"},
+		{"en-IN", "1000", "1,000"},
+		{"en-IN", "99999", "99,999"},
+		{"en-IN", "100000", "1,00,000"},
+		{"en-IN", "1234567", "12,34,567"},
+		{"en-IN", "12345678.90", "1,23,45,678.90"},
+		{"en-IN", "123456789", "12,34,56,789"},
+		{"en-IN", "-1234567.5", "-12,34,567.5"},
+		{"en-US", "1234567", "1,234,567"},
+		{"en-US", "123456", "123,456"},
+		{"en-US", "-1234.5", "-1,234.5"},
+		{"de-DE", "1234567.89", "1.234.567,89"},
+	}
+	for _, tt := range tests {
+		l := mustMatch(t, r, tt.tag)
+		if got := l.FormatNumber(money.MustParse(tt.in)); got != tt.want {
+			t.Errorf("%s FormatNumber(%s) = %q, want %q", tt.tag, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	r := testRegistry(t)
+	tests := []struct {
+		tag      string
+		amount   string
+		currency string
+		want     string
+	}{
+		{"en-IN", "1234567", "INR", "₹12,34,567.00"},
+		{"en-IN", "-100000.005", "INR", "-₹1,00,000.01"},
+		{"en-US", "1234.5", "USD", "$1,234.50"},
+		{"en-US", "1234.5", "CHF", "CHF\u00a01,234.50"},
+		{"en-US", "1234.5", "JPY", "¥1,235"},
+		{"de-DE", "1234.5", "EUR", "1.234,50\u00a0€"},
+	}
+	for _, tt := range tests {
+		l := mustMatch(t, r, tt.tag)
+		if got := l.FormatMoney(money.MustParse(tt.amount), tt.currency); got != tt.want {
+			t.Errorf("%s FormatMoney(%s, %s) = %q, want %q", tt.tag, tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAndMessages(t *testing.T) {
+	r := testRegistry(t)
+	if l := mustMatch(t, r, "de"); l.Tag() != "de-DE" {
+		t.Errorf("Match(de) = %s, want de-DE", l.Tag())
+	}
+	if _, ok := r.Match("fr"); ok {
+		t.Error("Match(fr) found a locale")
+	}
+	de := mustMatch(t, r, "de-DE")
+	if got := de.T("invoice.title"); got != "Rechnung" {
+		t.Errorf("T(invoice.title) = %q, want Rechnung", got)
+	}
+	if got := de.T("month.3"); got != "Mar" {
+		t.Errorf("T(month.3) = %q, want the default catalog's Mar", got)
+	}
+	if got := de.T("missing.key"); got != "missing.key" {
+		t.Errorf("T(missing.key) = %q, want the key", got)
+	}
+}
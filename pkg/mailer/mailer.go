@@ -3,18 +3,33 @@ package mailer
 import (
 	"context"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"mime"
+	"mime/quotedprintable"
 	"net/smtp"
 	"strings"
 	"time"
+	"unicode"
 )
 
 type Message struct {
-	To       string
-	Subject  string
-	TextBody string
-	HTMLBody string
+	To          string
+	Cc          []string
+	Subject     string
+	TextBody    string
+	HTMLBody    string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with a message. An attachment with a
+// ContentID is sent inline so the HTML body can show it as "cid:<ContentID>".
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+	ContentID   string
 }
 
 type Sender interface {
@@ -61,6 +76,16 @@ func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
 	if msg.TextBody == "" && msg.HTMLBody == "" {
 		return fmt.Errorf("email body is required")
 	}
+	// Header values are written as-is apart from the subject's encoding, so a
+	// line break in one would let it add headers of its own.
+	if hasControlChars(msg.Subject) {
+		return fmt.Errorf("subject must not contain control characters")
+	}
+	for _, addr := range append([]string{msg.To}, msg.Cc...) {
+		if hasControlChars(addr) {
+			return fmt.Errorf("recipient %q must not contain control characters", addr)
+		}
+	}
 
 	rawMessage := buildMIMEMessage(m.cfg.From, msg)
 
@@ -73,7 +98,7 @@ func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, []byte(rawMessage))
+		errCh <- smtp.SendMail(addr, auth, m.cfg.From, append([]string{msg.To}, msg.Cc...), []byte(rawMessage))
 	}()
 
 	select {
@@ -92,39 +117,95 @@ func buildMIMEMessage(from string, msg Message) string {
 	var builder strings.Builder
 	builder.WriteString(fmt.Sprintf("From: %s\r\n", from))
 	builder.WriteString(fmt.Sprintf("To: %s\r\n", msg.To))
-	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", msg.Subject))
+	if len(msg.Cc) > 0 {
+		builder.WriteString(fmt.Sprintf("Cc: %s\r\n", strings.Join(msg.Cc, ", ")))
+	}
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject)))
 	builder.WriteString("MIME-Version: 1.0\r\n")
 
+	if len(msg.Attachments) == 0 {
+		writeBody(&builder, msg)
+		return builder.String()
+	}
+
+	boundary := randomBoundary()
+	builder.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", boundary))
+
+	builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	writeBody(&builder, msg)
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+		builder.WriteString(fmt.Sprintf("Content-Type: %s\r\n", contentType))
+		builder.WriteString("Content-Transfer-Encoding: base64\r\n")
+		disposition := "attachment"
+		if attachment.ContentID != "" {
+			disposition = "inline"
+			builder.WriteString(fmt.Sprintf("Content-ID: <%s>\r\n", attachment.ContentID))
+		}
+		builder.WriteString(fmt.Sprintf("Content-Disposition: %s\r\n\r\n",
+			mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename})))
+		writeBase64(&builder, attachment.Content)
+	}
+
+	builder.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
+
+	return builder.String()
+}
+
+func hasControlChars(value string) bool {
+	return strings.IndexFunc(value, unicode.IsControl) >= 0
+}
+
+// writeBody writes the headers and content of the message text, as a
+// multipart/alternative part when both a text and an HTML body are set.
+func writeBody(builder *strings.Builder, msg Message) {
 	if msg.TextBody != "" && msg.HTMLBody != "" {
 		boundary := randomBoundary()
 		builder.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary))
 
 		builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		builder.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
-		builder.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		builder.WriteString(msg.TextBody)
-		builder.WriteString("\r\n")
+		writeTextPart(builder, "text/plain", msg.TextBody)
 
 		builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-		builder.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
-		builder.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		builder.WriteString(msg.HTMLBody)
-		builder.WriteString("\r\n")
+		writeTextPart(builder, "text/html", msg.HTMLBody)
 
 		builder.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
 	} else if msg.HTMLBody != "" {
-		builder.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
-		builder.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		builder.WriteString(msg.HTMLBody)
-		builder.WriteString("\r\n")
+		writeTextPart(builder, "text/html", msg.HTMLBody)
 	} else {
-		builder.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
-		builder.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		builder.WriteString(msg.TextBody)
-		builder.WriteString("\r\n")
+		writeTextPart(builder, "text/plain", msg.TextBody)
 	}
+}
 
-	return builder.String()
+// writeTextPart writes a UTF-8 body as quoted-printable, which keeps
+// non-ASCII text and long lines intact through 7-bit SMTP relays.
+func writeTextPart(builder *strings.Builder, contentType string, body string) {
+	builder.WriteString(fmt.Sprintf("Content-Type: %s; charset=\"UTF-8\"\r\n", contentType))
+	builder.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	writer := quotedprintable.NewWriter(builder)
+	writer.Write([]byte(body))
+	writer.Close()
+	builder.WriteString("\r\n")
+}
+
+// writeBase64 encodes content in lines of 76 characters, as RFC 2045
+// requires.
+func writeBase64(builder *strings.Builder, content []byte) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		builder.WriteString(encoded[:76])
+		builder.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	if encoded != "" {
+		builder.WriteString(encoded)
+		builder.WriteString("\r\n")
+	}
 }
 
 func randomBoundary() string {
@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestSendRejectsHeaderInjection(t *testing.T) {
+	m, err := NewSMTPMailer(SMTPConfig{Host: "localhost", Port: 25, From: "billing@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []Message{
+		{To: "client@example.com", Subject: "Invoice INV-001\r\nBcc: someone@example.com", TextBody: "hi"},
+		{To: "client@example.com", Subject: "Invoice\nINV-001", TextBody: "hi"},
+		{To: "client@example.com\r\nBcc: someone@example.com", Subject: "Invoice", TextBody: "hi"},
+		{To: "client@example.com", Cc: []string{"cc@example.com\nBcc: x@example.com"}, Subject: "Invoice", TextBody: "hi"},
+	}
+	for _, msg := range tests {
+		if err := m.Send(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "control characters") {
+			t.Errorf("Send(%q, %q) = %v, want control character error", msg.To, msg.Subject, err)
+		}
+	}
+}
+
+func TestBuildMIMEMessageEncodesSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"Invoice INV-001 from Acme", "Subject: Invoice INV-001 from Acme\r\n"},
+		{"Rechnung INV-001 von Müller GmbH", "Subject: =?utf-8?q?Rechnung_INV-001_von_M=C3=BCller_GmbH?=\r\n"},
+	}
+	for _, tt := range tests {
+		raw := buildMIMEMessage("billing@example.com", Message{To: "client@example.com", Subject: tt.subject, TextBody: "hi"})
+		if !strings.Contains(raw, tt.want) {
+			t.Errorf("subject %q: message has no %q header:\n%s", tt.subject, tt.want, raw)
+		}
+	}
+}
+
+func TestBuildMIMEMessageEncodesBody(t *testing.T) {
+	text := "Grüße von Müller GmbH – total €1.234,50"
+	html := "<p>Grüße von <b>Müller GmbH</b></p>"
+	tests := []Message{
+		{To: "client@example.com", Subject: "Rechnung", TextBody: text},
+		{To: "client@example.com", Subject: "Rechnung", HTMLBody: html},
+		{To: "client@example.com", Subject: "Rechnung", TextBody: text, HTMLBody: html},
+		{To: "client@example.com", Subject: "Rechnung", TextBody: text, Attachments: []Attachment{{Filename: "INV-001.pdf", Content: []byte("%PDF")}}},
+	}
+	for _, msg := range tests {
+		raw := buildMIMEMessage("billing@example.com", msg)
+		for i := 0; i < len(raw); i++ {
+			if raw[i] > 0x7f {
+				t.Fatalf("message has a non-ASCII byte at %d:\n%s", i, raw)
+			}
+		}
+		if strings.Contains(raw, "7bit") {
+			t.Errorf("message declares 7bit:\n%s", raw)
+		}
+
+		m, err := mail.ReadMessage(strings.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var bodies []string
+		collectBodies(t, textproto.MIMEHeader(m.Header), m.Body, &bodies)
+		want := []string{}
+		if msg.TextBody != "" {
+			want = append(want, msg.TextBody)
+		}
+		if msg.HTMLBody != "" {
+			want = append(want, msg.HTMLBody)
+		}
+		if strings.Join(bodies, "|") != strings.Join(want, "|") {
+			t.Errorf("decoded bodies = %q, want %q", bodies, want)
+		}
+	}
+}
+
+// collectBodies appends the decoded text parts of a message to bodies.
+func collectBodies(t *testing.T, header textproto.MIMEHeader, body io.Reader, bodies *[]string) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			collectBodies(t, part.Header, part, bodies)
+		}
+	}
+	if !strings.HasPrefix(mediaType, "text/") {
+		return
+	}
+	if encoding := header.Get("Content-Transfer-Encoding"); encoding != "quoted-printable" {
+		t.Errorf("%s part has Content-Transfer-Encoding %q, want quoted-printable", mediaType, encoding)
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	*bodies = append(*bodies, strings.TrimSuffix(string(decoded), "\r\n"))
+}
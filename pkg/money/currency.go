@@ -0,0 +1,28 @@
+package money
+
+import "strings"
+
+// DefaultMinorUnits is used for currencies not listed in minorUnits.
+const DefaultMinorUnits = 2
+
+// minorUnits lists ISO 4217 currencies whose minor unit is not 1/100.
+var minorUnits = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns the number of decimal places currency is quoted in,
+// e.g. 2 for USD, 0 for JPY and 3 for KWD.
+func MinorUnits(currency string) int32 {
+	if units, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return units
+	}
+	return DefaultMinorUnits
+}
+
+// RoundCurrency rounds d to currency's minor unit.
+func (d Decimal) RoundCurrency(currency string, mode RoundingMode) Decimal {
+	return d.Round(MinorUnits(currency), mode)
+}
@@ -0,0 +1,405 @@
+// Package money provides an exact decimal type for monetary amounts.
+//
+// Decimal values are immutable and never lose precision on addition,
+// subtraction or multiplication; rounding only happens when a caller asks for
+// it, with an explicit RoundingMode. Values scan losslessly from Postgres
+// NUMERIC columns and encode to JSON as exact number literals.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode selects how Round discards digits.
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest value, with ties away from zero
+	// (2.345 -> 2.35, -2.345 -> -2.35).
+	HalfUp RoundingMode = iota
+	// HalfEven rounds to the nearest value, with ties to the even neighbour
+	// (banker's rounding: 2.345 -> 2.34, 2.355 -> 2.36).
+	HalfEven
+	// Down truncates toward zero.
+	Down
+	// Up rounds away from zero.
+	Up
+)
+
+// Decimal is an arbitrary-precision decimal number: coef × 10^-scale. The
+// zero value is 0.
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+var (
+	Zero    = Decimal{}
+	One     = NewFromInt(1)
+	Hundred = NewFromInt(100)
+)
+
+// Parse rejects exponents and scales beyond these bounds, so untrusted input
+// can never make rounding or rescaling allocate huge powers of ten.
+const (
+	MaxExponent = 64
+	MaxScale    = 18
+)
+
+// New returns coef × 10^-scale, e.g. New(12345, 2) is 123.45.
+func New(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(coef), pow10(-scale))}
+	}
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+func NewFromInt(v int64) Decimal {
+	return New(v, 0)
+}
+
+// NewFromFloat converts f using its shortest decimal representation, so
+// NewFromFloat(0.1) is exactly 0.1. Prefer Parse for user input.
+func NewFromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return d
+}
+
+// Parse reads a decimal such as "-1234.5", "0.05" or "1e3". The exponent
+// must be within ±MaxExponent and the result have at most MaxScale decimal
+// places.
+func Parse(s string) (Decimal, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+
+	exp := int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+		}
+		if e > MaxExponent || e < -MaxExponent {
+			return Zero, fmt.Errorf("money: exponent of %q is out of range", orig)
+		}
+		exp = e
+		s = s[:i]
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Zero, fmt.Errorf("money: invalid decimal %q", orig)
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale > MaxScale {
+		return Zero, fmt.Errorf("money: %q has more than %d decimal places", orig, MaxScale)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is intended for
+// constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Sum adds values.
+func Sum(values ...Decimal) Decimal {
+	total := Zero
+	for _, v := range values {
+		total = total.Add(v)
+	}
+	return total
+}
+
+func (d Decimal) value() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns d's coefficient expressed at scale, which must be >= d.scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return new(big.Int).Set(d.value())
+	}
+	return new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{coef: a.Add(a, b), scale: scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{coef: a.Sub(a, b), scale: scale}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.value(), o.value()), scale: d.scale + o.scale}
+}
+
+// Div returns d / o rounded to places decimal places. It panics if o is zero.
+func (d Decimal) Div(o Decimal, places int32, mode RoundingMode) Decimal {
+	if o.IsZero() {
+		panic("money: division by zero")
+	}
+
+	num := new(big.Int).Set(d.value())
+	den := new(big.Int).Set(o.value())
+	// d/o = (num / den) × 10^(o.scale - d.scale); shift so the quotient is
+	// an integer count of 10^-places.
+	shift := places + o.scale - d.scale
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+
+	neg := num.Sign()*den.Sign() < 0
+	num.Abs(num)
+	den.Abs(den)
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	q = roundQuotient(q, r, den, mode)
+	if neg {
+		q.Neg(q)
+	}
+	return Decimal{coef: q, scale: places}
+}
+
+// Round returns d rounded to exactly places decimal places.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		return Decimal{coef: d.rescale(places), scale: places}
+	}
+
+	div := pow10(d.scale - places)
+	neg := d.value().Sign() < 0
+	abs := new(big.Int).Abs(d.value())
+	q, r := new(big.Int).QuoRem(abs, div, new(big.Int))
+	q = roundQuotient(q, r, div, mode)
+	if neg {
+		q.Neg(q)
+	}
+	return Decimal{coef: q, scale: places}
+}
+
+// roundQuotient adjusts the non-negative quotient q of a division by den with
+// remainder r according to mode.
+func roundQuotient(q, r, den *big.Int, mode RoundingMode) *big.Int {
+	if r.Sign() == 0 {
+		return q
+	}
+	half := new(big.Int).Lsh(r, 1).Cmp(den)
+	roundUp := false
+	switch mode {
+	case HalfUp:
+		roundUp = half >= 0
+	case HalfEven:
+		roundUp = half > 0 || (half == 0 && q.Bit(0) == 1)
+	case Up:
+		roundUp = true
+	case Down:
+	}
+	if roundUp {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.value()), scale: d.scale}
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Sign() int                      { return d.value().Sign() }
+func (d Decimal) IsZero() bool                   { return d.Sign() == 0 }
+func (d Decimal) IsPositive() bool               { return d.Sign() > 0 }
+func (d Decimal) IsNegative() bool               { return d.Sign() < 0 }
+func (d Decimal) Equal(o Decimal) bool           { return d.Cmp(o) == 0 }
+func (d Decimal) LessThan(o Decimal) bool        { return d.Cmp(o) < 0 }
+func (d Decimal) GreaterThan(o Decimal) bool     { return d.Cmp(o) > 0 }
+func (d Decimal) LessThanOrEqual(o Decimal) bool { return d.Cmp(o) <= 0 }
+
+// Min returns the smaller of d and o.
+func (d Decimal) Min(o Decimal) Decimal {
+	if o.LessThan(d) {
+		return o
+	}
+	return d
+}
+
+// Max returns the larger of d and o.
+func (d Decimal) Max(o Decimal) Decimal {
+	if o.GreaterThan(d) {
+		return o
+	}
+	return d
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Float64 returns the nearest float64. Use it only for display or layout,
+// never for further arithmetic.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String formats d with all of its digits, e.g. "1234.50".
+func (d Decimal) String() string {
+	abs := new(big.Int).Abs(d.value()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + abs
+	}
+	if pad := int(d.scale) + 1 - len(abs); pad > 0 {
+		abs = strings.Repeat("0", pad) + abs
+	}
+	cut := len(abs) - int(d.scale)
+	return sign + abs[:cut] + "." + abs[cut:]
+}
+
+// StringFixed formats d rounded half-up to places decimal places.
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places, HalfUp).String()
+}
+
+// MarshalJSON encodes d as an exact JSON number, e.g. 1234.50.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string containing one. Numbers
+// are read from their literal text, so no binary rounding occurs.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner. NUMERIC values arrive as text and are parsed
+// exactly.
+func (d *Decimal) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	case float64:
+		*d = NewFromFloat(v)
+		return nil
+	}
+	return fmt.Errorf("money: cannot scan %T into Decimal", src)
+}
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+var pow10Cache = func() []*big.Int {
+	cache := make([]*big.Int, 40)
+	cache[0] = big.NewInt(1)
+	for i := 1; i < len(cache); i++ {
+		cache[i] = new(big.Int).Mul(cache[i-1], big.NewInt(10))
+	}
+	return cache
+}()
+
+func pow10(n int32) *big.Int {
+	if int(n) < len(pow10Cache) {
+		return pow10Cache[n]
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,119 @@
+package money
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "0", want: "0"},
+		{in: "1234.50", want: "1234.50"},
+		{in: "-0.05", want: "-0.05"},
+		{in: "+7", want: "7"},
+		{in: " 12.5 ", want: "12.5"},
+		{in: ".5", want: "0.5"},
+		{in: "5.", want: "5"},
+		{in: "1e3", want: "1000"},
+		{in: "1.5E-2", want: "0.015"},
+		{in: "1e64", want: "1" + strings.Repeat("0", 64)},
+		{in: "1e-18", want: "0." + strings.Repeat("0", 17) + "1"},
+		{in: "0." + strings.Repeat("0", 17) + "1", want: "0." + strings.Repeat("0", 17) + "1"},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: "1e65", wantErr: true},
+		{in: "1e-65", wantErr: true},
+		{in: "1e-19", wantErr: true},
+		{in: "1e-20000000", wantErr: true},
+		{in: "1e-2147483648", wantErr: true},
+		{in: "1e99999999999", wantErr: true},
+		{in: "0." + strings.Repeat("0", 18) + "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		mode   RoundingMode
+		want   string
+	}{
+		{"2.345", 2, HalfUp, "2.35"},
+		{"-2.345", 2, HalfUp, "-2.35"},
+		{"2.344", 2, HalfUp, "2.34"},
+		{"2.345", 2, HalfEven, "2.34"},
+		{"2.355", 2, HalfEven, "2.36"},
+		{"2.3451", 2, HalfEven, "2.35"},
+		{"2.349", 2, Down, "2.34"},
+		{"-2.349", 2, Down, "-2.34"},
+		{"2.341", 2, Up, "2.35"},
+		{"-2.341", 2, Up, "-2.35"},
+		{"2.5", 3, HalfUp, "2.500"},
+		{"1234.5", 0, HalfUp, "1235"},
+		{"0.004", 2, HalfUp, "0.00"},
+	}
+	for _, tt := range tests {
+		got := MustParse(tt.in).Round(tt.places, tt.mode)
+		if got.String() != tt.want {
+			t.Errorf("Round(%s, %d, %d) = %s, want %s", tt.in, tt.places, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		places int32
+		mode   RoundingMode
+		want   string
+	}{
+		{"10", "4", 2, HalfUp, "2.50"},
+		{"10", "3", 2, HalfUp, "3.33"},
+		{"20", "3", 2, HalfUp, "6.67"},
+		{"-20", "3", 2, HalfUp, "-6.67"},
+		{"20", "-3", 2, Down, "-6.66"},
+		{"1", "8", 2, HalfEven, "0.12"},
+		{"3", "8", 2, HalfEven, "0.38"},
+		{"0.5", "0.25", 0, HalfUp, "2"},
+		{"1.000", "3", 4, Up, "0.3334"},
+		{"123.45", "100", 4, HalfUp, "1.2345"},
+	}
+	for _, tt := range tests {
+		got := MustParse(tt.a).Div(MustParse(tt.b), tt.places, tt.mode)
+		if got.String() != tt.want {
+			t.Errorf("%s / %s at %d places = %s, want %s", tt.a, tt.b, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestDivByZeroPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Div by zero did not panic")
+		}
+	}()
+	One.Div(Zero, 2, HalfUp)
+}
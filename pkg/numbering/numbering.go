@@ -0,0 +1,119 @@
+// Package numbering renders document numbers from formats such as
+// "INV-{YYYY}-{SEQ:4}".
+//
+// Supported tokens:
+//
+//	{YYYY}   four-digit year
+//	{YY}     two-digit year
+//	{MM}     two-digit month
+//	{SEQ}    sequence number
+//	{SEQ:n}  sequence number zero-padded to n digits (1-12)
+package numbering
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxPadding = 12
+
+// Validate checks that format only uses known tokens and contains exactly
+// one sequence token.
+func Validate(format string) error {
+	if strings.TrimSpace(format) == "" {
+		return fmt.Errorf("format is required")
+	}
+	seqCount := 0
+	err := walk(format, func(token string) error {
+		kind, _, err := parseToken(token)
+		if err != nil {
+			return err
+		}
+		if kind == "SEQ" {
+			seqCount++
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if seqCount != 1 {
+		return fmt.Errorf("format must contain exactly one {SEQ} token")
+	}
+	return nil
+}
+
+// HasYear reports whether format includes the year, which is required for
+// yearly-reset sequences to stay unique.
+func HasYear(format string) bool {
+	return strings.Contains(format, "{YYYY}") || strings.Contains(format, "{YY}")
+}
+
+// Format renders format for sequence value seq on date. Unknown tokens are
+// emitted verbatim; call Validate first.
+func Format(format string, seq int64, date time.Time) string {
+	var b strings.Builder
+	_ = walk(format, func(token string) error {
+		kind, pad, err := parseToken(token)
+		if err != nil {
+			b.WriteString("{" + token + "}")
+			return nil
+		}
+		switch kind {
+		case "YYYY":
+			fmt.Fprintf(&b, "%04d", date.Year())
+		case "YY":
+			fmt.Fprintf(&b, "%02d", date.Year()%100)
+		case "MM":
+			fmt.Fprintf(&b, "%02d", int(date.Month()))
+		case "SEQ":
+			fmt.Fprintf(&b, "%0*d", pad, seq)
+		}
+		return nil
+	}, func(literal string) {
+		b.WriteString(literal)
+	})
+	return b.String()
+}
+
+// walk splits format into literals and {tokens}.
+func walk(format string, onToken func(string) error, onLiteral func(string)) error {
+	for len(format) > 0 {
+		start := strings.IndexByte(format, '{')
+		if start < 0 {
+			if onLiteral != nil {
+				onLiteral(format)
+			}
+			return nil
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			return fmt.Errorf("unclosed token in format %q", format)
+		}
+		if start > 0 && onLiteral != nil {
+			onLiteral(format[:start])
+		}
+		if err := onToken(format[start+1 : start+end]); err != nil {
+			return err
+		}
+		format = format[start+end+1:]
+	}
+	return nil
+}
+
+func parseToken(token string) (kind string, pad int, err error) {
+	switch token {
+	case "YYYY", "YY", "MM", "SEQ":
+		return token, 1, nil
+	}
+	if strings.HasPrefix(token, "SEQ:") {
+		n, convErr := strconv.Atoi(token[len("SEQ:"):])
+		if convErr != nil || n < 1 || n > maxPadding {
+			return "", 0, fmt.Errorf("invalid padding in {%s} (use 1-%d)", token, maxPadding)
+		}
+		return "SEQ", n, nil
+	}
+	return "", 0, fmt.Errorf("unknown token {%s}", token)
+}
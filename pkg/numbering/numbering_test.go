@@ -0,0 +1,51 @@
+package numbering
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2024, time.March, 9, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format string
+		seq    int64
+		want   string
+	}{
+		{"INV-{SEQ}", 7, "INV-7"},
+		{"INV-{SEQ:4}", 7, "INV-0007"},
+		{"INV-{SEQ:4}", 12345, "INV-12345"},
+		{"INV-{YYYY}-{SEQ:3}", 42, "INV-2024-042"},
+		{"{YY}{MM}/{SEQ:2}", 5, "2403/05"},
+		{"{SEQ:12}", 1, "000000000001"},
+		{"Q-{DD}-{SEQ}", 3, "Q-{DD}-3"},
+		{"{SEQ:0}-{SEQ}", 3, "{SEQ:0}-3"},
+		{"plain", 1, "plain"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.format, tt.seq, date); got != tt.want {
+			t.Errorf("Format(%q, %d) = %q, want %q", tt.format, tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"INV-{YYYY}-{SEQ:4}", false},
+		{"{SEQ}", false},
+		{"", true},
+		{"INV-{YYYY}", true},
+		{"{SEQ}-{SEQ}", true},
+		{"INV-{SEQ:13}", true},
+		{"INV-{DD}-{SEQ}", true},
+		{"INV-{SEQ", true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) = %v, want error %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+)
+
+// Image is a raster image prepared for embedding. Pixels are stored as 8-bit
+// RGB; transparent areas are flattened onto white since PDF 1.4 soft masks
+// are not worth the extra objects for logos.
+type Image struct {
+	width  int
+	height int
+	rgb    []byte
+}
+
+// NewImage converts img for embedding. Decode PNG or JPEG data with the
+// standard image packages first.
+func NewImage(img image.Image) *Image {
+	bounds := img.Bounds()
+	out := &Image{
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		rgb:    make([]byte, 0, bounds.Dx()*bounds.Dy()*3),
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// RGBA returns alpha-premultiplied 16-bit components, so adding
+			// the uncovered share of white composites onto a white page.
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			out.rgb = append(out.rgb, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+	return out
+}
+
+// Width and Height are the image's size in pixels.
+func (img *Image) Width() int  { return img.width }
+func (img *Image) Height() int { return img.height }
+
+// DrawImage draws img scaled to w×h with its top-left corner at (x, y).
+func (d *Document) DrawImage(img *Image, x, y, w, h float64) {
+	index := -1
+	for i, existing := range d.images {
+		if existing == img {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		d.images = append(d.images, img)
+		index = len(d.images) - 1
+	}
+	fmt.Fprintf(d.current(), "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n",
+		w, h, x, d.size.Height-y-h, imageResourceName(index))
+}
+
+func imageResourceName(index int) string {
+	return fmt.Sprintf("Im%d", index+1)
+}
+
+// imageObject returns the XObject dictionary and stream for img.
+func imageObject(img *Image) (string, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(img.rgb); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream",
+		img.width, img.height, compressed.Len(), compressed.String()), nil
+}
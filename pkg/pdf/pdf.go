@@ -0,0 +1,312 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PageSize is a page dimension in PDF points (1/72 inch).
+type PageSize struct {
+	Width  float64
+	Height float64
+}
+
+var (
+	PageA4     = PageSize{Width: 595.28, Height: 841.89}
+	PageLetter = PageSize{Width: 612, Height: 792}
+)
+
+// Font identifies one of the standard Type1 fonts every PDF viewer ships with,
+// so documents never need to embed font files.
+type Font string
+
+const (
+	Helvetica     Font = "Helvetica"
+	HelveticaBold Font = "Helvetica-Bold"
+)
+
+var fontResources = []Font{Helvetica, HelveticaBold}
+
+// Color is an RGB colour with components in the 0-1 range.
+type Color struct {
+	R, G, B float64
+}
+
+var (
+	Black     = Color{0, 0, 0}
+	White     = Color{1, 1, 1}
+	Gray      = Color{0.45, 0.45, 0.45}
+	LightGray = Color{0.93, 0.93, 0.93}
+)
+
+// ParseColor reads a CSS-style "#rrggbb" colour.
+func ParseColor(s string) (Color, bool) {
+	var r, g, b uint8
+	if len(s) != 7 || s[0] != '#' {
+		return Color{}, false
+	}
+	if _, err := fmt.Sscanf(s[1:], "%02x%02x%02x", &r, &g, &b); err != nil {
+		return Color{}, false
+	}
+	return Color{float64(r) / 255, float64(g) / 255, float64(b) / 255}, true
+}
+
+// Mix blends c towards other; weight 0 keeps c and 1 gives other.
+func (c Color) Mix(other Color, weight float64) Color {
+	return Color{
+		R: c.R + (other.R-c.R)*weight,
+		G: c.G + (other.G-c.G)*weight,
+		B: c.B + (other.B-c.B)*weight,
+	}
+}
+
+// Document is a minimal PDF writer. Coordinates passed to drawing methods use a
+// top-left origin, matching how layouts are usually reasoned about.
+type Document struct {
+	size   PageSize
+	pages  []*bytes.Buffer
+	active int
+	font   Font
+	fsize  float64
+	images []*Image
+}
+
+func NewDocument(size PageSize) *Document {
+	return &Document{size: size, font: Helvetica, fsize: 10}
+}
+
+func (d *Document) Size() PageSize {
+	return d.size
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.active = len(d.pages) - 1
+}
+
+// SetPage makes page i (zero-based) the target of subsequent drawing calls.
+// It is used to stamp headers and footers once the page count is known.
+func (d *Document) SetPage(i int) {
+	if i < 0 || i >= len(d.pages) {
+		return
+	}
+	d.active = i
+}
+
+func (d *Document) SetFont(font Font, size float64) {
+	d.font = font
+	d.fsize = size
+}
+
+func (d *Document) current() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[d.active]
+}
+
+// Text draws s with its baseline at (x, y).
+func (d *Document) Text(x, y float64, s string, color Color) {
+	fmt.Fprintf(d.current(), "BT /%s %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+		fontResourceName(d.font), d.fsize, color.R, color.G, color.B, x, d.size.Height-y, escapeText(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y float64, s string, color Color) {
+	d.Text(x-d.TextWidth(s), y, s, color)
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(d.current(), "%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		color.R, color.G, color.B, width, x1, d.size.Height-y1, x2, d.size.Height-y2)
+}
+
+// FillRect fills a rectangle whose top-left corner is (x, y).
+func (d *Document) FillRect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(d.current(), "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+		color.R, color.G, color.B, x, d.size.Height-y-h, w, h)
+}
+
+// TextWidth returns the rendered width of s in the current font and size.
+func (d *Document) TextWidth(s string) float64 {
+	return StringWidth(d.font, d.fsize, s)
+}
+
+// WrapText splits s into lines no wider than maxWidth in the current font.
+func (d *Document) WrapText(s string, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+		line := words[0]
+		for _, word := range words[1:] {
+			candidate := line + " " + word
+			if d.TextWidth(candidate) <= maxWidth {
+				line = candidate
+				continue
+			}
+			lines = append(lines, line)
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// WriteTo serializes the document. Every page gets a content stream compressed
+// with FlateDecode and shares the same font resources.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+
+	writeObject := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Object layout: 1 catalog, 2 page tree, then fonts, then images, then
+	// page/content pairs.
+	fontBase := 3
+	imageBase := fontBase + len(fontResources)
+	pageBase := imageBase + len(d.images)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageBase+i*2)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	var fontDict strings.Builder
+	for i, font := range fontResources {
+		writeObject(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+		fmt.Fprintf(&fontDict, "/%s %d 0 R ", fontResourceName(font), fontBase+i)
+	}
+
+	resources := fmt.Sprintf("/Font << %s>>", fontDict.String())
+	if len(d.images) > 0 {
+		var imageDict strings.Builder
+		for i, img := range d.images {
+			object, err := imageObject(img)
+			if err != nil {
+				return 0, fmt.Errorf("compress image %d: %w", i+1, err)
+			}
+			writeObject(object)
+			fmt.Fprintf(&imageDict, "/%s %d 0 R ", imageResourceName(i), imageBase+i)
+		}
+		resources += fmt.Sprintf(" /XObject << %s>>", imageDict.String())
+	}
+
+	for i, page := range d.pages {
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << %s >> /Contents %d 0 R >>",
+			d.size.Width, d.size.Height, resources, pageBase+i*2+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return 0, fmt.Errorf("compress page %d: %w", i+1, err)
+		}
+		if err := zw.Close(); err != nil {
+			return 0, fmt.Errorf("compress page %d: %w", i+1, err)
+		}
+		writeObject(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+	}
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+func fontResourceName(font Font) string {
+	if font == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// escapeText converts s to WinAnsi bytes and escapes PDF string delimiters.
+// Characters outside WinAnsi are replaced with their stand-in from
+// substitutes, or '?' when they have none.
+func escapeText(s string) string {
+	var b strings.Builder
+	for _, r := range substitute(s) {
+		c, ok := winAnsiByte(r)
+		if !ok {
+			c = '?'
+		}
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n', '\r', '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// substitutes lists ASCII stand-ins for characters the standard fonts
+// cannot show.
+var substitutes = map[rune]string{
+	'₹': "Rs.",
+	'−': "-",
+}
+
+// substitute replaces the characters in s that have a stand-in.
+func substitute(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { _, ok := substitutes[r]; return ok }) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if stand, ok := substitutes[r]; ok {
+			b.WriteString(stand)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+func winAnsiByte(r rune) (byte, bool) {
+	if r >= 0x20 && r <= 0x7E || r == '\n' || r == '\r' || r == '\t' {
+		return byte(r), true
+	}
+	if r >= 0xA0 && r <= 0xFF {
+		return byte(r), true
+	}
+	c, ok := winAnsiSpecials[r]
+	return c, ok
+}